@@ -0,0 +1,64 @@
+package binding
+
+import (
+	jsonpkg "encoding/json"
+	"fmt"
+	"reflect"
+)
+
+const (
+	tagAMQPHeader     = "amqp"
+	tagAMQPRoutingKey = "amqp_routing_key"
+)
+
+// AMQPMessage the AMQP (e.g. RabbitMQ) message
+type AMQPMessage struct {
+	// Headers the application headers, the values are the AMQP table field values
+	Headers     map[string]interface{}
+	ContentType string
+	RoutingKey  string
+	Body        []byte
+}
+
+// BindAMQP binds the AMQP message to the struct.
+// NOTE:
+//  The headers are bound to the fields tagged `amqp:"$name"`;
+//  The routing key is bound to the field tagged `amqp_routing_key:"true"`;
+//  The body is bound to the body fields by the ContentType property, the default is application/json;
+//  If msg==nil, ErrNilMessage is returned.
+func (b *Binding) BindAMQP(msg *AMQPMessage, structPointer interface{}) error {
+	if msg == nil {
+		return ErrNilMessage
+	}
+	return b.BindMessage(structPointer, msg.Body, msg.ContentType, func(field reflect.StructField) ([]byte, bool) {
+		if name, ok := field.Tag.Lookup(tagAMQPHeader); ok {
+			if name == "" {
+				name = field.Name
+			}
+			v, found := msg.Headers[name]
+			if !found || v == nil {
+				return nil, false
+			}
+			return amqpHeaderBytes(v), true
+		}
+		if field.Tag.Get(tagAMQPRoutingKey) == "true" {
+			return []byte(msg.RoutingKey), true
+		}
+		return nil, false
+	})
+}
+
+// amqpHeaderBytes returns the bytes of the AMQP table field value.
+func amqpHeaderBytes(v interface{}) []byte {
+	switch r := v.(type) {
+	case []byte:
+		return r
+	case string:
+		return []byte(r)
+	case map[string]interface{}, []interface{}:
+		b, _ := jsonpkg.Marshal(r)
+		return b
+	default:
+		return []byte(fmt.Sprint(r))
+	}
+}
@@ -0,0 +1,103 @@
+package binding
+
+import (
+	"errors"
+
+	"github.com/bytedance/go-tagexpr"
+)
+
+const (
+	// apiKeyHeader the header of the API key bound to the field tagged `apikey:"true"`
+	apiKeyHeader = "X-API-Key"
+	// apiKeyQuery the query parameter of the API key bound to the field tagged `apikey:"true"`
+	apiKeyQuery = "api_key"
+)
+
+var (
+	defaultAPIKeySources = []Source{SourceHeader, SourceQuery}
+	errNoAPIKeyValidator = errors.New("binding: no API key validator")
+)
+
+// SetAPIKeyValidator sets the function which validates the API key and returns the user ID,
+// which is bound to the fields tagged `apikey:"true"`.
+// NOTE:
+//  The API key is the X-API-Key header or the api_key query parameter, see SetAPIKeySources;
+//  The key is validated once per request, and the error returned by fn is returned as is,
+//  e.g. to respond 401 Unauthorized;
+//  If fn is nil, the binding of the API key fails.
+func (b *Binding) SetAPIKeyValidator(fn func(key string) (userID string, err error)) *Binding {
+	return b.update(WithAPIKeyValidator(fn))
+}
+
+// SetAPIKeySources sets the sources of the API key in the order of precedence,
+// SourceHeader is the X-API-Key header and SourceQuery is the api_key query parameter.
+// NOTE:
+//  The default is SourceHeader, SourceQuery;
+//  The other sources are ignored.
+func (b *Binding) SetAPIKeySources(sources ...Source) *Binding {
+	return b.update(WithAPIKeySources(sources...))
+}
+
+// apiKeySourcesOf returns the supported sources of the API key in order.
+func apiKeySourcesOf(sources []Source) []Source {
+	r := make([]Source, 0, len(sources))
+	for _, s := range sources {
+		if s == SourceHeader || s == SourceQuery {
+			r = append(r, s)
+		}
+	}
+	return r
+}
+
+// bindAPIKey binds the user ID of the API key to the field tagged `apikey:"true"`.
+func (b *Binding) bindAPIKey(param *paramInfo, info *tagInfo, expr *tagexpr.TagExpr, rc *requestCache) (bool, error) {
+	userID, found, err := b.apiKeyUserOf(rc)
+	if !found {
+		if info.required {
+			return false, info.requiredError
+		}
+		return false, nil
+	}
+	if err != nil {
+		return true, err
+	}
+	return true, param.bindStringSlice(info, expr, []string{userID})
+}
+
+// apiKeyUserOf returns the user ID of the API key of the request, and whether the key is present.
+func (b *Binding) apiKeyUserOf(rc *requestCache) (string, bool, error) {
+	if !rc.apiKeyChecked {
+		rc.apiKeyChecked = true
+		key, found := b.apiKeyOf(rc)
+		switch {
+		case !found:
+		case b.apiKeyValidator == nil:
+			rc.apiKeyErr = errNoAPIKeyValidator
+		default:
+			rc.apiKeyUser, rc.apiKeyErr = b.apiKeyValidator(key)
+		}
+		rc.apiKeyFound = found
+	}
+	return rc.apiKeyUser, rc.apiKeyFound, rc.apiKeyErr
+}
+
+// apiKeyOf returns the first non-empty API key of the sources.
+func (b *Binding) apiKeyOf(rc *requestCache) (string, bool) {
+	for _, s := range b.apiKeySources {
+		var key string
+		switch s {
+		case SourceHeader:
+			key = rc.req.Header.Get(apiKeyHeader)
+		case SourceQuery:
+			if !rc.queryParsed {
+				rc.queryParsed = true
+				rc.queryValues = rc.req.URL.Query()
+			}
+			key = rc.queryValues.Get(apiKeyQuery)
+		}
+		if key != "" {
+			return key, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,32 @@
+package binding
+
+import (
+	"github.com/bytedance/go-tagexpr"
+)
+
+// The parameter names of the 'basic_auth' tag, such as `basic_auth:"username"`.
+const (
+	basicAuthUsername = "username"
+	basicAuthPassword = "password"
+)
+
+// bindBasicAuth binds the username or the password of the HTTP Basic Auth credentials,
+// which are parsed by req.BasicAuth().
+// NOTE:
+//  The field is required, if the request has no Authorization header or it is not the Basic scheme,
+//  return the required error.
+func (p *paramInfo) bindBasicAuth(info *tagInfo, expr *tagexpr.TagExpr, rc *requestCache) (bool, error) {
+	value, ok := basicAuthOf(info, rc)
+	if !ok {
+		return false, info.requiredError
+	}
+	return true, p.bindStringSlice(info, expr, []string{value})
+}
+
+func basicAuthOf(info *tagInfo, rc *requestCache) (string, bool) {
+	username, password, ok := rc.req.BasicAuth()
+	if info.paramName == basicAuthPassword {
+		return password, ok
+	}
+	return username, ok
+}
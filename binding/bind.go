@@ -1,8 +1,12 @@
 package binding
 
 import (
+	"context"
 	"net/http"
+	"net/url"
 	"reflect"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/bytedance/go-tagexpr"
@@ -13,27 +17,44 @@ import (
 
 // Binding binding and verification tool for http request
 type Binding struct {
-	vd             *validator.Validator
-	recvs          map[int32]*receiver
-	lock           sync.RWMutex
-	bindErrFactory func(failField, msg string) error
-	config         Config
+	options
+	vd    *validator.Validator
+	recvs map[int32]*receiver
+	lock  sync.RWMutex
 }
 
 // New creates a binding tool.
 // NOTE:
 //  Use default tag name for config fields that are empty
 func New(config *Config) *Binding {
-	if config == nil {
-		config = new(Config)
+	return NewBinding(WithConfig(config))
+}
+
+// setOptions sets the options at once, and resets the validator and the prepared receivers.
+func (b *Binding) setOptions(o options) *Binding {
+	b.options = o
+	b.recvs = make(map[int32]*receiver, 1024)
+	if o.validator != nil {
+		b.vd = o.validator
+		return b
+	}
+	b.vd = validator.New(o.config.Validator)
+	if o.validatingErrFactory == nil {
+		b.vd.SetCodeErrorFactory(defaultValidatingErrFactory)
+	} else {
+		b.vd.SetErrorFactory(o.validatingErrFactory)
 	}
-	b := &Binding{
-		recvs:  make(map[int32]*receiver, 1024),
-		config: *config,
+	return b
+}
+
+// update applies the options to the copy of the current options, and then sets them at once,
+// which is the same path as NewBinding.
+func (b *Binding) update(opts ...Option) *Binding {
+	o := b.options
+	for _, opt := range opts {
+		opt.apply(&o)
 	}
-	b.config.init()
-	b.vd = validator.New(b.config.Validator)
-	return b.SetErrorFactory(nil, nil)
+	return b.setOptions(o)
 }
 
 // SetLooseZeroMode if set to true,
@@ -42,55 +63,273 @@ func New(config *Config) *Binding {
 //  The default is false;
 //  Suitable for these parameter types: query/header/cookie/form .
 func (b *Binding) SetLooseZeroMode(enable bool) *Binding {
-	b.config.LooseZeroMode = enable
-	for k := range b.recvs {
-		delete(b.recvs, k)
-	}
-	return b
+	return b.update(Option{apply: func(o *options) {
+		o.config.LooseZeroMode = enable
+	}})
 }
 
-var defaultValidatingErrFactory = newDefaultErrorFactory("validating")
+var defaultValidatingErrFactory = newDefaultCodeErrorFactory("validating")
 var defaultBindErrFactory = newDefaultErrorFactory("binding")
 
 // SetErrorFactory customizes the factory of validation error.
 // NOTE:
-//  If errFactory==nil, the default is used
+//  If errFactory==nil, the default is used, whose validation error has the error code
 func (b *Binding) SetErrorFactory(bindErrFactory, validatingErrFactory func(failField, msg string) error) *Binding {
-	if bindErrFactory == nil {
-		bindErrFactory = defaultBindErrFactory
+	return b.update(WithErrorFactory(bindErrFactory, validatingErrFactory))
+}
+
+// SetPathParamsDecoder sets the decoder of the path parameters.
+// NOTE:
+//  The decoder is used only when the pathParams argument of binding is nil;
+//  If decoder==nil, the path parameters are not decoded from the request.
+func (b *Binding) SetPathParamsDecoder(decoder PathParamsDecoder) *Binding {
+	return b.update(WithPathParamsDecoder(decoder))
+}
+
+// SetMaxFormFields sets the maximum number of the form fields parsed from the body,
+// if exceeded, ErrTooManyFields is returned.
+// NOTE:
+//  The default is 10000;
+//  If n<=0, the number is not limited.
+func (b *Binding) SetMaxFormFields(n int) *Binding {
+	return b.update(WithMaxFormFields(n))
+}
+
+// SetMaxHeaderSize sets the maximum total byte length of the header values,
+// which is checked before binding the header and cookie parameters,
+// if exceeded, ErrHeaderTooLarge is returned.
+// NOTE:
+//  The default is 1 MB, the same as http.DefaultMaxHeaderBytes;
+//  If n<=0, the size is not limited.
+func (b *Binding) SetMaxHeaderSize(n int64) *Binding {
+	return b.update(WithMaxHeaderSize(n))
+}
+
+// SetJSONUnmarshaler sets the JSON unmarshal function of the binding,
+// instead of the global one reset by ResetJSONUnmarshaler.
+// NOTE:
+//  verifyingRequired is true if the required tag is supported by fn;
+//  If fn==nil, the global one is used.
+func (b *Binding) SetJSONUnmarshaler(verifyingRequired bool, fn func(data []byte, v interface{}) error) *Binding {
+	return b.update(WithJSONUnmarshaler(verifyingRequired, fn))
+}
+
+// jsonUnmarshaler returns the JSON unmarshal function,
+// and whether the required tag should be verified by the binding.
+func (b *Binding) jsonUnmarshaler() (func(data []byte, v interface{}) error, bool) {
+	if b.jsonUnmarshal != nil {
+		return b.jsonUnmarshal, !b.jsonVerifyingRequired
 	}
-	if validatingErrFactory == nil {
-		validatingErrFactory = defaultValidatingErrFactory
+	return jsonUnmarshalFunc, jsonIndependentRequired
+}
+
+// SetValidator sets the validator of the binding.
+// NOTE:
+//  The validating error factory of SetErrorFactory is not applied to it;
+//  If v==nil, the validator is not changed.
+func (b *Binding) SetValidator(v *validator.Validator) *Binding {
+	return b.update(WithValidator(v))
+}
+
+// SetRequestContext sets the context of validation which is passed to the validator functions,
+// when the validation has no context, such as BindAndValidate.
+// NOTE:
+//  The default is nil, that is, context.Background().
+func (b *Binding) SetRequestContext(ctx context.Context) *Binding {
+	return b.update(WithRequestContext(ctx))
+}
+
+// SetCollectAll sets whether BindAndValidate reports all the binding and validation errors,
+// instead of the first one.
+// NOTE:
+//  The default is false;
+//  Each field reports at most one error: the field which failed to be bound is not validated;
+//  The errors are combined as validator.Errors in the order of the field declarations.
+func (b *Binding) SetCollectAll(enable bool) *Binding {
+	return b.update(Option{apply: func(o *options) {
+		o.collectAll = enable
+	}})
+}
+
+// validate validates the value with the context set by SetRequestContext.
+func (b *Binding) validate(value interface{}) error {
+	if b.ctx != nil {
+		return b.vd.ValidateContext(b.ctx, value)
 	}
-	b.bindErrFactory = bindErrFactory
-	b.vd.SetErrorFactory(validatingErrFactory)
-	return b
+	return b.vd.Validate(value)
 }
 
 // BindAndValidate binds the request parameters and validates them if needed.
-func (b *Binding) BindAndValidate(structPointer interface{}, req *http.Request, pathParams PathParams) error {
+func (b *Binding) BindAndValidate(structPointer interface{}, req *http.Request, pathParams PathParams) (err error) {
+	var span bindSpan
+	if b.tracer != nil {
+		origin := req
+		req, span = b.startSpan(req.Context(), req, structPointer)
+		defer func() { endSpan(span, origin, req, err) }()
+	}
+	if b.collectAll {
+		return b.bindAndValidateAll(b.ctx, structPointer, req, pathParams, span)
+	}
 	v, hasVd, err := b.bind(structPointer, req, pathParams)
 	if err != nil {
 		return err
 	}
 	if hasVd {
-		return b.vd.Validate(v)
+		err = b.validate(v)
+		if span != nil {
+			span.validated(err == nil)
+		}
+		return err
 	}
 	return nil
 }
 
+// BindAndValidateContext binds the request parameters and validates them with the context if needed.
+// NOTE:
+//  If ctx==nil, req.Context() is used;
+//  The context is passed to the validator functions registered by validator.RegCtxFunc.
+func (b *Binding) BindAndValidateContext(ctx context.Context, structPointer interface{}, req *http.Request, pathParams PathParams) (err error) {
+	if ctx == nil {
+		ctx = req.Context()
+	}
+	var span bindSpan
+	if b.tracer != nil {
+		origin := req
+		req, span = b.startSpan(ctx, req, structPointer)
+		ctx = req.Context()
+		defer func() { endSpan(span, origin, req, err) }()
+	}
+	if b.collectAll {
+		return b.bindAndValidateAll(ctx, structPointer, req, pathParams, span)
+	}
+	v, hasVd, err := b.bind(structPointer, req, pathParams)
+	if err != nil {
+		return err
+	}
+	if !hasVd {
+		return nil
+	}
+	err = b.vd.ValidateContext(ctx, v)
+	if span != nil {
+		span.validated(err == nil)
+	}
+	return err
+}
+
+// BindValidateOnly binds the request parameters to a new zero value of the struct type and validates it,
+// without modifying the struct, e.g. for the preflight checks.
+// NOTE:
+//  The request body is restored after reading, so the request can still be bound later;
+//  The new value is discarded, only the error is returned.
+func (b *Binding) BindValidateOnly(structPointer interface{}, req *http.Request, pathParams PathParams) error {
+	value, err := b.structValueOf(structPointer)
+	if err != nil {
+		return err
+	}
+	return b.BindAndValidate(reflect.New(value.Type()).Interface(), req, pathParams)
+}
+
 // Bind binds the request parameters.
-func (b *Binding) Bind(structPointer interface{}, req *http.Request, pathParams PathParams) error {
-	_, _, err := b.bind(structPointer, req, pathParams)
+func (b *Binding) Bind(structPointer interface{}, req *http.Request, pathParams PathParams) (err error) {
+	if b.tracer != nil {
+		origin := req
+		var span bindSpan
+		req, span = b.startSpan(req.Context(), req, structPointer)
+		defer func() { endSpan(span, origin, req, err) }()
+	}
+	_, _, err = b.bind(structPointer, req, pathParams)
 	return err
 }
 
 // Validate validates whether the fields of value is valid.
 func (b *Binding) Validate(value interface{}) error {
-	return b.vd.Validate(value)
+	return b.validate(value)
+}
+
+// ValidateContext validates whether the fields of value is valid with the context.
+func (b *Binding) ValidateContext(ctx context.Context, value interface{}) error {
+	return b.vd.ValidateContext(ctx, value)
+}
+
+// BindMultiStruct binds the request parameters to multiple structs and validates them if needed.
+// NOTE:
+//  The request is parsed only once, and the path parameters are decoded by the PathParamsDecoder;
+//  If any struct fails validation, the errors of all the structs are combined.
+func (b *Binding) BindMultiStruct(req *http.Request, targets ...interface{}) error {
+	rc := newRequestCache(req)
+	pathParams := b.pathParamsOf(req, nil)
+	var errs []error
+	for _, target := range targets {
+		v, hasVd, err := b.bindRequest(target, rc, pathParams)
+		if err != nil {
+			return err
+		}
+		if hasVd {
+			if err = b.validate(v); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return joinErrors(errs)
+}
+
+// joinErrors combines the errors into validator.Errors, which keeps the original errors,
+// or returns nil if there is none.
+func joinErrors(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return validator.Errors(errs)
+	}
+}
+
+// BindChain binds the request parameters and validates them with the binders in order,
+// the first binder which succeeds wins, e.g. to try the binders of the API versions in turn.
+// NOTE:
+//  The struct is reset to its value before the chain when the next binder tries;
+//  The body is read once and reused by all the binders;
+//  If all the binders fail, the error of the last one is returned;
+//  The nil binder is the default binding, and if no binder, the default binding is used.
+func BindChain(req *http.Request, structPointer interface{}, binders ...*Binding) error {
+	if len(binders) == 0 {
+		binders = []*Binding{defaultBinding}
+	}
+	v := reflect.ValueOf(structPointer)
+	var orig reflect.Value
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		orig = reflect.New(v.Elem().Type()).Elem()
+		orig.Set(v.Elem())
+	}
+	var err error
+	for i, b := range binders {
+		if b == nil {
+			b = defaultBinding
+		}
+		if i > 0 && orig.IsValid() {
+			v.Elem().Set(orig)
+		}
+		if err = b.BindAndValidate(structPointer, req, nil); err == nil {
+			return nil
+		}
+	}
+	return err
 }
 
 func (b *Binding) bind(structPointer interface{}, req *http.Request, pathParams PathParams) (value reflect.Value, hasVd bool, err error) {
+	return b.bindRequest(structPointer, newRequestCache(req), b.pathParamsOf(req, pathParams))
+}
+
+func (b *Binding) pathParamsOf(req *http.Request, pathParams PathParams) PathParams {
+	if pathParams == nil && b.pathDecoder != nil {
+		return b.pathDecoder(req)
+	}
+	return pathParams
+}
+
+func (b *Binding) bindRequest(structPointer interface{}, rc *requestCache, pathParams PathParams) (value reflect.Value, hasVd bool, err error) {
 	value, err = b.structValueOf(structPointer)
 	if err != nil {
 		return
@@ -105,42 +344,72 @@ func (b *Binding) bind(structPointer interface{}, req *http.Request, pathParams
 		return
 	}
 
-	bodyCodec := recv.getBodyCodec(req)
+	bodyCodec := rc.bodyCodec
 
-	bodyBytes, bodyString, err := recv.getBody(req)
+	bodyBytes, bodyString, err := recv.getBody(rc)
 	if err != nil {
 		return
 	}
-	err = recv.prebindBody(structPointer, value, bodyCodec, bodyBytes)
+	jsonUnmarshal, jsonIndependentRequired := b.jsonUnmarshaler()
+	err = recv.prebindBody(structPointer, value, bodyCodec, bodyBytes, jsonUnmarshal)
 	if err != nil {
 		return
 	}
+	if b.sanitizer != nil && len(bodyBytes) > 0 && (bodyCodec == bodyJSON || bodyCodec == bodyProtobuf) {
+		// the body is decoded into the whole struct, including the nested structs
+		if err = sanitizeValue(b.sanitizer, b.bindErrFactory, value, ""); err != nil {
+			return
+		}
+	}
 
-	postForm, err := recv.getPostForm(req, bodyCodec)
+	postForm, err := recv.getPostForm(rc, b.maxFormFields)
 	if err != nil {
 		return
 	}
 
-	queryValues := recv.getQuery(req)
-	cookies := recv.getCookies(req)
+	queryValues := recv.getQuery(rc)
+	if b.keyTransform != nil && queryValues != nil {
+		queryValues = transformKeys(queryValues, b.keyTransform)
+	}
 
-	for _, param := range recv.params {
+	if !recv.needsSlowPath && !rc.tracking() {
+		err = b.bindStringOnly(recv, expr, rc, bodyCodec, queryValues, postForm)
+		return value, recv.hasVd, err
+	}
 
-		for i, info := range param.tagInfos {
+	cookies, err := recv.getCookies(rc, b.maxHeaderSize)
+	if err != nil {
+		return
+	}
+	headers, err := recv.getHeaders(rc, b.maxHeaderSize)
+	if err != nil {
+		return
+	}
+	jwtClaims, err := recv.getJWTClaims(rc, b.jwtKeyFunc)
+	if err != nil {
+		return
+	}
+
+	for _, param := range recv.params {
+		tagInfos := param.tagInfos
+		if rc.sources != nil {
+			tagInfos = filterTagInfos(tagInfos, rc.sources)
+		}
+		for i, info := range tagInfos {
 			var found bool
 			switch info.paramIn {
 			case path:
 				found, err = param.bindPath(info, expr, pathParams)
 			case query:
-				found, err = param.bindQuery(info, expr, queryValues)
+				found, err = param.bindQuery(info, expr, param.queryOf(rc, queryValues))
 			case cookie:
-				err = param.bindCookie(info, expr, cookies)
+				err = param.bindCookie(info, expr, cookies, b.cookieDecoder(param, info.paramName))
 				found = err == nil
 			case header:
-				found, err = param.bindHeader(info, expr, req.Header)
+				found, err = param.bindHeader(info, expr, headers)
 			case form, json, protobuf:
 				if info.paramIn == in(bodyCodec) {
-					found, err = param.bindOrRequireBody(info, expr, bodyCodec, bodyString, postForm)
+					found, err = param.bindOrRequireBody(info, expr, bodyCodec, bodyString, postForm, jsonIndependentRequired)
 				} else if info.required {
 					found = false
 					err = info.requiredError
@@ -148,16 +417,82 @@ func (b *Binding) bind(structPointer interface{}, req *http.Request, pathParams
 			case raw_body:
 				err = param.bindRawBody(info, expr, bodyBytes)
 				found = err == nil
+			case jwt:
+				found, err = param.bindJWT(info, expr, jwtClaims)
+			case apikey:
+				found, err = b.bindAPIKey(param, info, expr, rc)
+			case basic_auth:
+				found, err = param.bindBasicAuth(info, expr, rc)
 			}
 			if found && err == nil {
+				if err = b.limitRate(param, expr); err != nil {
+					return value, recv.hasVd, err
+				}
+				if rc.bound != nil || b.logger != nil {
+					if raw, ok := boundRawValue(info, rc, pathParams, param.queryOf(rc, queryValues), postForm, cookies, bodyString); ok {
+						field := BoundField{
+							Selector: param.fieldSelector,
+							Source:   Source(info.paramIn),
+							RawValue: raw,
+						}
+						if rc.bound != nil {
+							*rc.bound = append(*rc.bound, field)
+						}
+						if b.logger != nil {
+							b.logBound(rc, param, info, field)
+						}
+					}
+				}
 				break
 			}
-			if (found || i == len(param.tagInfos)-1) && err != nil {
+			if (found || i == len(tagInfos)-1) && err != nil {
+				if rc.failed != nil {
+					*rc.failed = append(*rc.failed, fieldError{selector: param.fieldSelector, err: err})
+					break
+				}
 				return value, recv.hasVd, err
 			}
 		}
 	}
-	return value, recv.hasVd, nil
+	hasVd = recv.hasVd
+	if len(recv.embeddedIfaces) > 0 {
+		var ifaceHasVd bool
+		ifaceHasVd, err = b.bindEmbeddedIfaces(recv, value, expr, rc, pathParams)
+		hasVd = hasVd || ifaceHasVd
+		if err != nil {
+			return value, hasVd, err
+		}
+	}
+	if recv.rateLimit != nil && b.rateLimiter != nil {
+		return value, hasVd, b.rateLimiter("", "", *recv.rateLimit)
+	}
+	return value, hasVd, nil
+}
+
+// bindStringOnly is the fast path of bind for the struct
+// whose fields are all string types bound from query or form.
+func (b *Binding) bindStringOnly(recv *receiver, expr *tagexpr.TagExpr, rc *requestCache, bodyCodec codec, queryValues, postForm url.Values) error {
+	for _, param := range recv.params {
+		for i, info := range param.tagInfos {
+			var values url.Values
+			switch info.paramIn {
+			case query:
+				values = param.queryOf(rc, queryValues)
+			case form:
+				if bodyCodec == bodyForm {
+					values = postForm
+				}
+			}
+			found, err := param.bindString(info, expr, values)
+			if found && err == nil {
+				break
+			}
+			if (found || i == len(param.tagInfos)-1) && err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 func (b *Binding) structValueOf(structPointer interface{}) (reflect.Value, error) {
@@ -191,11 +526,36 @@ func (b *Binding) getOrPrepareReceiver(value reflect.Value) (*receiver, error) {
 	recv = &receiver{
 		params:        make([]*paramInfo, 0, 16),
 		looseZeroMode: b.config.LooseZeroMode,
+		sanitizer:     b.sanitizer,
 	}
 	var errExprSelector tagexpr.ExprSelector
 	var errMsg string
+	var errCyclic error
+	var opaqueSelectors []string
 
 	expr.RangeFields(func(fh *tagexpr.FieldHandler) bool {
+		if isOpaqueField(fh.StringSelector(), opaqueSelectors) {
+			return true
+		}
+		if goutil.DereferenceType(fh.StructField().Type) == syncMapType {
+			opaqueSelectors = append(opaqueSelectors, fh.StringSelector())
+		}
+		if isRepeatedStruct(value.Type(), fh) {
+			t := goutil.DereferenceType(fh.StructField().Type)
+			if b.hasRequestParams(t, make(map[reflect.Type]bool)) {
+				// the parameters of the repeated type cannot be bound level by level
+				errCyclic = &ErrCyclicStruct{Type: t, Field: fh.StringSelector()}
+				return false
+			}
+			// stop going deeper into the repeated type, otherwise its fields are prepared at every level,
+			// and the field itself is still bound, e.g. by the JSON body
+			opaqueSelectors = append(opaqueSelectors, fh.StringSelector())
+		}
+		if isEmbeddedIface(fh.StructField()) {
+			// the fields of the concrete type are bound at runtime
+			recv.embeddedIfaces = append(recv.embeddedIfaces, fh.StringSelector())
+			return true
+		}
 		if !fh.Value(true).CanSet() {
 			selector := fh.StringSelector()
 			errMsg = "field cannot be set: " + selector
@@ -205,6 +565,56 @@ func (b *Binding) getOrPrepareReceiver(value reflect.Value) (*receiver, error) {
 
 		tagKVs := b.config.parse(fh.StructField())
 		p := recv.getOrAddParam(fh, b.bindErrFactory)
+		if hint, ok := fh.StructField().Tag.Lookup(tagSizeHint); ok {
+			n, err := strconv.Atoi(strings.TrimSpace(hint))
+			if err != nil || n < 0 || goutil.DereferenceType(fh.StructField().Type).Kind() != reflect.Slice {
+				selector := fh.StringSelector()
+				errMsg = "invalid size_hint: " + selector
+				errExprSelector = tagexpr.ExprSelector(selector)
+				return false
+			}
+			p.sizeHint = n
+		}
+		if name, ok := fh.StructField().Tag.Lookup(tagKeyTransform); ok {
+			name = strings.TrimSpace(name)
+			if _, ok = keyTransforms[name]; !ok {
+				selector := fh.StringSelector()
+				errMsg = "invalid key_transform: " + selector
+				errExprSelector = tagexpr.ExprSelector(selector)
+				return false
+			}
+			p.keyTransform = name
+		}
+		if signed, ok := fh.StructField().Tag.Lookup(tagCookieSigned); ok {
+			var err error
+			if p.cookieSigned, err = strconv.ParseBool(strings.TrimSpace(signed)); err != nil {
+				selector := fh.StringSelector()
+				errMsg = "invalid cookie_signed: " + selector
+				errExprSelector = tagexpr.ExprSelector(selector)
+				return false
+			}
+		}
+		if s, ok := fh.StructField().Tag.Lookup(tagRateLimit); ok && fh.StructField().Name != "_" {
+			limit, err := parseRateLimit(s)
+			if err != nil {
+				selector := fh.StringSelector()
+				errMsg = "invalid rate_limit: " + selector
+				errExprSelector = tagexpr.ExprSelector(selector)
+				return false
+			}
+			p.rateLimit = &limit
+			recv.rateLimited = true
+		}
+		p.deprecation, p.deprecated = fh.StructField().Tag.Lookup(tagDeprecated)
+		if encrypted, ok := fh.StructField().Tag.Lookup(tagCookieEncrypted); ok {
+			var err error
+			if p.cookieEncrypted, err = strconv.ParseBool(strings.TrimSpace(encrypted)); err != nil {
+				selector := fh.StringSelector()
+				errMsg = "invalid cookie_encrypted: " + selector
+				errExprSelector = tagexpr.ExprSelector(selector)
+				return false
+			}
+		}
 		tagInfos := [maxIn]*tagInfo{}
 	L:
 		for _, tagKV := range tagKVs {
@@ -229,10 +639,43 @@ func (b *Binding) getOrPrepareReceiver(value reflect.Value) (*receiver, error) {
 				paramIn = json
 			case b.config.RawBody:
 				paramIn = raw_body
+			case b.config.jwtClaim:
+				paramIn = jwt
+			case b.config.apiKey:
+				paramIn = apikey
+			case b.config.basicAuth:
+				paramIn = basic_auth
 			default:
 				continue L
 			}
-			tagInfos[paramIn] = tagKV.defaultSplit()
+			info := tagKV.defaultSplit()
+			if paramIn == apikey && info.paramName != "-" {
+				enabled, err := strconv.ParseBool(info.paramName)
+				if err != nil {
+					selector := fh.StringSelector()
+					errMsg = "invalid apikey: " + selector
+					errExprSelector = tagexpr.ExprSelector(selector)
+					return false
+				}
+				if !enabled {
+					continue L
+				}
+			}
+			if paramIn == header && info.paramName == headerAll &&
+				goutil.DereferenceType(fh.StructField().Type) != syncMapType {
+				selector := fh.StringSelector()
+				errMsg = "invalid header: " + selector
+				errExprSelector = tagexpr.ExprSelector(selector)
+				return false
+			}
+			if paramIn == basic_auth && info.paramName != "-" &&
+				info.paramName != basicAuthUsername && info.paramName != basicAuthPassword {
+				selector := fh.StringSelector()
+				errMsg = "invalid basic_auth: " + selector
+				errExprSelector = tagexpr.ExprSelector(selector)
+				return false
+			}
+			tagInfos[paramIn] = info
 		}
 		for i, info := range tagInfos {
 			if info != nil {
@@ -262,14 +705,26 @@ func (b *Binding) getOrPrepareReceiver(value reflect.Value) (*receiver, error) {
 		if !recv.hasVd {
 			_, recv.hasVd = tagKVs.lookup(b.config.Validator)
 		}
+		if !recv.hasVd {
+			// the modifiers are applied in the validation
+			_, recv.hasVd = fh.StructField().Tag.Lookup(validator.ModifierTagName)
+		}
 		return true
 	})
 
+	if errCyclic != nil {
+		return nil, errCyclic
+	}
 	if errMsg != "" {
 		return nil, b.bindErrFactory(errExprSelector.String(), errMsg)
 	}
+	if recv.rateLimit, err = structRateLimit(value.Type()); err != nil {
+		return nil, b.bindErrFactory("_", "invalid rate_limit: _")
+	}
+	recv.rateLimited = recv.rateLimited || recv.rateLimit != nil
 
 	recv.initParams()
+	recv.initSlowPath(b.logger != nil)
 
 	b.lock.Lock()
 	b.recvs[runtimeTypeID] = recv
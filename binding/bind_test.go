@@ -2,16 +2,27 @@ package binding_test
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
+	"html"
 	"io"
 	"io/ioutil"
+	"math/big"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"reflect"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/bytedance/go-tagexpr/binding"
+	vd "github.com/bytedance/go-tagexpr/validator"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/henrylee2cn/goutil/httpbody"
 	"github.com/stretchr/testify/assert"
 )
@@ -78,6 +89,22 @@ func TestQueryString(t *testing.T) {
 	assert.Equal(t, (*string)(nil), recv.Z)
 }
 
+func TestOmitEmptyLooseZero(t *testing.T) {
+	type Recv struct {
+		Email string `query:"email" vd:"?email($)"`
+		Age   int    `query:"age" vd:"?$>=18"`
+		Code  string `query:"code,required" vd:"?len($)==4"`
+	}
+	binder := binding.New(nil).SetLooseZeroMode(true)
+	req := newRequest("http://localhost:8080/?email=&age=&code=", nil, nil, nil)
+	recv := new(Recv)
+	assert.NoError(t, binder.BindAndValidate(recv, req, nil))
+	req = newRequest("http://localhost:8080/?email=&age=1&code=", nil, nil, nil)
+	assert.EqualError(t, binder.BindAndValidate(recv, req, nil), "validating Age: fail")
+	req = newRequest("http://localhost:8080/?email=&age=", nil, nil, nil)
+	assert.EqualError(t, binder.BindAndValidate(recv, req, nil), "binding Code: missing required parameter")
+}
+
 func TestGetBody(t *testing.T) {
 	type Recv struct {
 		X **struct {
@@ -372,6 +399,37 @@ func TestJSON(t *testing.T) {
 	assert.Equal(t, (int64)(6), *recv.Z)
 }
 
+func TestJSONNull(t *testing.T) {
+	type Recv struct {
+		Name  *string `json:"name"`
+		Nick  *string `json:"nick"`
+		Alias *string `json:"alias"`
+		Age   *int    `json:"age"`
+		Inner *struct {
+			Tags *[]string `json:"tags"`
+		} `json:"inner"`
+		Title string `json:"title"`
+	}
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	req := newRequest("", header, nil, strings.NewReader(`{"name":"alice","nick":null,"age":null,"inner":{"tags":null},"title":null}`))
+	recv := &Recv{Title: "keep"}
+	err := binding.New(nil).BindAndValidate(recv, req, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", *recv.Name)
+	if assert.NotNil(t, recv.Nick) {
+		assert.Equal(t, "", *recv.Nick)
+	}
+	assert.Nil(t, recv.Alias)
+	if assert.NotNil(t, recv.Age) {
+		assert.Equal(t, 0, *recv.Age)
+	}
+	if assert.NotNil(t, recv.Inner) && assert.NotNil(t, recv.Inner.Tags) {
+		assert.Nil(t, *recv.Inner.Tags)
+	}
+	assert.Equal(t, "keep", recv.Title)
+}
+
 func BenchmarkBindJSON(b *testing.B) {
 	type Recv struct {
 		X **struct {
@@ -453,6 +511,89 @@ func BenchmarkStdJSON(b *testing.B) {
 	}
 }
 
+func TestStringOnly(t *testing.T) {
+	type Recv struct {
+		A string `query:"a"`
+		B string `query:"b,required"`
+		C string `form:"c" query:"c"`
+		D string `form:"d,required"`
+	}
+	values := make(url.Values)
+	values.Add("c", "c-from-form")
+	contentType, bodyReader := httpbody.NewFormBody2(values, nil)
+	header := make(http.Header)
+	header.Set("Content-Type", contentType)
+	req := newRequest("http://localhost/?a=a1&a=a2&b=b1&c=c-from-query", header, nil, bodyReader)
+	recv := new(Recv)
+	binder := binding.New(nil)
+	err := binder.Bind(recv, req, nil)
+	assert.EqualError(t, err, "binding D: missing required parameter")
+	assert.Equal(t, "a1", recv.A)
+	assert.Equal(t, "b1", recv.B)
+	assert.Equal(t, "c-from-form", recv.C)
+
+	req = newRequest("http://localhost/?a=a1&d=d1&c=c-from-query", nil, nil, nil)
+	recv = new(Recv)
+	err = binder.Bind(recv, req, nil)
+	assert.EqualError(t, err, "binding B: missing required parameter")
+}
+
+func BenchmarkBindStringOnly(b *testing.B) {
+	type Recv struct {
+		A string `query:"a"`
+		B string `query:"b"`
+		C string `query:"c"`
+		D string `query:"d"`
+	}
+	benchmarkBindQuery(b, new(Recv))
+}
+
+func BenchmarkBindGeneral(b *testing.B) {
+	type Recv struct {
+		A string `query:"a"`
+		B string `query:"b"`
+		C string `query:"c"`
+		D *int   `query:"d"`
+	}
+	benchmarkBindQuery(b, new(Recv))
+}
+
+// BenchmarkBindSizeHint binds the slice fields shorter than their size hints.
+// NOTE:
+//  Before sizing the slices once: 49 allocs/op, 2712 B/op;
+//  After: 30 allocs/op, 2008 B/op.
+func BenchmarkBindSizeHint(b *testing.B) {
+	type Recv struct {
+		IDs  []int        `query:"id" size_hint:"16"`
+		Refs []*int64     `query:"ref" size_hint:"16"`
+		Tags []string     `query:"tag" size_hint:"16"`
+		At   []*time.Time `query:"t" size_hint:"16"`
+	}
+	req := newRequest("http://localhost:8080/?id=1&id=2&id=3&ref=4&ref=5&tag=x&tag=y&t=2019-09-04T14:05:24Z", nil, nil, nil)
+	binder := binding.New(nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := binder.Bind(new(Recv), req, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkBindQuery(b *testing.B, recv interface{}) {
+	req := newRequest("http://localhost:8080/?a=a1&b=b1&c=c1&d=1", nil, nil, nil)
+	binder := binding.New(nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := binder.Bind(recv, req, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 type testPathParams struct{}
 
 func (testPathParams) Get(name string) (string, bool) {
@@ -550,6 +691,699 @@ func TestAuto(t *testing.T) {
 	assert.EqualError(t, err, "validating A: fail")
 }
 
+func TestBindMultiStruct(t *testing.T) {
+	type PathRecv struct {
+		A string `path:"a"`
+	}
+	type QueryRecv struct {
+		B string `query:"b" vd:"$=='b'"`
+	}
+	type BodyRecv struct {
+		C string `json:"c" vd:"$=='c'"`
+		D int    `json:"d"`
+	}
+	contentType, bodyReader, err := httpbody.NewJSONBody(map[string]interface{}{"c": "x", "d": 1})
+	assert.NoError(t, err)
+	header := make(http.Header)
+	header.Set("Content-Type", contentType)
+	req := newRequest("http://localhost/?b=x", header, nil, bodyReader)
+	pathRecv, queryRecv, bodyRecv, bodyRecv2 := new(PathRecv), new(QueryRecv), new(BodyRecv), new(BodyRecv)
+	binder := binding.New(nil).SetPathParamsDecoder(func(*http.Request) binding.PathParams {
+		return new(testPathParams)
+	})
+	err = binder.BindMultiStruct(req, pathRecv, queryRecv, bodyRecv, bodyRecv2)
+	assert.EqualError(t, err, "validating B: fail\tvalidating C: fail\tvalidating C: fail")
+	var bindErr *binding.Error
+	assert.True(t, errors.As(err, &bindErr))
+	assert.Equal(t, "B", bindErr.FailField)
+	assert.Equal(t, "a1", pathRecv.A)
+	assert.Equal(t, "x", queryRecv.B)
+	assert.Equal(t, "x", bodyRecv.C)
+	assert.Equal(t, 1, bodyRecv.D)
+	assert.Equal(t, bodyRecv, bodyRecv2)
+}
+
+func TestBindMerge(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type View struct {
+		ID      string
+		Page    int
+		Name    string
+		Address Address
+		Note    string
+	}
+	type PathRecv struct {
+		ID string `path:"a"`
+	}
+	type QueryRecv struct {
+		Page int    `query:"page" vd:"$>0"`
+		Note string `query:"note"`
+	}
+	type BodyRecv struct {
+		Name    string  `json:"name"`
+		Page    string  `json:"page"`
+		Address Address `json:"address"`
+	}
+	newReq := func(query string) *http.Request {
+		contentType, bodyReader, err := httpbody.NewJSONBody(map[string]interface{}{
+			"name":    "henry",
+			"page":    "x",
+			"address": map[string]interface{}{"city": "beijing"},
+		})
+		assert.NoError(t, err)
+		header := make(http.Header)
+		header.Set("Content-Type", contentType)
+		return newRequest("http://localhost/?"+query, header, nil, bodyReader)
+	}
+	binder := binding.New(nil).SetPathParamsDecoder(func(*http.Request) binding.PathParams {
+		return new(testPathParams)
+	})
+
+	view := &View{Note: "keep"}
+	err := binder.BindMerge(newReq("page=2"), view, new(PathRecv), new(QueryRecv), new(BodyRecv))
+	assert.NoError(t, err)
+	// the unbound Note and the mismatched type of Page are not merged
+	assert.Equal(t, &View{ID: "a1", Page: 2, Name: "henry", Address: Address{City: "beijing"}, Note: "keep"}, view)
+
+	view = new(View)
+	err = binder.BindMerge(newReq("page=0"), view, new(PathRecv), new(QueryRecv))
+	assert.EqualError(t, err, "validating Page: fail")
+	assert.Equal(t, new(View), view)
+	assert.NoError(t, binding.BindMerge(newReq("")))
+}
+
+func TestBindChain(t *testing.T) {
+	type Recv struct {
+		ID   int    `query:"id"`
+		Name string `json:"name" vd:"len($)>3" vd1:"len($)>0"`
+		Ver  string `v2header:"X-Ver"`
+	}
+	v2 := binding.New(&binding.Config{Header: "v2header"})
+	v1 := binding.New(&binding.Config{Validator: "vd1"})
+	newReq := func(name string) *http.Request {
+		contentType, bodyReader, err := httpbody.NewJSONBody(map[string]interface{}{"name": name})
+		assert.NoError(t, err)
+		header := make(http.Header)
+		header.Set("Content-Type", contentType)
+		header.Set("X-Ver", "2")
+		return newRequest("http://localhost/?id=1", header, nil, bodyReader)
+	}
+
+	recv := &Recv{Ver: "orig"}
+	assert.NoError(t, binding.BindChain(newReq("henry"), recv, v2, v1))
+	assert.Equal(t, &Recv{ID: 1, Name: "henry", Ver: "2"}, recv)
+
+	// the v2 binder fails, and the struct is reset before the v1 binder tries
+	recv = &Recv{Ver: "orig"}
+	assert.NoError(t, binding.BindChain(newReq("ab"), recv, v2, v1))
+	assert.Equal(t, &Recv{ID: 1, Name: "ab", Ver: "orig"}, recv)
+
+	recv = new(Recv)
+	assert.EqualError(t, binding.BindChain(newReq(""), recv, v2, v1), "validating Name: fail")
+	assert.EqualError(t, binding.BindChain(newReq("ab"), new(Recv)), "validating Name: fail")
+	assert.NoError(t, binding.BindChain(newReq("henry"), new(Recv), nil))
+}
+
+func TestModifier(t *testing.T) {
+	type Recv struct {
+		Email string   `query:"email" mod:"trim,lower"`
+		Tags  []string `query:"tag" mod:"trim"`
+	}
+	req := newRequest("http://localhost/?email=+Henry@Example.COM+&tag=+a&tag=b+", nil, nil, nil)
+	recv := new(Recv)
+	err := binding.BindAndValidate(recv, req, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "henry@example.com", recv.Email)
+	assert.Equal(t, []string{"a", "b"}, recv.Tags)
+}
+
+func TestBindGraphQL(t *testing.T) {
+	type Address struct {
+		City string `gql:"city"`
+		Zip  *int   `json:"zip"`
+	}
+	type Recv struct {
+		ID       string   `gql:"id"`
+		UserID   uint32   `gql:"userId"`
+		Age      int8     `json:"age"`
+		Score    float32  `gql:"score"`
+		Admin    *bool    `gql:"admin"`
+		Tags     []string `gql:"tags"`
+		Address  Address  `gql:"address"`
+		Created  time.Time
+		Ignored  string `gql:"-"`
+		NotFound *string
+	}
+	var recv Recv
+	err := binding.BindGraphQL(map[string]interface{}{
+		"id":      float64(123),
+		"userId":  json.Number("456"),
+		"age":     float64(18),
+		"score":   99,
+		"admin":   true,
+		"tags":    []interface{}{"a", "b"},
+		"address": map[string]interface{}{"city": "Beijing", "zip": float64(100000)},
+		"Created": "2019-09-04T18:04:08+08:00",
+		"-":       "x",
+		"Ignored": "x",
+	}, &recv)
+	assert.NoError(t, err)
+	assert.Equal(t, "123", recv.ID)
+	assert.Equal(t, uint32(456), recv.UserID)
+	assert.Equal(t, int8(18), recv.Age)
+	assert.Equal(t, float32(99), recv.Score)
+	assert.Equal(t, true, *recv.Admin)
+	assert.Equal(t, []string{"a", "b"}, recv.Tags)
+	assert.Equal(t, "Beijing", recv.Address.City)
+	assert.Equal(t, 100000, *recv.Address.Zip)
+	assert.Equal(t, "2019-09-04T18:04:08+08:00", recv.Created.Format(time.RFC3339))
+	assert.Equal(t, "", recv.Ignored)
+	assert.Nil(t, recv.NotFound)
+
+	err = binding.BindGraphQL(map[string]interface{}{"age": 1.5}, &recv)
+	assert.EqualError(t, err, "binding age: parameter type does not match binding data")
+	err = binding.BindGraphQL(map[string]interface{}{"age": float64(300)}, &recv)
+	assert.EqualError(t, err, "binding age: parameter type does not match binding data")
+	err = binding.BindGraphQL(map[string]interface{}{"address": map[string]interface{}{"city": true}}, &recv)
+	assert.EqualError(t, err, "binding address.city: parameter type does not match binding data")
+	err = binding.BindGraphQL(map[string]interface{}{"admin": "true"}, &recv)
+	assert.EqualError(t, err, "binding admin: parameter type does not match binding data")
+
+	// the ID is serialized as the string
+	err = binding.BindGraphQL(map[string]interface{}{"userId": "42", "age": "-7"}, &recv)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(42), recv.UserID)
+	assert.Equal(t, int8(-7), recv.Age)
+	err = binding.BindGraphQL(map[string]interface{}{"age": "x42"}, &recv)
+	assert.EqualError(t, err, "binding age: parameter type does not match binding data")
+}
+
+type grpcAddress struct {
+	City string `protobuf:"bytes,1,opt,name=city,proto3" json:"city,omitempty"`
+}
+
+type grpcRequest struct {
+	UserId  int64             `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Name    string            `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Tags    []string          `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	Address *grpcAddress      `protobuf:"bytes,4,opt,name=address,proto3" json:"address,omitempty"`
+	Labels  map[string]string `protobuf:"bytes,5,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Count   uint32            `protobuf:"varint,6,opt,name=count,proto3" json:"count,omitempty"`
+}
+
+func (*grpcRequest) Reset()         {}
+func (*grpcRequest) String() string { return "grpcRequest" }
+func (*grpcRequest) ProtoMessage()  {}
+
+func TestBindGRPCRequest(t *testing.T) {
+	type Address struct {
+		City string `proto_name:"city" vd:"$!=''"`
+	}
+	type Recv struct {
+		UserID  int               `protobuf:"varint,1,opt" vd:"$>0"`
+		Name    *string           `proto_name:"name"`
+		Tags    []string          `proto_name:"tags"`
+		Address *Address          `proto_name:"address"`
+		Labels  map[string]string `proto_name:"labels"`
+		Count   int8              `proto_name:"count"`
+		Other   string
+	}
+	req := &grpcRequest{
+		UserId:  1,
+		Name:    "henrylee2cn",
+		Tags:    []string{"a", "b"},
+		Address: &grpcAddress{City: "Beijing"},
+		Labels:  map[string]string{"k": "v"},
+		Count:   2,
+	}
+	recv := new(Recv)
+	err := binding.BindGRPCRequest(req, recv)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, recv.UserID)
+	assert.Equal(t, "henrylee2cn", *recv.Name)
+	assert.Equal(t, []string{"a", "b"}, recv.Tags)
+	assert.Equal(t, "Beijing", recv.Address.City)
+	assert.Equal(t, map[string]string{"k": "v"}, recv.Labels)
+	assert.Equal(t, int8(2), recv.Count)
+	assert.Equal(t, "", recv.Other)
+
+	req.Count = 300
+	err = binding.BindGRPCRequest(req, new(Recv))
+	assert.EqualError(t, err, "binding count: parameter type does not match binding data")
+
+	req.Count = 2
+	req.Address.City = ""
+	err = binding.BindGRPCRequest(req, new(Recv))
+	assert.EqualError(t, err, "validating Address.City: fail")
+
+	req.Address = nil
+	req.UserId = 0
+	recv = new(Recv)
+	err = binding.BindGRPCRequest(req, recv)
+	assert.EqualError(t, err, "validating UserID: fail")
+	assert.Nil(t, recv.Address)
+}
+
+func TestBindWSMessage(t *testing.T) {
+	type Recv struct {
+		A string `json:"a,required"`
+		B int    `form:"b"`
+		C []int
+		D string `query:"d"`
+	}
+	recv := new(Recv)
+	err := binding.BindWSMessage([]byte(`{"a":"a1","C":[1,2]}`), "application/json; charset=utf-8", recv)
+	assert.NoError(t, err)
+	assert.Equal(t, "a1", recv.A)
+	assert.Equal(t, []int{1, 2}, recv.C)
+
+	err = binding.BindWSMessage([]byte(`{"C":[1,2]}`), "application/json", new(Recv))
+	assert.EqualError(t, err, "binding a: missing required parameter")
+
+	type FormRecv struct {
+		B int `form:"b"`
+		C []int
+	}
+	formRecv := new(FormRecv)
+	err = binding.BindWSMessage([]byte(`b=2&C=3&C=4`), "application/x-www-form-urlencoded", formRecv)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, formRecv.B)
+	assert.Equal(t, []int{3, 4}, formRecv.C)
+
+	err = binding.BindWSMessage([]byte(`a`), "text/plain", recv)
+	assert.EqualError(t, err, "binding : unsupported content type: text/plain")
+}
+
+func TestBindSSE(t *testing.T) {
+	type Data struct {
+		A string `json:"a"`
+		B []int  `json:"b"`
+	}
+	type Recv struct {
+		Event string `sse:"event"`
+		Data  *Data  `sse:"data"`
+		Raw   string `sse:"data"`
+		ID    int64  `sse:"id"`
+		Retry *int   `sse:"retry"`
+	}
+	recv := new(Recv)
+	event := &binding.SSEEvent{
+		Event: "update",
+		Data:  `{"a":"x","b":[1,2]}`,
+		ID:    "42",
+		Retry: 3000,
+	}
+	err := binding.BindSSE(event, recv)
+	assert.NoError(t, err)
+	assert.Equal(t, "update", recv.Event)
+	assert.Equal(t, &Data{A: "x", B: []int{1, 2}}, recv.Data)
+	assert.Equal(t, event.Data, recv.Raw)
+	assert.Equal(t, int64(42), recv.ID)
+	assert.Equal(t, 3000, *recv.Retry)
+
+	type Count struct {
+		N int `sse:"data"`
+	}
+	count := new(Count)
+	assert.NoError(t, binding.BindSSE(&binding.SSEEvent{Data: "7"}, count))
+	assert.Equal(t, 7, count.N)
+	err = binding.BindSSE(&binding.SSEEvent{Data: "x"}, count)
+	assert.EqualError(t, err, "binding N: parameter type does not match binding data")
+	err = binding.BindSSE(&binding.SSEEvent{Data: `{"a":1}`}, recv)
+	assert.EqualError(t, err, "binding Data: parameter type does not match binding data")
+}
+
+type kafkaMessage struct {
+	key, value []byte
+	headers    []binding.KafkaHeader
+}
+
+func (m *kafkaMessage) Key() []byte                    { return m.key }
+func (m *kafkaMessage) Value() []byte                  { return m.value }
+func (m *kafkaMessage) Headers() []binding.KafkaHeader { return m.headers }
+
+func TestBindKafkaMessage(t *testing.T) {
+	type Recv struct {
+		Key     string `kafka_key:"true"`
+		TraceID []byte `kafka_header:"trace-id"`
+		Retry   int    `kafka_header:"retry"`
+		A       string `json:"a,required"`
+		B       []int  `json:"b"`
+	}
+	msg := &kafkaMessage{
+		key:   []byte("k1"),
+		value: []byte(`{"a":"x","b":[1,2]}`),
+		headers: []binding.KafkaHeader{
+			{Key: "trace-id", Value: []byte("abc")},
+			{Key: "retry", Value: []byte("3")},
+		},
+	}
+	recv := new(Recv)
+	err := binding.BindKafkaMessage(msg, recv)
+	assert.NoError(t, err)
+	assert.Equal(t, "k1", recv.Key)
+	assert.Equal(t, []byte("abc"), recv.TraceID)
+	assert.Equal(t, 3, recv.Retry)
+	assert.Equal(t, "x", recv.A)
+	assert.Equal(t, []int{1, 2}, recv.B)
+
+	msg.headers[1].Value = []byte("x")
+	err = binding.BindKafkaMessage(msg, new(Recv))
+	assert.EqualError(t, err, "binding Retry: parameter type does not match binding data")
+
+	msg.value = []byte(`{}`)
+	err = binding.BindKafkaMessage(msg, new(Recv))
+	assert.EqualError(t, err, "binding a: missing required parameter")
+
+	msg.headers = append(msg.headers, binding.KafkaHeader{Key: "Content-Type", Value: []byte("avro/binary")})
+	err = binding.BindKafkaMessage(msg, new(Recv))
+	assert.EqualError(t, err, "binding : unsupported content type: avro/binary")
+
+	err = binding.BindKafkaMessage(nil, new(Recv))
+	assert.Equal(t, binding.ErrNilMessage, err)
+}
+
+func TestBindAMQP(t *testing.T) {
+	type Recv struct {
+		RoutingKey string            `amqp_routing_key:"true"`
+		TraceID    string            `amqp:"trace-id"`
+		Retry      int               `amqp:"retry"`
+		Meta       map[string]string `amqp:"meta"`
+		A          string            `json:"a,required"`
+	}
+	msg := &binding.AMQPMessage{
+		Headers: map[string]interface{}{
+			"trace-id": []byte("abc"),
+			"retry":    int32(3),
+			"meta":     map[string]interface{}{"k": "v"},
+		},
+		RoutingKey: "user.created",
+		Body:       []byte(`{"a":"x"}`),
+	}
+	recv := new(Recv)
+	err := binding.BindAMQP(msg, recv)
+	assert.NoError(t, err)
+	assert.Equal(t, "user.created", recv.RoutingKey)
+	assert.Equal(t, "abc", recv.TraceID)
+	assert.Equal(t, 3, recv.Retry)
+	assert.Equal(t, map[string]string{"k": "v"}, recv.Meta)
+	assert.Equal(t, "x", recv.A)
+
+	msg.ContentType = "application/x-www-form-urlencoded"
+	msg.Body = []byte("a=y")
+	recv = new(Recv)
+	err = binding.BindAMQP(msg, recv)
+	assert.EqualError(t, err, "binding a: missing required parameter")
+
+	msg.ContentType = "text/plain"
+	err = binding.BindAMQP(msg, new(Recv))
+	assert.EqualError(t, err, "binding : unsupported content type: text/plain")
+
+	err = binding.BindAMQP(nil, new(Recv))
+	assert.Equal(t, binding.ErrNilMessage, err)
+}
+
+func TestBindAndValidateContext(t *testing.T) {
+	type Recv struct {
+		A string `query:"a" vd:"len($)>0"`
+	}
+	header := make(http.Header)
+	req := newRequest("http://localhost:8080/?a=x", header, nil, nil)
+	recv := new(Recv)
+	err := binding.BindAndValidateContext(nil, recv, req, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "x", recv.A)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = binding.BindAndValidateContext(ctx, new(Recv), req, nil)
+	_, ok := err.(*vd.ContextError)
+	assert.True(t, ok)
+}
+
+func TestNewBinding(t *testing.T) {
+	type Recv struct {
+		A int    `q:"a" check:"$>0"`
+		B string `json:"b,required"`
+	}
+	var unmarshaled bool
+	b := binding.NewBinding(
+		binding.WithConfig(&binding.Config{Query: "q", Validator: "check"}),
+		binding.WithLooseZeroMode(),
+		binding.WithJSONUnmarshaler(true, func(data []byte, v interface{}) error {
+			unmarshaled = true
+			return json.Unmarshal(data, v)
+		}),
+	)
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	recv := new(Recv)
+	err := b.BindAndValidate(recv, newRequest("http://localhost/?a=1", header, nil, strings.NewReader(`{}`)), nil)
+	// the required tag is verified by the JSON unmarshaler
+	assert.NoError(t, err)
+	assert.True(t, unmarshaled)
+	assert.Equal(t, 1, recv.A)
+
+	err = b.BindAndValidate(new(Recv), newRequest("http://localhost/?a=", header, nil, strings.NewReader(`{"b":"x"}`)), nil)
+	assert.EqualError(t, err, "validating A: fail")
+
+	// the config is applied first, whatever the position of WithConfig
+	b = binding.NewBinding(
+		binding.WithErrorFactory(nil, func(failField, msg string) error {
+			return errors.New("invalid " + failField)
+		}),
+		binding.WithLooseZeroMode(),
+		binding.WithConfig(&binding.Config{Query: "q", Validator: "check"}),
+	)
+	err = b.BindAndValidate(new(Recv), newRequest("http://localhost/?a=", header, nil, strings.NewReader(`{"b":"x"}`)), nil)
+	assert.EqualError(t, err, "invalid A")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b = binding.NewBinding(
+		binding.WithConfig(&binding.Config{Query: "q", Validator: "check"}),
+		binding.WithValidator(vd.New("check")),
+		binding.WithRequestContext(ctx),
+	)
+	err = b.BindAndValidate(new(Recv), newRequest("http://localhost/?a=0", header, nil, strings.NewReader(`{"b":"x"}`)), nil)
+	_, ok := err.(*vd.ContextError)
+	assert.True(t, ok)
+
+	// the options are set to each binding at once, and the setter is applied in the same way
+	opts := []binding.Option{
+		binding.WithConfig(&binding.Config{Query: "q", Validator: "check"}),
+		binding.WithLooseZeroMode(),
+	}
+	b1, b2 := binding.NewBinding(opts...), binding.NewBinding(opts...)
+	b1.SetLooseZeroMode(false)
+	err = b1.Bind(new(Recv), newRequest("http://localhost/?a=", header, nil, strings.NewReader(`{"b":"x"}`)), nil)
+	assert.EqualError(t, err, "binding A: parameter type does not match binding data")
+	assert.NoError(t, b2.Bind(new(Recv), newRequest("http://localhost/?a=", header, nil, strings.NewReader(`{"b":"x"}`)), nil))
+}
+
+func TestWriteProblemDetails(t *testing.T) {
+	type Recv struct {
+		A string `query:"a,required"`
+	}
+	header := make(http.Header)
+	header.Set("Accept", "text/html, application/problem+json;q=0.9")
+	req := newRequest("http://localhost:8080/", header, nil, nil)
+	assert.True(t, binding.AcceptsProblemDetails(req))
+	err := binding.BindAndValidate(new(Recv), req, nil)
+	assert.Error(t, err)
+
+	w := httptest.NewRecorder()
+	assert.NoError(t, binding.WriteProblemDetails(w, err, http.StatusBadRequest))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, binding.ProblemContentType, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"type":"about:blank","title":"Bad Request","status":400,"detail":"binding A: missing required parameter","invalid-params":[{"name":"A","reason":"missing required parameter"}]}`, w.Body.String())
+
+	type Recv2 struct {
+		A string `query:"a" vd:"len($)<4; code:'A_TOO_LONG'"`
+	}
+	err = binding.BindAndValidate(new(Recv2), newRequest("http://localhost:8080/?a=abcde", nil, nil, nil), nil)
+	p := binding.NewProblemDetails(err, http.StatusBadRequest)
+	assert.Equal(t, []binding.InvalidParam{{Name: "A", Reason: "validating fail", Code: "A_TOO_LONG"}}, p.InvalidParams)
+
+	req = newRequest("http://localhost:8080/", nil, nil, nil)
+	assert.False(t, binding.AcceptsProblemDetails(req))
+}
+
+func TestMaxFormFields(t *testing.T) {
+	type Recv struct {
+		A string `form:"a"`
+	}
+	newFormRequest := func(body string) *http.Request {
+		header := make(http.Header)
+		header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return newRequest("", header, nil, strings.NewReader(body))
+	}
+	b := binding.New(nil).SetMaxFormFields(2)
+	recv := new(Recv)
+	err := b.Bind(recv, newFormRequest("a=1&b=2&b=3"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", recv.A)
+	err = b.Bind(new(Recv), newFormRequest("a=1&b=2&c=3"), nil)
+	assert.Equal(t, binding.ErrTooManyFields, err)
+	err = b.SetMaxFormFields(0).Bind(new(Recv), newFormRequest("a=1&b=2&c=3"), nil)
+	assert.NoError(t, err)
+}
+
+func TestMaxHeaderSize(t *testing.T) {
+	type Recv struct {
+		A string `header:"X-A"`
+		B string `cookie:"b"`
+	}
+	header := make(http.Header)
+	header.Set("X-A", "12345")
+	header.Set("Cookie", "b=6789")
+	b := binding.New(nil).SetMaxHeaderSize(11)
+	recv := new(Recv)
+	err := b.Bind(recv, newRequest("", header, nil, nil), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "12345", recv.A)
+	assert.Equal(t, "6789", recv.B)
+
+	header.Set("X-A", "123456")
+	err = b.Bind(new(Recv), newRequest("", header, nil, nil), nil)
+	assert.Equal(t, binding.ErrHeaderTooLarge, err)
+	type QueryRecv struct {
+		C string `query:"c"`
+	}
+	err = b.Bind(new(QueryRecv), newRequest("", header, nil, nil), nil)
+	assert.NoError(t, err)
+	err = b.SetMaxHeaderSize(0).Bind(new(Recv), newRequest("", header, nil, nil), nil)
+	assert.NoError(t, err)
+}
+
+func TestStringSanitizer(t *testing.T) {
+	type Recv struct {
+		A string   `query:"a"`
+		B *string  `json:"b"`
+		C []string `header:"X-C"`
+		D int      `query:"d"`
+	}
+	b := binding.New(nil).SetStringSanitizer(func(field, value string) (string, error) {
+		if strings.Contains(value, "DROP TABLE") {
+			return "", errors.New("suspicious value")
+		}
+		return html.EscapeString(value), nil
+	})
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	header.Add("X-C", "<i>")
+	recv := new(Recv)
+	err := b.Bind(recv, newRequest("http://localhost/?a=<b>&d=1", header, nil, strings.NewReader(`{"b":"x&y"}`)), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "&lt;b&gt;", recv.A)
+	assert.Equal(t, "x&amp;y", *recv.B)
+	assert.Equal(t, []string{"&lt;i&gt;"}, recv.C)
+	assert.Equal(t, 1, recv.D)
+
+	recv = new(Recv)
+	err = b.Bind(recv, newRequest("http://localhost/?a=1%27%20DROP%20TABLE%20users", nil, nil, nil), nil)
+	assert.EqualError(t, err, "binding A: suspicious value")
+	// the rejected value is not assigned
+	assert.Equal(t, "", recv.A)
+
+	type Item struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+	type Nested struct {
+		Item  Item    `json:"item"`
+		Items []*Item `json:"items"`
+	}
+	nested := new(Nested)
+	body := `{"item":{"name":"<b>","tags":["<i>"]},"items":[{"name":"x&y"}]}`
+	err = b.Bind(nested, newRequest("", header, nil, strings.NewReader(body)), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "&lt;b&gt;", nested.Item.Name)
+	assert.Equal(t, []string{"&lt;i&gt;"}, nested.Item.Tags)
+	assert.Equal(t, "x&amp;y", nested.Items[0].Name)
+
+	err = b.Bind(new(Nested), newRequest("", header, nil, strings.NewReader(`{"items":[{"name":"DROP TABLE users"}]}`)), nil)
+	assert.EqualError(t, err, "binding Items.Name: suspicious value")
+
+	type StringOnly struct {
+		A string `query:"a"`
+	}
+	so := new(StringOnly)
+	err = b.Bind(so, newRequest("http://localhost/?a=<b>", nil, nil, nil), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "&lt;b&gt;", so.A)
+}
+
+func TestBindFull(t *testing.T) {
+	type Recv struct {
+		A string   `query:"a" header:"X-A"`
+		B int      `header:"X-B"`
+		C *string  `json:"c"`
+		D []string `query:"d"`
+		E string   `query:"e"`
+	}
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	header.Set("X-A", "ha")
+	header.Set("X-B", "2")
+	req := newRequest("http://localhost:8080/?d=x&d=y", header, nil, strings.NewReader(`{"c":"jc"}`))
+	recv := new(Recv)
+	result, err := binding.BindFull(recv, req, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []binding.BoundField{
+		{Selector: "A", Source: binding.SourceHeader, RawValue: "ha"},
+		{Selector: "B", Source: binding.SourceHeader, RawValue: "2"},
+		{Selector: "C", Source: binding.SourceJSON, RawValue: "jc"},
+		{Selector: "D", Source: binding.SourceQuery, RawValue: "x"},
+	}, result.BoundFields)
+	assert.Equal(t, 2, recv.B)
+}
+
+func TestSourceString(t *testing.T) {
+	assert.Equal(t, "path", binding.SourcePath.String())
+	assert.Equal(t, "query", binding.SourceQuery.String())
+	assert.Equal(t, "raw_body", binding.SourceRawBody.String())
+	assert.Equal(t, "undefined", binding.Source(0).String())
+}
+
+func TestBindPartial(t *testing.T) {
+	type Recv struct {
+		A string  `query:"a,required"`
+		B string  `header:"X-B,required"`
+		C *string `json:"c"`
+		D string  `query:"d" header:"X-D"`
+	}
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	header.Set("X-D", "hd")
+	bodyReader := strings.NewReader(`{"c":"jc"}`)
+	req := newRequest("http://localhost:8080/?a=qa&d=qd", header, nil, bodyReader)
+	recv := new(Recv)
+	err := binding.BindPartial(req, recv, binding.SourceQuery)
+	assert.NoError(t, err)
+	assert.Equal(t, "qa", recv.A)
+	assert.Equal(t, "", recv.B)
+	assert.Nil(t, recv.C)
+	assert.Equal(t, "qd", recv.D)
+
+	req = newRequest("http://localhost:8080/?a=qa", header, nil, strings.NewReader(`{"c":"jc"}`))
+	recv = new(Recv)
+	err = binding.BindPartial(req, recv, binding.SourceJSON, binding.SourceHeader)
+	assert.EqualError(t, err, "binding B: missing required parameter")
+
+	header.Set("X-B", "hb")
+	req = newRequest("http://localhost:8080/?a=qa", header, nil, strings.NewReader(`{"c":"jc"}`))
+	recv = new(Recv)
+	err = binding.BindPartial(req, recv, binding.SourceJSON, binding.SourceHeader)
+	assert.NoError(t, err)
+	assert.Equal(t, "", recv.A)
+	assert.Equal(t, "hb", recv.B)
+	assert.Equal(t, "jc", *recv.C)
+	assert.Equal(t, "hd", recv.D)
+}
+
 func TestTypeUnmarshal(t *testing.T) {
 	type Recv struct {
 		A time.Time   `form:"t1"`
@@ -581,6 +1415,136 @@ func TestTypeUnmarshal(t *testing.T) {
 	t.Logf("%v", recv)
 }
 
+func TestBigNumber(t *testing.T) {
+	type Recv struct {
+		Amount *big.Float `query:"amount"`
+		Ratio  big.Rat    `query:"ratio"`
+		Rates  []*big.Rat `query:"rate"`
+	}
+	req := newRequest("http://localhost/?amount=3.14159265358979323846&ratio=1/3&rate=0.5&rate=2/3", nil, nil, nil)
+	recv := new(Recv)
+	err := binding.BindAndValidate(recv, req, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "3.14159265358979323846", recv.Amount.Text('f', 20))
+	assert.Equal(t, "1/3", recv.Ratio.String())
+	assert.Equal(t, []string{"1/2", "2/3"}, []string{recv.Rates[0].String(), recv.Rates[1].String()})
+
+	req = newRequest("http://localhost/?amount=abc", nil, nil, nil)
+	err = binding.BindAndValidate(new(Recv), req, nil)
+	assert.EqualError(t, err, "binding Amount: parameter type does not match binding data")
+	req = newRequest("http://localhost/?ratio=1/0", nil, nil, nil)
+	err = binding.BindAndValidate(new(Recv), req, nil)
+	assert.EqualError(t, err, "binding Ratio: parameter type does not match binding data")
+}
+
+func TestBigInt(t *testing.T) {
+	type Recv struct {
+		Balance *big.Int  `query:"balance"`
+		Hash    big.Int   `query:"hash"`
+		Debts   []big.Int `query:"debt"`
+	}
+	const (
+		maxUint256 = "115792089237316195423570985008687907853269984665640564039457584007913129639935"
+		hash       = "0xFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF"
+	)
+	req := newRequest("http://localhost/?balance="+maxUint256+"&hash="+hash+"&debt=-10&debt=-0x10&debt=010", nil, nil, nil)
+	recv := new(Recv)
+	err := binding.BindAndValidate(recv, req, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, maxUint256, recv.Balance.String())
+	assert.Equal(t, maxUint256, recv.Hash.String())
+	assert.Equal(t, []string{"-10", "-16", "10"}, []string{recv.Debts[0].String(), recv.Debts[1].String(), recv.Debts[2].String()})
+
+	for _, s := range []string{"12.5", "0x", "0xZZ", "1_000", "--1"} {
+		req = newRequest("http://localhost/?balance="+url.QueryEscape(s), nil, nil, nil)
+		err = binding.BindAndValidate(new(Recv), req, nil)
+		assert.EqualError(t, err, "binding Balance: parameter type does not match binding data", s)
+	}
+}
+
+func TestSizeHint(t *testing.T) {
+	type Recv struct {
+		IDs   []int        `query:"id" size_hint:"100"`
+		Names *[]string    `query:"name" size_hint:"8"`
+		Times []*time.Time `query:"t" size_hint:"4"`
+		Tags  []string     `query:"tag" size_hint:"1"`
+	}
+	req := newRequest("http://localhost/?id=1&id=2&name=a&t=2019-09-04T14:05:24Z&tag=x&tag=y", nil, nil, nil)
+	recv := new(Recv)
+	err := binding.BindAndValidate(recv, req, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, recv.IDs)
+	assert.Equal(t, 100, cap(recv.IDs))
+	assert.Equal(t, []string{"a"}, *recv.Names)
+	assert.Equal(t, 8, cap(*recv.Names))
+	assert.Equal(t, 1, len(recv.Times))
+	assert.Equal(t, 4, cap(recv.Times))
+	// the hint is not a maximum
+	assert.Equal(t, []string{"x", "y"}, recv.Tags)
+
+	req = newRequest("http://localhost/?id=1&id=x", nil, nil, nil)
+	err = binding.BindAndValidate(new(Recv), req, nil)
+	assert.EqualError(t, err, "binding IDs: parameter type does not match binding data")
+
+	type BadRecv struct {
+		ID int `query:"id" size_hint:"100"`
+	}
+	err = binding.BindAndValidate(new(BadRecv), req, nil)
+	assert.EqualError(t, err, "binding ID: invalid size_hint: ID")
+	type BadRecv2 struct {
+		IDs []int `query:"id" size_hint:"-1"`
+	}
+	err = binding.BindAndValidate(new(BadRecv2), req, nil)
+	assert.EqualError(t, err, "binding IDs: invalid size_hint: IDs")
+}
+
+func TestKeyTransform(t *testing.T) {
+	for key, want := range map[string][3]string{
+		"userId":       {"user_id", "userId", "user-id"},
+		"UserID":       {"user_id", "userId", "user-id"},
+		"user_id":      {"user_id", "userId", "user-id"},
+		"user-id":      {"user_id", "userId", "user-id"},
+		"HTTPServerID": {"http_server_id", "httpServerId", "http-server-id"},
+		"page2Size":    {"page2_size", "page2Size", "page2-size"},
+		"a":            {"a", "a", "a"},
+	} {
+		assert.Equal(t, want, [3]string{binding.SnakeCase(key), binding.CamelCase(key), binding.KebabCase(key)}, key)
+	}
+
+	type Recv struct {
+		UserID   string   `query:"user_id"`
+		PageSize int      `query:"page_size"`
+		Tags     []string `query:"tag_names"`
+	}
+	req := newRequest("http://localhost/?userId=u1&PageSize=20&tag-names=a&tag_names=b", nil, nil, nil)
+	recv := new(Recv)
+	b := binding.NewBinding(binding.WithKeyTransform(binding.SnakeCase))
+	err := b.BindAndValidate(recv, req, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "u1", recv.UserID)
+	assert.Equal(t, 20, recv.PageSize)
+	sort.Strings(recv.Tags)
+	assert.Equal(t, []string{"a", "b"}, recv.Tags)
+
+	// the tag overrides the global transform
+	type Recv2 struct {
+		UserID string `query:"userId" key_transform:"camel"`
+		Name   string `query:"first-name" key_transform:"kebab"`
+		Email  string `query:"email"`
+	}
+	req = newRequest("http://localhost/?user_id=u2&firstName=n&email=e", nil, nil, nil)
+	recv2 := new(Recv2)
+	err = binding.BindAndValidate(recv2, req, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Recv2{UserID: "u2", Name: "n", Email: "e"}, *recv2)
+
+	type BadRecv struct {
+		A string `query:"a" key_transform:"upper"`
+	}
+	err = binding.BindAndValidate(new(BadRecv), req, nil)
+	assert.EqualError(t, err, "binding A: invalid key_transform: A")
+}
+
 func newRequest(u string, header http.Header, cookies []*http.Cookie, bodyReader io.Reader) *http.Request {
 	if header == nil {
 		header = make(http.Header)
@@ -606,3 +1570,621 @@ func newRequest(u string, header http.Header, cookies []*http.Cookie, bodyReader
 	}
 	return req
 }
+
+func TestCollectAll(t *testing.T) {
+	type Profile struct {
+		Bio string `query:"bio" vd:"len($)<5"`
+	}
+	type Recv struct {
+		Age     int      `query:"age" vd:"$>=18"`
+		Name    string   `query:"name" vd:"len($)>0"`
+		Profile *Profile `vd:"?"`
+		Score   float64  `query:"score" vd:"$>0"`
+		Token   string   `header:"X-Token,required" vd:"len($)==4"`
+	}
+	binder := binding.NewBinding(binding.WithCollectAll())
+	req := newRequest("http://localhost:8080/?age=x&bio=abcdef&score=y", nil, nil, nil)
+	err := binder.BindAndValidate(new(Recv), req, nil)
+	assert.EqualError(t, err, "binding Age: parameter type does not match binding data\t"+
+		"validating Name: fail\t"+
+		"validating Profile.Bio: fail\t"+
+		"binding Score: parameter type does not match binding data\t"+
+		"binding Token: missing required parameter")
+	errs, ok := err.(vd.Errors)
+	assert.True(t, ok)
+	assert.Len(t, errs, 5)
+
+	// the bound field is validated
+	req = newRequest("http://localhost:8080/?age=1&name=a&score=1", http.Header{"X-Token": []string{"abcd"}}, nil, nil)
+	err = binder.BindAndValidateContext(context.Background(), new(Recv), req, nil)
+	assert.EqualError(t, err, "validating Age: fail")
+
+	req = newRequest("http://localhost:8080/?age=18&name=a&score=1", http.Header{"X-Token": []string{"abcd"}}, nil, nil)
+	assert.NoError(t, binder.BindAndValidate(new(Recv), req, nil))
+
+	// the first error by default
+	req = newRequest("http://localhost:8080/?age=x&bio=abcdef&score=y", nil, nil, nil)
+	err = binding.New(nil).BindAndValidate(new(Recv), req, nil)
+	assert.EqualError(t, err, "binding Age: parameter type does not match binding data")
+}
+
+func TestBindValidateOnly(t *testing.T) {
+	type Recv struct {
+		ID    int    `query:"id" vd:"$>0"`
+		Name  string `json:"name" vd:"len($)>0"`
+		Email string `json:"email"`
+		Owner string `path:"name" vd:"$!=''"`
+	}
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	recv := &Recv{Email: "keep@b.com"}
+	req := newRequest("http://localhost:8080/?id=1", header, nil, strings.NewReader(`{"name":"a","email":"a@b.com"}`))
+	pathParams := new(testPathParams)
+	assert.EqualError(t, binding.BindValidateOnly(recv, req, nil), "validating Owner: fail")
+	assert.NoError(t, binding.BindValidateOnly(recv, req, pathParams))
+	assert.Equal(t, &Recv{Email: "keep@b.com"}, recv)
+	// the body can still be bound
+	assert.NoError(t, binding.BindAndValidate(recv, req, pathParams))
+	assert.Equal(t, &Recv{ID: 1, Name: "a", Email: "a@b.com", Owner: "henrylee2cn"}, recv)
+
+	recv = &Recv{ID: 2, Name: "b"}
+	req = newRequest("http://localhost:8080/?id=0", header, nil, strings.NewReader(`{"name":"a"}`))
+	assert.EqualError(t, binding.BindValidateOnly(recv, req, pathParams), "validating ID: fail")
+	assert.Equal(t, &Recv{ID: 2, Name: "b"}, recv)
+
+	assert.EqualError(t, binding.BindValidateOnly(Recv{}, req, nil), "binding : structPointer must be a non-nil struct pointer")
+}
+
+func TestBindSecure(t *testing.T) {
+	type Recv struct {
+		Card string `form:"card"`
+	}
+	newFormRequest := func() *http.Request {
+		header := make(http.Header)
+		header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return newRequest("", header, nil, strings.NewReader("card=4111"))
+	}
+	recv := new(Recv)
+	assert.Equal(t, binding.ErrInsecureRequest, binding.BindSecure(newFormRequest(), recv))
+	assert.Equal(t, "", recv.Card)
+
+	req := newFormRequest()
+	req.TLS = &tls.ConnectionState{}
+	assert.NoError(t, binding.BindSecure(req, recv))
+	assert.Equal(t, "4111", recv.Card)
+
+	req = newFormRequest()
+	req.Header.Set("X-Forwarded-Proto", "HTTPS, http")
+	recv = new(Recv)
+	assert.NoError(t, binding.BindSecure(req, recv))
+	assert.Equal(t, "4111", recv.Card)
+
+	req = newFormRequest()
+	req.Header.Set("X-Forwarded-Proto", "http")
+	assert.Equal(t, binding.ErrInsecureRequest, binding.BindSecure(req, new(Recv)))
+
+	// only the trusted headers are checked
+	binder := binding.NewBinding(binding.WithTrustedProxyHeaders("X-Original-Proto"))
+	req = newFormRequest()
+	req.Header.Set("X-Forwarded-Proto", "https")
+	assert.Equal(t, binding.ErrInsecureRequest, binder.BindSecure(req, new(Recv)))
+	req.Header.Set("X-Original-Proto", "https")
+	assert.NoError(t, binder.BindSecure(req, new(Recv)))
+
+	binder.SetTrustedProxyHeaders()
+	assert.Equal(t, binding.ErrInsecureRequest, binder.BindSecure(req, new(Recv)))
+}
+
+func TestSignedCookie(t *testing.T) {
+	type Recv struct {
+		Session string   `cookie:"session" cookie_signed:"true"`
+		IDs     []int    `cookie:"id" cookie_signed:"true"`
+		Theme   string   `cookie:"theme"`
+		Tags    []string `cookie:"tag" cookie_signed:"false"`
+	}
+	binder := binding.NewBinding(binding.WithCookieSigningKey([]byte("secret")))
+	newCookieRequest := func(session string) *http.Request {
+		return newRequest("", nil, []*http.Cookie{
+			{Name: "session", Value: session},
+			{Name: "id", Value: binder.SignCookie("id", "1")},
+			{Name: "id", Value: binder.SignCookie("id", "2")},
+			{Name: "theme", Value: "dark|x"},
+			{Name: "tag", Value: "a|b"},
+		}, nil)
+	}
+	recv := new(Recv)
+	assert.NoError(t, binder.Bind(recv, newCookieRequest(binder.SignCookie("session", "user|42")), nil))
+	assert.Equal(t, &Recv{Session: "user|42", IDs: []int{1, 2}, Theme: "dark|x", Tags: []string{"a|b"}}, recv)
+
+	signed := binder.SignCookie("session", "user|42")
+	// the value signed for another cookie is not replayed
+	replayed := binder.SignCookie("id", "user|42")
+	for _, session := range []string{"user|43" + signed[len("user|42"):], "user|42", signed + "x", "", replayed} {
+		err := binder.Bind(new(Recv), newCookieRequest(session), nil)
+		assert.EqualError(t, err, "binding Session: invalid cookie signature", session)
+	}
+	// the other key
+	err := binding.NewBinding(binding.WithCookieSigningKey([]byte("other"))).Bind(new(Recv), newCookieRequest(signed), nil)
+	assert.EqualError(t, err, "binding Session: invalid cookie signature")
+
+	binder.SetCookieSignatureSeparator(".")
+	assert.True(t, strings.HasPrefix(binder.SignCookie("session", "user|42"), "user|42."))
+	recv = new(Recv)
+	req := newRequest("", nil, []*http.Cookie{{Name: "session", Value: binder.SignCookie("session", "user.42")}}, nil)
+	assert.NoError(t, binder.Bind(recv, req, nil))
+	assert.Equal(t, "user.42", recv.Session)
+
+	type BadRecv struct {
+		A string `cookie:"a" cookie_signed:"yes"`
+	}
+	assert.EqualError(t, binder.Bind(new(BadRecv), req, nil), "binding A: invalid cookie_signed: A")
+}
+
+func TestEncryptedCookie(t *testing.T) {
+	type Profile struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	type Recv struct {
+		Token   string            `cookie:"token" cookie_encrypted:"true"`
+		Profile *Profile          `cookie:"profile" cookie_encrypted:"true"`
+		Prefs   map[string]string `cookie:"prefs" cookie_encrypted:"true"`
+		Secure  string            `cookie:"secure" cookie_signed:"true" cookie_encrypted:"true"`
+	}
+	oldKey := []byte("0123456789abcdef")
+	newKey := []byte("0123456789abcdef0123456789abcdef")
+	oldBinder := binding.NewBinding(binding.WithCookieEncryptionKeys(oldKey))
+	binder := binding.NewBinding(
+		binding.WithCookieEncryptionKeys(newKey, oldKey),
+		binding.WithCookieSigningKey([]byte("secret")),
+	)
+	encrypt := func(b *binding.Binding, name, plaintext string) string {
+		value, err := b.EncryptCookie(name, plaintext)
+		assert.NoError(t, err)
+		return value
+	}
+	token := encrypt(binder, "token", "t0k3n")
+	assert.NotEqual(t, token, encrypt(binder, "token", "t0k3n"))
+	req := newRequest("", nil, []*http.Cookie{
+		{Name: "token", Value: token},
+		// encrypted by the rotated key
+		{Name: "profile", Value: encrypt(oldBinder, "profile", `{"id":1,"name":"alice"}`)},
+		{Name: "prefs", Value: encrypt(binder, "prefs", `{"theme":"dark"}`)},
+		{Name: "secure", Value: binder.SignCookie("secure", encrypt(binder, "secure", "s"))},
+	}, nil)
+	recv := new(Recv)
+	assert.NoError(t, binder.Bind(recv, req, nil))
+	assert.Equal(t, &Recv{Token: "t0k3n", Profile: &Profile{ID: 1, Name: "alice"}, Prefs: map[string]string{"theme": "dark"}, Secure: "s"}, recv)
+
+	tampered := []byte(token)
+	tampered[len(tampered)/2] ^= 1
+	for _, c := range []struct {
+		cookie *http.Cookie
+		err    string
+	}{
+		{&http.Cookie{Name: "token", Value: "t0k3n"}, "binding Token: invalid encrypted cookie"},
+		{&http.Cookie{Name: "token", Value: string(tampered)}, "binding Token: invalid encrypted cookie"},
+		// encrypted for another cookie
+		{&http.Cookie{Name: "token", Value: encrypt(binder, "secure", "t0k3n")}, "binding Token: invalid encrypted cookie"},
+		{&http.Cookie{Name: "token", Value: encrypt(binding.NewBinding(binding.WithCookieEncryptionKeys([]byte("fedcba9876543210"))), "token", "x")}, "binding Token: invalid encrypted cookie"},
+		{&http.Cookie{Name: "profile", Value: encrypt(binder, "profile", "alice")}, "binding Profile: parameter type does not match binding data"},
+		{&http.Cookie{Name: "secure", Value: encrypt(binder, "secure", "s")}, "binding Secure: invalid cookie signature"},
+	} {
+		err := binder.Bind(new(Recv), newRequest("", nil, []*http.Cookie{c.cookie}, nil), nil)
+		assert.EqualError(t, err, c.err)
+	}
+
+	_, err := binding.NewBinding().EncryptCookie("token", "x")
+	assert.EqualError(t, err, "binding: no cookie encryption key")
+	assert.EqualError(t, binder.SetCookieEncryptionKeys(newKey, []byte("short")), "binding: invalid cookie encryption key: crypto/aes: invalid key size 5")
+	// the previous keys are kept
+	recv = new(Recv)
+	assert.NoError(t, binder.Bind(recv, newRequest("", nil, []*http.Cookie{{Name: "token", Value: token}}, nil), nil))
+	assert.Equal(t, "t0k3n", recv.Token)
+	assert.Panics(t, func() { binding.NewBinding(binding.WithCookieEncryptionKeys([]byte("short"))) })
+}
+
+func TestJWT(t *testing.T) {
+	type Recv struct {
+		Sub     string    `jwt:"sub,required"`
+		Issuer  string    `jwt:"iss"`
+		Expires time.Time `jwt:"exp"`
+		Issued  time.Time `jwt:"iat"`
+		Roles   []string  `jwt:"roles"`
+		Admin   bool      `jwt:"admin"`
+	}
+	key := []byte("secret")
+	binder := binding.NewBinding(binding.WithJWTKeyFunc(func(*jwt.Token) (interface{}, error) {
+		return key, nil
+	}))
+	sign := func(claims jwt.MapClaims, key []byte) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+		assert.NoError(t, err)
+		return token
+	}
+	newAuthRequest := func(token string) *http.Request {
+		header := make(http.Header)
+		header.Set("Authorization", "Bearer "+token)
+		return newRequest("", header, nil, nil)
+	}
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+	iat := time.Now().Add(-time.Minute).Truncate(time.Second)
+	token := sign(jwt.MapClaims{
+		"sub":   "alice",
+		"iss":   "auth.example.com",
+		"exp":   exp.Unix(),
+		"iat":   iat.Unix(),
+		"roles": []string{"admin", "dev"},
+		"admin": true,
+	}, key)
+	recv := new(Recv)
+	assert.NoError(t, binder.Bind(recv, newAuthRequest(token), nil))
+	assert.Equal(t, "alice", recv.Sub)
+	assert.Equal(t, "auth.example.com", recv.Issuer)
+	assert.True(t, exp.Equal(recv.Expires))
+	assert.True(t, iat.Equal(recv.Issued))
+	assert.Equal(t, []string{"admin", "dev"}, recv.Roles)
+	assert.True(t, recv.Admin)
+
+	for _, token := range []string{
+		"not.a.token",
+		sign(jwt.MapClaims{"sub": "alice"}, []byte("other")),
+		sign(jwt.MapClaims{"sub": "alice", "exp": time.Now().Add(-time.Hour).Unix()}, key),
+	} {
+		err := binder.Bind(new(Recv), newAuthRequest(token), nil)
+		var e *binding.ErrJWTInvalid
+		assert.True(t, errors.As(err, &e), err)
+	}
+	err := binder.Bind(new(Recv), newRequest("", nil, nil, nil), nil)
+	assert.EqualError(t, err, "binding Sub: missing required parameter")
+}
+
+func TestAPIKey(t *testing.T) {
+	type Recv struct {
+		UserID string `apikey:"true,required"`
+		Name   string `query:"name"`
+	}
+	errUnauthorized := errors.New("unauthorized")
+	var calls int
+	validate := func(key string) (string, error) {
+		calls++
+		switch key {
+		case "k1":
+			return "u1", nil
+		case "k2":
+			return "u2", nil
+		}
+		return "", errUnauthorized
+	}
+	binder := binding.NewBinding(binding.WithAPIKeyValidator(validate))
+	newKeyRequest := func(header, query string) *http.Request {
+		h := make(http.Header)
+		if header != "" {
+			h.Set("X-API-Key", header)
+		}
+		return newRequest("http://localhost/?name=a&api_key="+query, h, nil, nil)
+	}
+	for _, c := range []struct {
+		binder         *binding.Binding
+		header, query  string
+		expect, errMsg string
+	}{
+		{binder, "k1", "k2", "u1", ""},
+		{binder, "", "k2", "u2", ""},
+		{binding.NewBinding(binding.WithAPIKeyValidator(validate), binding.WithAPIKeySources(binding.SourceQuery, binding.SourceHeader)), "k1", "k2", "u2", ""},
+		{binding.NewBinding(binding.WithAPIKeyValidator(validate), binding.WithAPIKeySources(binding.SourceHeader)), "", "k2", "", "binding UserID: missing required parameter"},
+		{binder, "", "", "", "binding UserID: missing required parameter"},
+		{binding.NewBinding(), "k1", "", "", "binding: no API key validator"},
+	} {
+		recv := new(Recv)
+		err := c.binder.Bind(recv, newKeyRequest(c.header, c.query), nil)
+		if c.errMsg != "" {
+			assert.EqualError(t, err, c.errMsg)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, &Recv{UserID: c.expect, Name: "a"}, recv)
+	}
+
+	calls = 0
+	type Twice struct {
+		A string `apikey:"true"`
+		B string `apikey:"true"`
+		C string `apikey:"false"`
+	}
+	recv := new(Twice)
+	assert.NoError(t, binder.Bind(recv, newKeyRequest("k1", ""), nil))
+	assert.Equal(t, &Twice{A: "u1", B: "u1"}, recv)
+	assert.Equal(t, 1, calls)
+
+	err := binder.Bind(new(Recv), newKeyRequest("bad", ""), nil)
+	assert.True(t, errors.Is(err, errUnauthorized))
+
+	type Invalid struct {
+		A string `apikey:"yes"`
+	}
+	assert.EqualError(t, binder.Bind(new(Invalid), newKeyRequest("k1", ""), nil), "binding A: invalid apikey: A")
+}
+
+func TestBasicAuth(t *testing.T) {
+	type Recv struct {
+		User     string `basic_auth:"username"`
+		Password string `basic_auth:"password"`
+		Name     string `query:"name"`
+	}
+	req := newRequest("http://localhost/?name=a", nil, nil, nil)
+	req.SetBasicAuth("alice", "p@ss:word")
+	recv := new(Recv)
+	assert.NoError(t, binding.Bind(recv, req, nil))
+	assert.Equal(t, &Recv{User: "alice", Password: "p@ss:word", Name: "a"}, recv)
+
+	for _, authorization := range []string{"", "Bearer token", "Basic !invalid"} {
+		header := make(http.Header)
+		if authorization != "" {
+			header.Set("Authorization", authorization)
+		}
+		err := binding.Bind(new(Recv), newRequest("", header, nil, nil), nil)
+		assert.EqualError(t, err, "binding User: missing required parameter")
+	}
+
+	type Invalid struct {
+		A string `basic_auth:"user"`
+	}
+	assert.EqualError(t, binding.Bind(new(Invalid), req, nil), "binding A: invalid basic_auth: A")
+}
+
+func TestRateLimit(t *testing.T) {
+	type Recv struct {
+		_      struct{} `rate_limit:"1000/hour"`
+		UserID string   `query:"user_id" rate_limit:"100/min"`
+		Page   *int     `query:"page" rate_limit:"5/30s"`
+		Name   string   `query:"name"`
+	}
+	type call struct {
+		field, key string
+		limit      binding.RateLimit
+	}
+	var calls []call
+	errTooManyRequests := errors.New("too many requests")
+	binder := binding.NewBinding(binding.WithRateLimiter(func(field, key string, limit binding.RateLimit) error {
+		calls = append(calls, call{field, key, limit})
+		if key == "blocked" {
+			return errTooManyRequests
+		}
+		return nil
+	}))
+	recv := new(Recv)
+	assert.NoError(t, binder.Bind(recv, newRequest("http://localhost/?user_id=u1&page=2&name=a", nil, nil, nil), nil))
+	assert.Equal(t, []call{
+		{"UserID", "u1", binding.RateLimit{Count: 100, Period: time.Minute}},
+		{"Page", "2", binding.RateLimit{Count: 5, Period: 30 * time.Second}},
+		{"", "", binding.RateLimit{Count: 1000, Period: time.Hour}},
+	}, calls)
+	assert.Equal(t, "100/1m0s", calls[0].limit.String())
+
+	// the field which is not bound is not limited
+	calls = nil
+	assert.NoError(t, binder.Bind(new(Recv), newRequest("http://localhost/?user_id=u1", nil, nil, nil), nil))
+	assert.Len(t, calls, 2)
+
+	err := binder.Bind(new(Recv), newRequest("http://localhost/?user_id=blocked", nil, nil, nil), nil)
+	assert.True(t, errors.Is(err, errTooManyRequests))
+
+	// the tags are ignored without the rate limiter
+	assert.NoError(t, binding.Bind(new(Recv), newRequest("http://localhost/?user_id=blocked", nil, nil, nil), nil))
+
+	for _, c := range []struct {
+		recv interface{}
+		err  string
+	}{
+		{new(struct {
+			A string `query:"a" rate_limit:"100"`
+		}), "binding A: invalid rate_limit: A"},
+		{new(struct {
+			A string `query:"a" rate_limit:"0/min"`
+		}), "binding A: invalid rate_limit: A"},
+		{new(struct {
+			_ struct{} `rate_limit:"10/week"`
+			A string   `query:"a"`
+		}), "binding _: invalid rate_limit: _"},
+	} {
+		assert.EqualError(t, binder.Bind(c.recv, newRequest("http://localhost/?a=1", nil, nil, nil), nil), c.err)
+	}
+}
+
+type greeter interface {
+	Greet() string
+}
+
+type greeterImpl struct {
+	Name  string `query:"name" vd:"$!=''"`
+	Token string `header:"X-Token"`
+	Lang  string `json:"lang"`
+}
+
+func (g *greeterImpl) Greet() string { return "hello " + g.Name }
+
+type greeterValue struct {
+	Name string `query:"name"`
+}
+
+func (g greeterValue) Greet() string { return "hello " + g.Name }
+
+func TestEmbeddedInterface(t *testing.T) {
+	type Recv struct {
+		greeter
+		ID int `query:"id"`
+	}
+	header := make(http.Header)
+	header.Set("X-Token", "t1")
+	req := newRequest("http://localhost/?id=1&name=bob", header, nil, nil)
+
+	// the nil interface is skipped
+	recv := new(Recv)
+	assert.NoError(t, binding.New(nil).Bind(recv, req, nil))
+	assert.Equal(t, 1, recv.ID)
+	assert.Nil(t, recv.greeter)
+
+	// the interface is not set, so the unexported one is bound too
+	impl := new(greeterImpl)
+	recv = &Recv{greeter: impl}
+	assert.NoError(t, binding.New(nil).Bind(recv, req, nil))
+	assert.Equal(t, "bob", impl.Name)
+
+	type Greeter greeter
+	type Recv2 struct {
+		Greeter
+		ID int `query:"id"`
+	}
+	recv2 := new(Recv2)
+	assert.NoError(t, binding.New(nil).Bind(recv2, req, nil))
+	assert.Equal(t, 1, recv2.ID)
+	assert.Nil(t, recv2.Greeter)
+
+	// the fields of the concrete struct pointer are bound
+	impl = new(greeterImpl)
+	recv2 = &Recv2{Greeter: impl}
+	result, err := binding.New(nil).BindFull(recv2, req, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello bob", recv2.Greet())
+	assert.Equal(t, "t1", impl.Token)
+	assert.Equal(t, []binding.BoundField{
+		{Selector: "ID", Source: binding.SourceQuery, RawValue: "1"},
+		{Selector: "Greeter.Name", Source: binding.SourceQuery, RawValue: "bob"},
+		{Selector: "Greeter.Token", Source: binding.SourceHeader, RawValue: "t1"},
+	}, result.BoundFields)
+
+	// the JSON body is bound to the concrete struct
+	impl = new(greeterImpl)
+	recv2 = &Recv2{Greeter: impl}
+	jsonHeader := make(http.Header)
+	jsonHeader.Set("Content-Type", "application/json")
+	assert.NoError(t, binding.New(nil).Bind(recv2, newRequest("http://localhost/", jsonHeader, nil, strings.NewReader(`{"lang":"en"}`)), nil))
+	assert.Equal(t, "en", impl.Lang)
+
+	// the concrete struct is validated
+	recv2 = &Recv2{Greeter: new(greeterImpl)}
+	assert.NoError(t, binding.New(nil).BindAndValidate(recv2, req, nil))
+	recv2 = &Recv2{Greeter: new(greeterImpl)}
+	assert.EqualError(t, binding.New(nil).BindAndValidate(recv2, newRequest("http://localhost/?id=1", nil, nil, nil), nil), "validating Greeter.Name: fail")
+
+	// the concrete value which is not a struct pointer cannot be set, and is skipped
+	recv2 = &Recv2{Greeter: greeterValue{}}
+	assert.NoError(t, binding.New(nil).Bind(recv2, req, nil))
+	assert.Equal(t, "hello ", recv2.Greet())
+
+	// the interface referencing the struct itself is not bound again
+	type Node struct {
+		Greeter
+		ID int `query:"id"`
+	}
+	node := new(Node)
+	node.Greeter = node
+	assert.NoError(t, binding.New(nil).Bind(node, req, nil))
+	assert.Equal(t, 1, node.ID)
+}
+
+func TestCyclicStruct(t *testing.T) {
+	type Node struct {
+		Name string `query:"name"`
+		Next *Node
+	}
+	type Edge struct {
+		Weight int `query:"weight"`
+		To     *struct {
+			ID    string `query:"id"`
+			Edges []Edge
+			Back  *Edge
+		}
+	}
+	type Addr struct {
+		City string `query:"city"`
+	}
+	type Route struct {
+		From Addr
+		To   *Addr
+	}
+	type Category struct {
+		Name   string    `json:"name"`
+		Parent *Category `json:"parent"`
+	}
+	req := newRequest("http://localhost/?name=a&weight=1&city=x", nil, nil, nil)
+
+	// the parameters of the repeated type cannot be bound level by level
+	err := binding.New(nil).Bind(new(Node), req, nil)
+	var cyclic *binding.ErrCyclicStruct
+	if assert.True(t, errors.As(err, &cyclic)) {
+		assert.Equal(t, "Next", cyclic.Field)
+		assert.Equal(t, reflect.TypeOf(Node{}), cyclic.Type)
+	}
+	// the error is returned when preparing the receiver, every time
+	binder := binding.New(nil)
+	for i := 0; i < 2; i++ {
+		err = binder.Bind(new(Edge), req, nil)
+		if assert.True(t, errors.As(err, &cyclic)) {
+			assert.Equal(t, "To.Back", cyclic.Field)
+			assert.Equal(t, reflect.TypeOf(Edge{}), cyclic.Type)
+		}
+	}
+	assert.EqualError(t, err, "binding: cyclic struct binding_test.Edge referenced by the field To.Back")
+
+	// the recursive type is bound by the JSON body as a whole
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	body := `{"name":"c","parent":{"name":"b","parent":{"name":"a"}}}`
+	category := new(Category)
+	assert.NoError(t, binding.New(nil).Bind(category, newRequest("", header, nil, strings.NewReader(body)), nil))
+	assert.Equal(t, &Category{Name: "c", Parent: &Category{Name: "b", Parent: &Category{Name: "a"}}}, category)
+
+	// the repeated type which is not nested in itself is not cyclic
+	route := new(Route)
+	assert.NoError(t, binding.New(nil).Bind(route, req, nil))
+	assert.Equal(t, "x", route.From.City)
+	assert.Equal(t, "x", route.To.City)
+}
+
+func TestHeaderSyncMap(t *testing.T) {
+	type Recv struct {
+		Headers *sync.Map `header:"*"`
+		Token   string    `header:"X-Token"`
+		Copy    sync.Map  `header:"*,required"`
+	}
+	header := make(http.Header)
+	header.Set("X-Token", "t1")
+	header.Add("Accept", "text/html")
+	header.Add("Accept", "application/json")
+	recv := new(Recv)
+	assert.NoError(t, binding.New(nil).Bind(recv, newRequest("http://localhost/?seed=1&m=2", header, nil, nil), nil))
+	assert.Equal(t, "t1", recv.Token)
+	for _, m := range []*sync.Map{recv.Headers, &recv.Copy} {
+		got := make(map[interface{}]interface{})
+		m.Range(func(k, v interface{}) bool {
+			got[k] = v
+			return true
+		})
+		assert.Equal(t, map[interface{}]interface{}{
+			"X-Token": "t1",
+			"Accept":  []string{"text/html", "application/json"},
+		}, got)
+	}
+	// the multi-value header is copied
+	header["Accept"][0] = "x"
+	v, _ := recv.Headers.Load("Accept")
+	assert.Equal(t, []string{"text/html", "application/json"}, v)
+
+	// the existing map is updated
+	m := new(sync.Map)
+	m.Store("Keep", "k")
+	recv = &Recv{Headers: m}
+	assert.NoError(t, binding.New(nil).Bind(recv, newRequest("http://localhost/", header, nil, nil), nil))
+	assert.True(t, m == recv.Headers)
+	v, _ = m.Load("Keep")
+	assert.Equal(t, "k", v)
+
+	assert.EqualError(t, binding.New(nil).Bind(new(Recv), newRequest("http://localhost/", nil, nil, nil), nil), "binding Copy: missing required parameter")
+
+	type Invalid struct {
+		Headers map[string]string `header:"*"`
+	}
+	assert.EqualError(t, binding.New(nil).Bind(new(Invalid), newRequest("http://localhost/", header, nil, nil), nil), "binding Headers: invalid header: Headers")
+}
@@ -0,0 +1,64 @@
+// Package cloudevents binds the CloudEvent of github.com/cloudevents/sdk-go/v2.
+package cloudevents
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/bytedance/go-tagexpr/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+const tagCE = "ce"
+
+// BindCloudEvent uses the default binding to bind the CloudEvent to the struct.
+// NOTE:
+//  The attributes are bound to the fields tagged `ce:"$attribute"`, such as
+//  `ce:"type"`, `ce:"source"`, `ce:"id"`, `ce:"time"` and `ce:"datacontenttype"`;
+//  The other attribute names are looked up in the extension attributes;
+//  The data is bound to the body fields by the datacontenttype, the default is application/json.
+func BindCloudEvent(e event.Event, structPointer interface{}) error {
+	return Bind(binding.Default(), e, structPointer)
+}
+
+// Bind uses the binding @b to bind the CloudEvent to the struct.
+// NOTE:
+//  If b==nil, the default binding is used.
+func Bind(b *binding.Binding, e event.Event, structPointer interface{}) error {
+	if b == nil {
+		b = binding.Default()
+	}
+	return b.BindMessage(structPointer, e.Data(), e.DataContentType(), func(field reflect.StructField) ([]byte, bool) {
+		name, ok := field.Tag.Lookup(tagCE)
+		if !ok {
+			return nil, false
+		}
+		switch name {
+		case "specversion":
+			return []byte(e.SpecVersion()), true
+		case "type":
+			return []byte(e.Type()), true
+		case "source":
+			return []byte(e.Source()), true
+		case "id":
+			return []byte(e.ID()), true
+		case "subject":
+			return []byte(e.Subject()), true
+		case "dataschema":
+			return []byte(e.DataSchema()), true
+		case "datacontenttype":
+			return []byte(e.DataContentType()), true
+		case "time":
+			if e.Time().IsZero() {
+				return nil, false
+			}
+			return []byte(e.Time().Format(time.RFC3339Nano)), true
+		}
+		v, ok := e.Extensions()[name]
+		if !ok {
+			return nil, false
+		}
+		return []byte(fmt.Sprint(v)), true
+	})
+}
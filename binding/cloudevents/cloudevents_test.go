@@ -0,0 +1,48 @@
+package cloudevents_test
+
+import (
+	"testing"
+	"time"
+
+	bindce "github.com/bytedance/go-tagexpr/binding/cloudevents"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindCloudEvent(t *testing.T) {
+	type Recv struct {
+		Type     string     `ce:"type"`
+		Source   string     `ce:"source"`
+		ID       string     `ce:"id"`
+		Time     *time.Time `ce:"time"`
+		TimeText string     `ce:"time"`
+		Tenant   string     `ce:"tenant"`
+		A        string     `json:"a,required" vd:"len($)>0"`
+		B        int        `json:"b"`
+	}
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	e := event.New()
+	e.SetType("com.example.order.created")
+	e.SetSource("/orders")
+	e.SetID("1")
+	e.SetTime(now)
+	e.SetExtension("tenant", "acme")
+	assert.NoError(t, e.SetData("application/json", map[string]interface{}{"a": "x", "b": 2}))
+	recv := new(Recv)
+	err := bindce.BindCloudEvent(e, recv)
+	assert.NoError(t, err)
+	assert.Equal(t, "com.example.order.created", recv.Type)
+	assert.Equal(t, "/orders", recv.Source)
+	assert.Equal(t, "1", recv.ID)
+	if assert.NotNil(t, recv.Time) {
+		assert.True(t, now.Equal(*recv.Time))
+	}
+	assert.Equal(t, "2020-03-01T12:00:00Z", recv.TimeText)
+	assert.Equal(t, "acme", recv.Tenant)
+	assert.Equal(t, "x", recv.A)
+	assert.Equal(t, 2, recv.B)
+
+	assert.NoError(t, e.SetData("application/x-www-form-urlencoded", []byte("b=3")))
+	err = bindce.BindCloudEvent(e, new(Recv))
+	assert.EqualError(t, err, "binding a: missing required parameter")
+}
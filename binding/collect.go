@@ -0,0 +1,152 @@
+package binding
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/go-tagexpr/validator"
+)
+
+// fieldError the error of the field which failed to be bound.
+type fieldError struct {
+	selector string
+	err      error
+}
+
+// bindAndValidateAll binds the request parameters and validates them, and reports all the errors.
+// NOTE:
+//  The fields which failed to be bound are not validated;
+//  The error which prevents binding any field, such as the malformed body, is returned alone;
+//  If span!=nil, it records whether the validation passed.
+func (b *Binding) bindAndValidateAll(ctx context.Context, structPointer interface{}, req *http.Request, pathParams PathParams, span bindSpan) error {
+	rc := newRequestCache(req)
+	var failed []fieldError
+	rc.failed = &failed
+	value, hasVd, err := b.bindRequest(structPointer, rc, b.pathParamsOf(req, pathParams))
+	if err != nil {
+		return err
+	}
+	errs := make([]fieldError, len(failed), len(failed)+4)
+	selectors := make([]string, len(failed))
+	for i, f := range failed {
+		errs[i] = f
+		selectors[i] = f.selector
+	}
+	if hasVd {
+		err = b.vd.ValidateExcept(ctx, value, true, selectors...)
+		if span != nil {
+			span.validated(err == nil)
+		}
+		switch err.(type) {
+		case *validator.ContextError, *validator.FuncError:
+			// the aborted validation is not a field error
+			return err
+		}
+		vdErrs, ok := err.(validator.Errors)
+		if !ok && err != nil {
+			vdErrs = validator.Errors{err}
+		}
+		for _, e := range vdErrs {
+			errs = append(errs, fieldError{selector: failPathOf(e), err: e})
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	recv, err := b.getOrPrepareReceiver(value)
+	if err != nil {
+		return err
+	}
+	sortFieldErrors(errs, recv)
+	if len(errs) == 1 {
+		return errs[0].err
+	}
+	a := make(validator.Errors, len(errs))
+	for i, e := range errs {
+		a[i] = e.err
+	}
+	return a
+}
+
+// failPathOf returns the path of the field of the validation error, or "" if unknown.
+func failPathOf(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.FailField
+	}
+	var ve *validator.Error
+	if errors.As(err, &ve) {
+		return ve.FailPath
+	}
+	return ""
+}
+
+// sortFieldErrors sorts the errors in the order of the field declarations,
+// the error of the unknown field is placed at the end.
+func sortFieldErrors(errs []fieldError, recv *receiver) {
+	order := make(map[string]int, len(recv.params))
+	for i, param := range recv.params {
+		order[param.fieldSelector] = i
+	}
+	indexOf := func(path string) int {
+		// the indexes of the slice and map are removed, and the parent field is looked up
+		path = removePathIndexes(path)
+		for path != "" {
+			if i, ok := order[path]; ok {
+				return i
+			}
+			i := strings.LastIndexByte(path, '.')
+			if i < 0 {
+				break
+			}
+			path = path[:i]
+		}
+		return len(recv.params)
+	}
+	indexes := make([]int, len(errs))
+	for i, e := range errs {
+		indexes[i] = indexOf(e.selector)
+	}
+	sort.Stable(fieldErrorSorter{errs, indexes})
+}
+
+type fieldErrorSorter struct {
+	errs    []fieldError
+	indexes []int
+}
+
+func (s fieldErrorSorter) Len() int {
+	return len(s.errs)
+}
+
+func (s fieldErrorSorter) Less(i, j int) bool {
+	return s.indexes[i] < s.indexes[j]
+}
+
+func (s fieldErrorSorter) Swap(i, j int) {
+	s.errs[i], s.errs[j] = s.errs[j], s.errs[i]
+	s.indexes[i], s.indexes[j] = s.indexes[j], s.indexes[i]
+}
+
+// removePathIndexes removes the indexes of the path, such as '[0]' and '{K:a}'.
+func removePathIndexes(path string) string {
+	if !strings.ContainsAny(path, "[{") {
+		return path
+	}
+	var b strings.Builder
+	var depth int
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; {
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case depth == 0:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,146 @@
+package binding
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	jsonpkg "encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/bytedance/go-tagexpr"
+	"github.com/henrylee2cn/goutil"
+)
+
+// tagCookieEncrypted the tag of the encrypted cookie field, such as `cookie:"profile" cookie_encrypted:"true"`
+const tagCookieEncrypted = "cookie_encrypted"
+
+var (
+	errInvalidEncryptedCookie = errors.New("invalid encrypted cookie")
+	errNoCookieEncryptionKey  = errors.New("binding: no cookie encryption key")
+)
+
+// SetCookieEncryptionKey sets the AES key which decrypts the cookies of the fields tagged `cookie_encrypted:"true"`,
+// see SetCookieEncryptionKeys.
+// NOTE:
+//  return the error if the key is not 16, 24 or 32 bytes.
+func (b *Binding) SetCookieEncryptionKey(key []byte) error {
+	return b.SetCookieEncryptionKeys(key)
+}
+
+// SetCookieEncryptionKeys sets the AES keys which decrypt the cookies of the fields tagged `cookie_encrypted:"true"`,
+// the decryption tries each key in order, and EncryptCookie uses the first one, e.g. for the key rotation.
+// NOTE:
+//  The encrypted cookie value is the unpadded base64url of nonce+ciphertext+tag by AES-GCM,
+//  and the cookie name is authenticated as the additional data, so the value can not be moved to another cookie;
+//  The string field is bound with the plaintext, and the struct or map field is unmarshaled from the JSON plaintext;
+//  The binding fails if the cookie can not be decrypted;
+//  return the error and keep the previous keys if a key is not 16, 24 or 32 bytes.
+func (b *Binding) SetCookieEncryptionKeys(keys ...[]byte) error {
+	aeads, err := newCookieAEADs(keys)
+	if err != nil {
+		return err
+	}
+	b.update(Option{apply: func(o *options) {
+		o.cookieAEADs = aeads
+	}})
+	return nil
+}
+
+// newCookieAEADs returns the AES-GCM ciphers of the keys.
+func newCookieAEADs(keys [][]byte) ([]cipher.AEAD, error) {
+	aeads := make([]cipher.AEAD, len(keys))
+	for i, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("binding: invalid cookie encryption key: %s", err)
+		}
+		aeads[i], err = cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("binding: invalid cookie encryption key: %s", err)
+		}
+	}
+	return aeads, nil
+}
+
+// EncryptCookie returns the encrypted value of the cookie named @name decrypted by the binding,
+// which is encrypted by the first key of SetCookieEncryptionKeys.
+func (b *Binding) EncryptCookie(name, plaintext string) (string, error) {
+	if len(b.cookieAEADs) == 0 {
+		return "", errNoCookieEncryptionKey
+	}
+	aead := b.cookieAEADs[0]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(plaintext), []byte(name))), nil
+}
+
+// decryptCookie returns the plaintext of the encrypted cookie named @name, and whether it is decrypted by one of the keys.
+func (b *Binding) decryptCookie(name, value string) (string, bool) {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return "", false
+	}
+	for _, aead := range b.cookieAEADs {
+		if len(data) < aead.NonceSize() {
+			continue
+		}
+		nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+		if plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(name)); err == nil {
+			return string(plaintext), true
+		}
+	}
+	return "", false
+}
+
+// cookieDecoder returns the decoder of the signed or encrypted cookie of the param, or nil if it is neither.
+// NOTE:
+//  The signature of the cookie both signed and encrypted is verified before the decryption.
+func (b *Binding) cookieDecoder(p *paramInfo, name string) func(value string) (string, error) {
+	if !p.cookieSigned && !p.cookieEncrypted {
+		return nil
+	}
+	return func(value string) (string, error) {
+		var ok bool
+		if p.cookieSigned {
+			if value, ok = b.verifyCookie(name, value); !ok {
+				return "", errInvalidCookieSignature
+			}
+		}
+		if p.cookieEncrypted {
+			if value, ok = b.decryptCookie(name, value); !ok {
+				return "", errInvalidEncryptedCookie
+			}
+		}
+		return value, nil
+	}
+}
+
+// bindJSONCookie unmarshals the JSON plaintext of the encrypted cookie to the struct or map field,
+// and reports whether the field is a struct or map.
+func (p *paramInfo) bindJSONCookie(info *tagInfo, expr *tagexpr.TagExpr, plaintext string) (bool, error) {
+	t := goutil.DereferenceType(p.structField.Type)
+	if t.Kind() != reflect.Struct && t.Kind() != reflect.Map {
+		return false, nil
+	}
+	if _, ok := typeUnmarshalFuncs[t]; ok {
+		return false, nil
+	}
+	v, err := p.getField(expr, true)
+	if err != nil || !v.IsValid() {
+		return true, err
+	}
+	if err = jsonpkg.Unmarshal([]byte(plaintext), v.Addr().Interface()); err != nil {
+		return true, info.typeError
+	}
+	if p.sanitizer != nil {
+		return true, sanitizeValue(p.sanitizer, p.bindErrFactory, v, p.fieldSelector)
+	}
+	return true, nil
+}
@@ -1,6 +1,12 @@
 package binding
 
-import "net/http"
+import (
+	"context"
+	"net/http"
+
+	"github.com/gogo/protobuf/proto"
+	jwtpkg "github.com/golang-jwt/jwt/v4"
+)
 
 var defaultBinding = New(nil)
 
@@ -15,6 +21,9 @@ var defaultBinding = New(nil)
 //  validator tag name is 'vd';
 //  protobuf tag name is 'protobuf';
 //  json tag name is 'json';
+//  jwt tag name is 'jwt';
+//  apikey tag name is 'apikey';
+//  basic_auth tag name is 'basic_auth';
 //  LooseZeroMode is false.
 func Default() *Binding {
 	return defaultBinding
@@ -36,17 +45,273 @@ func SetErrorFactory(bindErrFactory, validatingErrFactory func(failField, msg st
 	defaultBinding.SetErrorFactory(bindErrFactory, validatingErrFactory)
 }
 
+// SetPathParamsDecoder sets the decoder of the path parameters.
+// NOTE:
+//  The decoder is used only when the pathParams argument of binding is nil;
+//  If decoder==nil, the path parameters are not decoded from the request.
+func SetPathParamsDecoder(decoder PathParamsDecoder) {
+	defaultBinding.SetPathParamsDecoder(decoder)
+}
+
+// SetMaxFormFields sets the maximum number of the form fields parsed from the body,
+// if exceeded, ErrTooManyFields is returned.
+// NOTE:
+//  The default is 10000;
+//  If n<=0, the number is not limited.
+func SetMaxFormFields(n int) {
+	defaultBinding.SetMaxFormFields(n)
+}
+
+// SetMaxHeaderSize sets the maximum total byte length of the header values,
+// which is checked before binding the header and cookie parameters,
+// if exceeded, ErrHeaderTooLarge is returned.
+// NOTE:
+//  The default is 1 MB, the same as http.DefaultMaxHeaderBytes;
+//  If n<=0, the size is not limited.
+func SetMaxHeaderSize(n int64) {
+	defaultBinding.SetMaxHeaderSize(n)
+}
+
+// SetStringSanitizer sets the sanitizer which is called for every bound string field,
+// such as HTML escaping or SQL injection detection.
+// NOTE:
+//  @field is the field selector, such as 'A.B', and @value is the bound value;
+//  The returned string replaces the value, and the returned error rejects the binding;
+//  It is called before the value is assigned to the field, and before the validation;
+//  It is called for the string fields nested in the struct decoded from the JSON or protobuf body;
+//  If fn==nil, the values are not sanitized.
+func SetStringSanitizer(fn func(field, value string) (string, error)) {
+	defaultBinding.SetStringSanitizer(fn)
+}
+
+// SetKeyTransform sets the function which normalizes the incoming query parameter keys before lookup,
+// such as SnakeCase, CamelCase or KebabCase, nil to disable it.
+// NOTE:
+//  The 'key_transform' tag of the field overrides it, such as `query:"user_id" key_transform:"snake"`.
+func SetKeyTransform(fn func(key string) string) {
+	defaultBinding.SetKeyTransform(fn)
+}
+
+// SetTrustedProxyHeaders sets the headers of the original protocol set by the trusted TLS-terminating proxy,
+// the request is secure for BindSecure if one of them is 'https'.
+// NOTE:
+//  The default is X-Forwarded-Proto;
+//  If no headers, only the request with TLS is secure.
+func SetTrustedProxyHeaders(headers ...string) {
+	defaultBinding.SetTrustedProxyHeaders(headers...)
+}
+
+// SetCookieSigningKey sets the HMAC-SHA256 key which verifies the cookies of the fields tagged `cookie_signed:"true"`.
+// NOTE:
+//  The signed cookie value is 'value|signature', see SignCookie;
+//  If the key is empty, all the signed cookies are invalid.
+func SetCookieSigningKey(key []byte) {
+	defaultBinding.SetCookieSigningKey(key)
+}
+
+// SetCookieSignatureSeparator sets the separator of the value and the signature of the signed cookie.
+// NOTE:
+//  The default is '|'.
+func SetCookieSignatureSeparator(sep string) {
+	defaultBinding.SetCookieSignatureSeparator(sep)
+}
+
+// SignCookie returns the signed value 'value|signature' of the cookie named @name verified by the default binding.
+func SignCookie(name, value string) string {
+	return defaultBinding.SignCookie(name, value)
+}
+
+// SetCookieEncryptionKey sets the AES key which decrypts the cookies of the fields tagged `cookie_encrypted:"true"`.
+// NOTE:
+//  return the error if the key is not 16, 24 or 32 bytes.
+func SetCookieEncryptionKey(key []byte) error {
+	return defaultBinding.SetCookieEncryptionKey(key)
+}
+
+// SetCookieEncryptionKeys sets the AES keys which decrypt the cookies of the fields tagged `cookie_encrypted:"true"`,
+// the decryption tries each key in order, and EncryptCookie uses the first one, e.g. for the key rotation.
+// NOTE:
+//  return the error and keep the previous keys if a key is not 16, 24 or 32 bytes.
+func SetCookieEncryptionKeys(keys ...[]byte) error {
+	return defaultBinding.SetCookieEncryptionKeys(keys...)
+}
+
+// EncryptCookie returns the encrypted value of the cookie named @name decrypted by the default binding.
+func EncryptCookie(name, plaintext string) (string, error) {
+	return defaultBinding.EncryptCookie(name, plaintext)
+}
+
+// SetJWTKeyFunc sets the function which returns the key verifying the signature of the bearer token,
+// whose claims are bound to the fields tagged `jwt:"claim"`, such as `jwt:"sub"`.
+// NOTE:
+//  If the token is invalid or expired, return *ErrJWTInvalid;
+//  The numeric date claims, such as 'exp' and 'iat', are bound to the time.Time fields.
+func SetJWTKeyFunc(fn jwtpkg.Keyfunc) {
+	defaultBinding.SetJWTKeyFunc(fn)
+}
+
+// SetAPIKeyValidator sets the function which validates the API key and returns the user ID,
+// which is bound to the fields tagged `apikey:"true"`.
+// NOTE:
+//  The API key is the X-API-Key header or the api_key query parameter, see SetAPIKeySources;
+//  The error returned by fn is returned as is.
+func SetAPIKeyValidator(fn func(key string) (userID string, err error)) {
+	defaultBinding.SetAPIKeyValidator(fn)
+}
+
+// SetAPIKeySources sets the sources of the API key in the order of precedence,
+// SourceHeader is the X-API-Key header and SourceQuery is the api_key query parameter.
+// NOTE:
+//  The default is SourceHeader, SourceQuery.
+func SetAPIKeySources(sources ...Source) {
+	defaultBinding.SetAPIKeySources(sources...)
+}
+
+// SetRateLimiter sets the hook which enforces the rate limits of the 'rate_limit' tags,
+// such as `rate_limit:"100/min"`, the binding does not limit the requests itself.
+// NOTE:
+//  The hook is called after the tagged field is bound, with the field selector and the bound value as the key;
+//  The error returned by fn aborts the binding and is returned as is.
+func SetRateLimiter(fn func(field, key string, limit RateLimit) error) {
+	defaultBinding.SetRateLimiter(fn)
+}
+
+// SetCollectAll sets whether BindAndValidate reports all the binding and validation errors,
+// instead of the first one.
+// NOTE:
+//  The default is false;
+//  Each field reports at most one error: the field which failed to be bound is not validated;
+//  The errors are combined as validator.Errors in the order of the field declarations.
+func SetCollectAll(enable bool) {
+	defaultBinding.SetCollectAll(enable)
+}
+
 // BindAndValidate binds the request parameters and validates them if needed.
 func BindAndValidate(structPointer interface{}, req *http.Request, pathParams PathParams) error {
 	return defaultBinding.BindAndValidate(structPointer, req, pathParams)
 }
 
+// BindAndValidateContext binds the request parameters and validates them with the context if needed.
+// NOTE:
+//  If ctx==nil, req.Context() is used.
+func BindAndValidateContext(ctx context.Context, structPointer interface{}, req *http.Request, pathParams PathParams) error {
+	return defaultBinding.BindAndValidateContext(ctx, structPointer, req, pathParams)
+}
+
+// BindValidateOnly binds the request parameters to a new zero value of the struct type and validates it,
+// without modifying the struct, e.g. for the preflight checks.
+// NOTE:
+//  The request body is restored after reading, so the request can still be bound later;
+//  The new value is discarded, only the error is returned.
+func BindValidateOnly(structPointer interface{}, req *http.Request, pathParams PathParams) error {
+	return defaultBinding.BindValidateOnly(structPointer, req, pathParams)
+}
+
+// BindSecure binds the request parameters like Bind,
+// but returns ErrInsecureRequest without binding if the request is not over TLS.
+// NOTE:
+//  The request is secure if req.TLS!=nil, or one of the trusted proxy headers is 'https',
+//  see SetTrustedProxyHeaders.
+func BindSecure(req *http.Request, structPointer interface{}) error {
+	return defaultBinding.BindSecure(req, structPointer)
+}
+
 // Bind binds the request parameters.
 func Bind(structPointer interface{}, req *http.Request, pathParams PathParams) error {
 	return defaultBinding.Bind(structPointer, req, pathParams)
 }
 
+// BindFull binds the request parameters, and returns the provenance of the bound fields.
+func BindFull(structPointer interface{}, req *http.Request, pathParams PathParams) (*BindResult, error) {
+	return defaultBinding.BindFull(structPointer, req, pathParams)
+}
+
+// BindPartial binds only the request parameters from the specified sources.
+// NOTE:
+//  The fields which do not accept the sources are not processed, even if they are required.
+func BindPartial(req *http.Request, structPointer interface{}, sources ...Source) error {
+	return defaultBinding.BindPartial(req, structPointer, sources...)
+}
+
+// BindMultiStruct binds the request parameters to multiple structs and validates them if needed.
+// NOTE:
+//  The request is parsed only once, and the path parameters are decoded by the PathParamsDecoder;
+//  If any struct fails validation, the errors of all the structs are combined.
+func BindMultiStruct(req *http.Request, targets ...interface{}) error {
+	return defaultBinding.BindMultiStruct(req, targets...)
+}
+
+// BindMerge binds the request parameters to the targets and validates them like BindMultiStruct,
+// then merges the bound fields of the other targets into the first one by the field names.
+// NOTE:
+//  The bound field is copied to the field of the first target with the same name if its type is assignable;
+//  If any target fails, the errors are returned and the targets are not merged.
+func BindMerge(req *http.Request, targets ...interface{}) error {
+	return defaultBinding.BindMerge(req, targets...)
+}
+
+// BindGraphQL binds the GraphQL operation variables to the struct.
+// NOTE:
+//  The variable name is specified by the 'gql' tag, the 'json' tag or the field name in turn;
+//  The GraphQL scalar types (Int, Float, Boolean, String, ID) are coerced to the field type,
+//  and the numeric string, such as the ID "42", is bound to the number field.
+func BindGraphQL(variables map[string]interface{}, structPointer interface{}) error {
+	return defaultBinding.BindGraphQL(variables, structPointer)
+}
+
+// BindGRPCRequest binds the fields of the protobuf message to the struct, and validates it.
+// NOTE:
+//  The message field is mapped by the field number of the 'protobuf' tag, such as `protobuf:"varint,1,opt"`,
+//  or by the name of the 'proto_name' tag, such as `proto_name:"user_id"`;
+//  The untagged fields and the oneof fields of the message are not bound;
+//  The absent message fields keep the zero value, and the numbers are converted to the field type.
+func BindGRPCRequest(req proto.Message, structPointer interface{}) error {
+	return defaultBinding.BindGRPCRequest(req, structPointer)
+}
+
+// BindWSMessage binds the WebSocket message to the struct.
+// NOTE:
+//  @contentType is the content type of the message agreed at handshake time;
+//  The supported content types are the same as the request body:
+//  application/json, application/x-protobuf, application/x-www-form-urlencoded.
+func BindWSMessage(msg []byte, contentType string, structPointer interface{}) error {
+	return defaultBinding.BindWSMessage(msg, contentType, structPointer)
+}
+
+// BindSSE binds the Server-Sent Event to the struct.
+// NOTE:
+//  The fields are tagged by `sse:"event"`, `sse:"data"`, `sse:"id"` or `sse:"retry"`;
+//  The data is parsed as JSON if it looks like JSON and the field is not a string.
+func BindSSE(event *SSEEvent, structPointer interface{}) error {
+	return defaultBinding.BindSSE(event, structPointer)
+}
+
+// BindKafkaMessage binds the Kafka message to the struct.
+// NOTE:
+//  The headers are bound to the fields tagged `kafka_header:"$name"`;
+//  The key is bound to the field tagged `kafka_key:"true"`;
+//  The value is bound to the body fields by the 'content-type' header, the default is application/json;
+//  If msg==nil, ErrNilMessage is returned.
+func BindKafkaMessage(msg KafkaMessage, structPointer interface{}) error {
+	return defaultBinding.BindKafkaMessage(msg, structPointer)
+}
+
+// BindAMQP binds the AMQP message to the struct.
+// NOTE:
+//  The headers are bound to the fields tagged `amqp:"$name"`;
+//  The routing key is bound to the field tagged `amqp_routing_key:"true"`;
+//  The body is bound to the body fields by the ContentType property, the default is application/json;
+//  If msg==nil, ErrNilMessage is returned.
+func BindAMQP(msg *AMQPMessage, structPointer interface{}) error {
+	return defaultBinding.BindAMQP(msg, structPointer)
+}
+
 // Validate validates whether the fields of value is valid.
 func Validate(value interface{}) error {
 	return defaultBinding.Validate(value)
 }
+
+// ValidateContext validates whether the fields of value is valid with the context.
+func ValidateContext(ctx context.Context, value interface{}) error {
+	return defaultBinding.ValidateContext(ctx, value)
+}
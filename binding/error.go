@@ -1,8 +1,12 @@
 package binding
 
+import "reflect"
+
 // Error validate error
 type Error struct {
 	ErrType, FailField, Msg string
+	// Code the machine-readable error code of the validation
+	Code string
 }
 
 // Error implements error interface.
@@ -22,3 +26,29 @@ func newDefaultErrorFactory(errType string) func(string, string) error {
 		}
 	}
 }
+
+func newDefaultCodeErrorFactory(errType string) func(string, string, string) error {
+	return func(failField, msg, code string) error {
+		return &Error{
+			ErrType:   errType,
+			FailField: failField,
+			Msg:       msg,
+			Code:      code,
+		}
+	}
+}
+
+// ErrCyclicStruct the error returned when preparing the struct which references itself,
+// directly or through the other structs, such as `type Node struct { Next *Node }`,
+// and the repeated type has the fields bound from the request parameters, such as the query.
+type ErrCyclicStruct struct {
+	// Type the repeated struct type
+	Type reflect.Type
+	// Field the selector of the field referencing the repeated type, such as 'Next'
+	Field string
+}
+
+// Error implements error interface.
+func (e *ErrCyclicStruct) Error() string {
+	return "binding: cyclic struct " + e.Type.String() + " referenced by the field " + e.Field
+}
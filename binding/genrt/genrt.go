@@ -0,0 +1,179 @@
+// Package genrt is the runtime of the binding code generated by the tagexpr-gen command,
+// which binds the request parameters without reflection.
+package genrt
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/bytedance/go-tagexpr/binding"
+	"github.com/tidwall/gjson"
+)
+
+const defaultMaxMemory = 32 << 20 // 32 MB
+
+var errJSONType = errors.New("json type mismatch")
+
+// Request the request parameters accessor of the generated code.
+type Request struct {
+	req         *http.Request
+	contentType string
+	query       url.Values
+	formParsed  bool
+	body        []byte
+}
+
+// NewRequest creates the parameters accessor of the request.
+func NewRequest(req *http.Request) *Request {
+	contentType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	return &Request{
+		req:         req,
+		contentType: contentType,
+	}
+}
+
+// ReadBody reads the body, and restores it for the subsequent readers.
+func (r *Request) ReadBody() error {
+	if r.req.Body == nil || r.body != nil {
+		return nil
+	}
+	b, err := ioutil.ReadAll(r.req.Body)
+	r.req.Body.Close()
+	if err != nil {
+		return err
+	}
+	if b == nil {
+		b = []byte{}
+	}
+	r.body = b
+	r.req.Body = ioutil.NopCloser(bytes.NewReader(b))
+	return nil
+}
+
+// Query returns the values of the URL query parameter.
+func (r *Request) Query(name string) ([]string, bool) {
+	if r.query == nil {
+		r.query = r.req.URL.Query()
+	}
+	a, ok := r.query[name]
+	return a, ok
+}
+
+// Form returns the values of the form body parameter.
+// NOTE:
+//  The content type should be application/x-www-form-urlencoded or multipart/form-data.
+func (r *Request) Form(name string) ([]string, bool) {
+	switch r.contentType {
+	case "application/x-www-form-urlencoded", "multipart/form-data":
+	default:
+		return nil, false
+	}
+	if !r.formParsed {
+		r.formParsed = true
+		if r.req.PostForm == nil {
+			r.req.ParseMultipartForm(defaultMaxMemory)
+		}
+	}
+	a, ok := r.req.PostForm[name]
+	return a, ok
+}
+
+// Header returns the values of the header, the name should be canonical.
+func (r *Request) Header(name string) ([]string, bool) {
+	a, ok := r.req.Header[name]
+	return a, ok
+}
+
+// Cookie returns the values of the cookie.
+func (r *Request) Cookie(name string) ([]string, bool) {
+	var a []string
+	for _, c := range r.req.Cookies() {
+		if c.Name == name {
+			a = append(a, c.Value)
+		}
+	}
+	return a, len(a) > 0
+}
+
+// JSON returns the value of the JSON body at the path.
+// NOTE:
+//  The body should be read by ReadBody, and the content type should be application/json;
+//  The null value is the same as absent.
+func (r *Request) JSON(path string) (gjson.Result, bool) {
+	if r.contentType != "application/json" || len(r.body) == 0 {
+		return gjson.Result{}, false
+	}
+	res := gjson.GetBytes(r.body, path)
+	return res, res.Exists() && res.Type != gjson.Null
+}
+
+// RawBody returns the body read by ReadBody.
+func (r *Request) RawBody() []byte {
+	return r.body
+}
+
+// JSONString returns the string of the JSON value.
+func JSONString(res gjson.Result) (string, error) {
+	if res.Type != gjson.String {
+		return "", errJSONType
+	}
+	return res.Str, nil
+}
+
+// JSONBool returns the bool of the JSON value.
+func JSONBool(res gjson.Result) (bool, error) {
+	switch res.Type {
+	case gjson.True:
+		return true, nil
+	case gjson.False:
+		return false, nil
+	}
+	return false, errJSONType
+}
+
+// JSONInt returns the integer of the JSON value, whose size is @bitSize.
+func JSONInt(res gjson.Result, bitSize int) (int64, error) {
+	if res.Type != gjson.Number {
+		return 0, errJSONType
+	}
+	return strconv.ParseInt(res.Raw, 10, bitSize)
+}
+
+// JSONUint returns the unsigned integer of the JSON value, whose size is @bitSize.
+func JSONUint(res gjson.Result, bitSize int) (uint64, error) {
+	if res.Type != gjson.Number {
+		return 0, errJSONType
+	}
+	return strconv.ParseUint(res.Raw, 10, bitSize)
+}
+
+// JSONFloat returns the float of the JSON value, whose size is @bitSize.
+func JSONFloat(res gjson.Result, bitSize int) (float64, error) {
+	if res.Type != gjson.Number {
+		return 0, errJSONType
+	}
+	return strconv.ParseFloat(res.Raw, bitSize)
+}
+
+// JSONArray returns the elements of the JSON array.
+func JSONArray(res gjson.Result) ([]gjson.Result, error) {
+	if !res.IsArray() {
+		return nil, errJSONType
+	}
+	return res.Array(), nil
+}
+
+// MissingError returns the binding error of the missing required parameter.
+func MissingError(failField string) error {
+	return &binding.Error{ErrType: "binding", FailField: failField, Msg: "missing required parameter"}
+}
+
+// TypeError returns the binding error of the mismatched parameter type.
+func TypeError(failField string) error {
+	return &binding.Error{ErrType: "binding", FailField: failField, Msg: "parameter type does not match binding data"}
+}
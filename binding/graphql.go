@@ -0,0 +1,240 @@
+package binding
+
+import (
+	jsonpkg "encoding/json"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/henrylee2cn/goutil"
+)
+
+const tagGraphQL = "gql"
+
+// BindGraphQL binds the GraphQL operation variables to the struct.
+// NOTE:
+//  The variable name is specified by the 'gql' tag, the 'json' tag or the field name in turn;
+//  The GraphQL scalar types (Int, Float, Boolean, String, ID) are coerced to the field type,
+//  and the numeric string, such as the ID "42", is bound to the number field.
+func (b *Binding) BindGraphQL(variables map[string]interface{}, structPointer interface{}) error {
+	value, err := b.structValueOf(structPointer)
+	if err != nil {
+		return err
+	}
+	return b.bindGraphQLObject("", variables, value)
+}
+
+func (b *Binding) bindGraphQLObject(pathPrefix string, obj map[string]interface{}, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, tagged := graphQLFieldName(field)
+		if name == "-" {
+			continue
+		}
+		fv := v.Field(i)
+		if field.Anonymous && !tagged {
+			if ft := goutil.DereferenceType(field.Type); ft.Kind() == reflect.Struct {
+				if fv.Kind() == reflect.Ptr && fv.IsNil() {
+					if !fv.CanSet() {
+						continue
+					}
+					fv.Set(reflect.New(ft))
+				}
+				err := b.bindGraphQLObject(pathPrefix, obj, goutil.DereferenceValue(fv))
+				if err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		val, ok := obj[name]
+		if !ok {
+			continue
+		}
+		path := name
+		if pathPrefix != "" {
+			path = pathPrefix + "." + name
+		}
+		if err := b.assignGraphQLValue(path, val, fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func graphQLFieldName(field reflect.StructField) (string, bool) {
+	for _, tagName := range [...]string{tagGraphQL, tagJSON} {
+		if tag, ok := field.Tag.Lookup(tagName); ok {
+			if name := strings.TrimSpace(strings.Split(tag, ",")[0]); name != "" {
+				return name, true
+			}
+		}
+	}
+	return field.Name, false
+}
+
+func (b *Binding) assignGraphQLValue(path string, val interface{}, v reflect.Value) error {
+	if val == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		elem := reflect.New(v.Type().Elem())
+		if err := b.assignGraphQLValue(path, val, elem.Elem()); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+	if fn := typeUnmarshalFuncs[v.Type()]; fn != nil {
+		s, ok := val.(string)
+		if !ok {
+			return b.graphQLTypeError(path)
+		}
+		vv, err := fn(s, false)
+		if err != nil {
+			return b.graphQLTypeError(path)
+		}
+		v.Set(vv)
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		// String or ID
+		switch s := val.(type) {
+		case string:
+			v.SetString(s)
+			return nil
+		case jsonpkg.Number:
+			v.SetString(s.String())
+			return nil
+		}
+		if i, ok := graphQLInt(val); ok {
+			v.SetString(strconv.FormatInt(i, 10))
+			return nil
+		}
+	case reflect.Bool:
+		if bol, ok := val.(bool); ok {
+			v.SetBool(bol)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, ok := graphQLInt(val); ok && !v.OverflowInt(i) {
+			v.SetInt(i)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if i, ok := graphQLInt(val); ok && i >= 0 && !v.OverflowUint(uint64(i)) {
+			v.SetUint(uint64(i))
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, ok := graphQLFloat(val); ok && !v.OverflowFloat(f) {
+			v.SetFloat(f)
+			return nil
+		}
+	case reflect.Slice:
+		a, ok := val.([]interface{})
+		if !ok {
+			return b.graphQLTypeError(path)
+		}
+		slice := reflect.MakeSlice(v.Type(), len(a), len(a))
+		for i, elem := range a {
+			if err := b.assignGraphQLValue(path, elem, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	case reflect.Map:
+		m, ok := val.(map[string]interface{})
+		if !ok || v.Type().Key().Kind() != reflect.String {
+			return b.graphQLTypeError(path)
+		}
+		mv := reflect.MakeMapWithSize(v.Type(), len(m))
+		for k, elem := range m {
+			ev := reflect.New(v.Type().Elem()).Elem()
+			if err := b.assignGraphQLValue(path+"."+k, elem, ev); err != nil {
+				return err
+			}
+			mv.SetMapIndex(reflect.ValueOf(k).Convert(v.Type().Key()), ev)
+		}
+		v.Set(mv)
+		return nil
+	case reflect.Struct:
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			return b.graphQLTypeError(path)
+		}
+		return b.bindGraphQLObject(path, m, v)
+	case reflect.Interface:
+		vv := reflect.ValueOf(val)
+		if vv.Type().AssignableTo(v.Type()) {
+			v.Set(vv)
+			return nil
+		}
+	}
+	return b.graphQLTypeError(path)
+}
+
+func (b *Binding) graphQLTypeError(path string) error {
+	return b.bindErrFactory(path, "parameter type does not match binding data")
+}
+
+// graphQLInt returns the integer value of the GraphQL Int or ID,
+// which is serialized as the string, such as "42".
+func graphQLInt(val interface{}) (int64, bool) {
+	switch i := val.(type) {
+	case string:
+		r, err := strconv.ParseInt(i, 10, 64)
+		return r, err == nil
+	case int:
+		return int64(i), true
+	case int8:
+		return int64(i), true
+	case int16:
+		return int64(i), true
+	case int32:
+		return int64(i), true
+	case int64:
+		return i, true
+	case uint:
+		return int64(i), uint64(i) <= math.MaxInt64
+	case uint8:
+		return int64(i), true
+	case uint16:
+		return int64(i), true
+	case uint32:
+		return int64(i), true
+	case uint64:
+		return int64(i), i <= math.MaxInt64
+	case jsonpkg.Number:
+		r, err := i.Int64()
+		return r, err == nil
+	case float32:
+		return int64(i), float32(int64(i)) == i
+	case float64:
+		return int64(i), float64(int64(i)) == i
+	}
+	return 0, false
+}
+
+// graphQLFloat returns the float value of the GraphQL Float or Int.
+func graphQLFloat(val interface{}) (float64, bool) {
+	switch f := val.(type) {
+	case float64:
+		return f, true
+	case float32:
+		return float64(f), true
+	case jsonpkg.Number:
+		r, err := f.Float64()
+		return r, err == nil
+	}
+	i, ok := graphQLInt(val)
+	return float64(i), ok
+}
@@ -0,0 +1,231 @@
+package binding
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/henrylee2cn/goutil"
+)
+
+const tagProtoName = "proto_name"
+
+// BindGRPCRequest binds the fields of the protobuf message to the struct, and validates it.
+// NOTE:
+//  The message field is mapped by the field number of the 'protobuf' tag, such as `protobuf:"varint,1,opt"`,
+//  or by the name of the 'proto_name' tag, such as `proto_name:"user_id"`;
+//  The untagged fields and the oneof fields of the message are not bound;
+//  The absent message fields keep the zero value, and the numbers are converted to the field type.
+func (b *Binding) BindGRPCRequest(req proto.Message, structPointer interface{}) error {
+	value, err := b.structValueOf(structPointer)
+	if err != nil {
+		return err
+	}
+	src := reflect.ValueOf(req)
+	for src.Kind() == reflect.Ptr || src.Kind() == reflect.Interface {
+		if src.IsNil() {
+			return b.bindErrFactory("", "req must be a non-nil protobuf message")
+		}
+		src = src.Elem()
+	}
+	if src.Kind() != reflect.Struct {
+		return b.bindErrFactory("", "req must be a non-nil protobuf message")
+	}
+	if err = b.bindProtoStruct("", src, value); err != nil {
+		return err
+	}
+	return b.validate(value)
+}
+
+// protoField the field of the protobuf message.
+type protoField struct {
+	index  int
+	number int
+	name   string
+}
+
+// protoFieldsOf returns the fields of the protobuf message struct,
+// which are indexed by the field number and the name.
+func protoFieldsOf(t reflect.Type) (byNumber map[int]protoField, byName map[string]protoField) {
+	byNumber = make(map[int]protoField, t.NumField())
+	byName = make(map[string]protoField, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f, ok := parseProtoTag(t.Field(i).Tag.Get(tagProtobuf))
+		if !ok {
+			continue
+		}
+		f.index = i
+		byNumber[f.number] = f
+		if f.name != "" {
+			byName[f.name] = f
+		}
+	}
+	return
+}
+
+// parseProtoTag parses the 'protobuf' tag, such as `protobuf:"varint,1,opt,name=user_id,json=userId,proto3"`.
+func parseProtoTag(tag string) (protoField, bool) {
+	var f protoField
+	a := strings.Split(tag, ",")
+	if len(a) < 2 {
+		return f, false
+	}
+	number, err := strconv.Atoi(strings.TrimSpace(a[1]))
+	if err != nil || number <= 0 {
+		return f, false
+	}
+	f.number = number
+	for _, s := range a[2:] {
+		if strings.HasPrefix(s, "name=") {
+			f.name = s[len("name="):]
+		}
+	}
+	return f, true
+}
+
+func (b *Binding) bindProtoStruct(pathPrefix string, src, dst reflect.Value) error {
+	byNumber, byName := protoFieldsOf(src.Type())
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fv := dst.Field(i)
+		var (
+			pf protoField
+			ok bool
+		)
+		if name, tagged := field.Tag.Lookup(tagProtoName); tagged {
+			pf, ok = byName[strings.TrimSpace(name)]
+		} else if tag, tagged := field.Tag.Lookup(tagProtobuf); tagged {
+			if pf, ok = parseProtoTag(tag); ok {
+				pf, ok = byNumber[pf.number]
+			}
+		} else if field.Anonymous {
+			if ft := goutil.DereferenceType(field.Type); ft.Kind() == reflect.Struct {
+				if fv.Kind() == reflect.Ptr && fv.IsNil() {
+					if !fv.CanSet() {
+						continue
+					}
+					fv.Set(reflect.New(ft))
+				}
+				err := b.bindProtoStruct(pathPrefix, src, goutil.DereferenceValue(fv))
+				if err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if !ok || field.PkgPath != "" {
+			continue
+		}
+		path := pf.name
+		if path == "" {
+			path = field.Name
+		}
+		if pathPrefix != "" {
+			path = pathPrefix + "." + path
+		}
+		if err := b.assignProtoValue(path, src.Field(pf.index), fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *Binding) assignProtoValue(path string, src, dst reflect.Value) error {
+	for src.Kind() == reflect.Ptr || src.Kind() == reflect.Interface {
+		if src.IsNil() {
+			return nil
+		}
+		src = src.Elem()
+	}
+	if dst.Kind() == reflect.Ptr {
+		elem := reflect.New(dst.Type().Elem())
+		if err := b.assignProtoValue(path, src, elem.Elem()); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	}
+	if src.Type().AssignableTo(dst.Type()) {
+		dst.Set(src)
+		return nil
+	}
+	switch dk, sk := dst.Kind(), src.Kind(); {
+	case isIntKind(dk) && (isIntKind(sk) || isUintKind(sk)):
+		i, ok := protoInt(src)
+		if ok && !dst.OverflowInt(i) {
+			dst.SetInt(i)
+			return nil
+		}
+	case isUintKind(dk) && (isIntKind(sk) || isUintKind(sk)):
+		u, ok := protoUint(src)
+		if ok && !dst.OverflowUint(u) {
+			dst.SetUint(u)
+			return nil
+		}
+	case dk == sk && dk != reflect.Struct && dk != reflect.Slice && dk != reflect.Map:
+		// the named types of string, bool and floats
+		dst.Set(src.Convert(dst.Type()))
+		return nil
+	case dk == reflect.Struct && sk == reflect.Struct:
+		return b.bindProtoStruct(path, src, dst)
+	case dk == reflect.Slice && sk == reflect.Slice:
+		if src.IsNil() {
+			return nil
+		}
+		a := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			err := b.assignProtoValue(path+"["+strconv.Itoa(i)+"]", src.Index(i), a.Index(i))
+			if err != nil {
+				return err
+			}
+		}
+		dst.Set(a)
+		return nil
+	case dk == reflect.Map && sk == reflect.Map:
+		if src.IsNil() {
+			return nil
+		}
+		m := reflect.MakeMapWithSize(dst.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			k := reflect.New(dst.Type().Key()).Elem()
+			if err := b.assignProtoValue(path, iter.Key(), k); err != nil {
+				return err
+			}
+			v := reflect.New(dst.Type().Elem()).Elem()
+			if err := b.assignProtoValue(path, iter.Value(), v); err != nil {
+				return err
+			}
+			m.SetMapIndex(k, v)
+		}
+		dst.Set(m)
+		return nil
+	}
+	return b.bindErrFactory(path, "parameter type does not match binding data")
+}
+
+func isIntKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUintKind(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
+
+func protoInt(v reflect.Value) (int64, bool) {
+	if isIntKind(v.Kind()) {
+		return v.Int(), true
+	}
+	u := v.Uint()
+	return int64(u), u <= 1<<63-1
+}
+
+func protoUint(v reflect.Value) (uint64, bool) {
+	if isUintKind(v.Kind()) {
+		return v.Uint(), true
+	}
+	i := v.Int()
+	return uint64(i), i >= 0
+}
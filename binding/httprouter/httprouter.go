@@ -0,0 +1,41 @@
+// Package httprouter binds the path parameters of github.com/julienschmidt/httprouter.
+package httprouter
+
+import (
+	"net/http"
+
+	"github.com/bytedance/go-tagexpr/binding"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Install makes the binding read path parameters from the httprouter.Params in the request context.
+// NOTE:
+//  If b==nil, the default binding is installed;
+//  The handler should be registered by httprouter.Router.Handler or HandlerFunc,
+//  so that the params are stored in the request context;
+//  The pathParams argument of binding should be nil.
+func Install(b *binding.Binding) {
+	if b == nil {
+		b = binding.Default()
+	}
+	b.SetPathParamsDecoder(NewPathParams)
+}
+
+// NewPathParams returns the httprouter path parameters stored in the request context.
+func NewPathParams(req *http.Request) binding.PathParams {
+	return PathParams(httprouter.ParamsFromContext(req.Context()))
+}
+
+// PathParams is the binding.PathParams implementation of httprouter.Params,
+// which can also be passed to the binding directly in the httprouter.Handle.
+type PathParams httprouter.Params
+
+// Get returns the value of the first parameter which key matches the given name.
+func (ps PathParams) Get(name string) (string, bool) {
+	for _, p := range ps {
+		if p.Key == name {
+			return p.Value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,42 @@
+package httprouter_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bytedance/go-tagexpr/binding"
+	bindhttprouter "github.com/bytedance/go-tagexpr/binding/httprouter"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstall(t *testing.T) {
+	type Recv struct {
+		ID   int    `path:"id"`
+		Name string `path:"name,required"`
+	}
+	b := binding.New(nil)
+	bindhttprouter.Install(b)
+
+	req := httptest.NewRequest("GET", "/users/7/henry", nil)
+	req = req.WithContext(context.WithValue(req.Context(), httprouter.ParamsKey, httprouter.Params{
+		{Key: "id", Value: "7"},
+		{Key: "name", Value: "henry"},
+	}))
+	var recv Recv
+	assert.NoError(t, b.Bind(&recv, req, nil))
+	assert.Equal(t, 7, recv.ID)
+	assert.Equal(t, "henry", recv.Name)
+
+	req = httptest.NewRequest("GET", "/users/8", nil)
+	req = req.WithContext(context.WithValue(req.Context(), httprouter.ParamsKey, httprouter.Params{
+		{Key: "id", Value: "8"},
+	}))
+	assert.EqualError(t, b.Bind(&recv, req, nil), "binding Name: missing required parameter")
+
+	recv = Recv{}
+	params := bindhttprouter.PathParams{{Key: "name", Value: "alice"}}
+	assert.NoError(t, b.Bind(&recv, httptest.NewRequest("GET", "/", nil), params))
+	assert.Equal(t, "alice", recv.Name)
+}
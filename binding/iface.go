@@ -0,0 +1,79 @@
+package binding
+
+import (
+	"reflect"
+
+	"github.com/bytedance/go-tagexpr"
+)
+
+// isEmbeddedIface returns whether the field is the embedded interface,
+// such as `type Handler struct { Service }`.
+func isEmbeddedIface(field reflect.StructField) bool {
+	return field.Anonymous && field.Type.Kind() == reflect.Interface
+}
+
+// bindEmbeddedIfaces binds the fields of the concrete structs of the embedded interfaces,
+// such as the *serviceImpl with the tagged fields in `type Handler struct { Service }`.
+// NOTE:
+//  The nil interface is skipped, and so is the concrete type other than the struct pointer,
+//  whose fields cannot be set;
+//  The selectors of the bound and failed fields are prefixed with the interface field, such as 'Service.Name'.
+func (b *Binding) bindEmbeddedIfaces(recv *receiver, value reflect.Value, expr *tagexpr.TagExpr, rc *requestCache, pathParams PathParams) (hasVd bool, err error) {
+	rc.ifaceBinding = append(rc.ifaceBinding, value.Addr().Pointer())
+	defer func() { rc.ifaceBinding = rc.ifaceBinding[:len(rc.ifaceBinding)-1] }()
+	for _, selector := range recv.embeddedIfaces {
+		fh, ok := expr.Field(selector)
+		if !ok {
+			continue
+		}
+		v := fh.Value(false)
+		if !v.IsValid() || v.IsNil() {
+			continue
+		}
+		v = v.Elem()
+		if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct || rc.isIfaceBinding(v.Pointer()) {
+			continue
+		}
+		var boundLen, failedLen int
+		if rc.bound != nil {
+			boundLen = len(*rc.bound)
+		}
+		if rc.failed != nil {
+			failedLen = len(*rc.failed)
+		}
+		_, vd, err := b.bindRequest(v, rc, pathParams)
+		hasVd = hasVd || vd
+		if rc.bound != nil {
+			for i := boundLen; i < len(*rc.bound); i++ {
+				(*rc.bound)[i].Selector = joinSelector(selector, (*rc.bound)[i].Selector)
+			}
+		}
+		if rc.failed != nil {
+			for i := failedLen; i < len(*rc.failed); i++ {
+				(*rc.failed)[i].selector = joinSelector(selector, (*rc.failed)[i].selector)
+			}
+		}
+		if err != nil {
+			return hasVd, err
+		}
+	}
+	return hasVd, nil
+}
+
+// isIfaceBinding returns whether the struct pointer is being bound, which prevents the cycle of the interfaces.
+func (rc *requestCache) isIfaceBinding(ptr uintptr) bool {
+	for _, p := range rc.ifaceBinding {
+		if p == ptr {
+			return true
+		}
+	}
+	return false
+}
+
+// joinSelector returns the selector of the field nested in the parent field, such as 'Service.Name'.
+func joinSelector(parent, selector string) string {
+	if selector == "" {
+		return parent
+	}
+	return parent + "." + selector
+}
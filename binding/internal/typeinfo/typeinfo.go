@@ -0,0 +1,53 @@
+// Package typeinfo provides the type helpers shared by the generators of the struct descriptions,
+// such as jsonschema, tsgen and protodesc.
+package typeinfo
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	TimeType          = reflect.TypeOf(time.Time{})
+	RawMessageType    = reflect.TypeOf(json.RawMessage{})
+	JSONMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	TextMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// ParseJSONTag returns the name and the options of the 'json' tag.
+func ParseJSONTag(tag string) (string, map[string]bool) {
+	a := strings.Split(tag, ",")
+	opts := make(map[string]bool, len(a)-1)
+	for _, s := range a[1:] {
+		opts[strings.TrimSpace(s)] = true
+	}
+	return strings.TrimSpace(a[0]), opts
+}
+
+// Name returns the name of the named type,
+// e.g. 'Page_User' for the instantiated generic type 'Page[pkg.User]'.
+func Name(t reflect.Type) string {
+	name := t.Name()
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		args := strings.Split(name[i+1:len(name)-1], ",")
+		name = name[:i]
+		for _, arg := range args {
+			if j := strings.LastIndexAny(arg, "./"); j >= 0 {
+				arg = arg[j+1:]
+			}
+			name += "_" + arg
+		}
+	}
+	return name
+}
+
+// Deref returns the type which the pointer type points to, recursively.
+func Deref(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
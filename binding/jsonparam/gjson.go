@@ -40,8 +40,15 @@ func init() {
 }
 
 // Assign unmarshal
+// NOTE:
+//  The explicit null sets the pointer to the non-nil zero value,
+//  while the pointer of the absent key is kept nil, so that they can be distinguished;
+//  The explicit null of the other types is ignored.
 func Assign(jsval gjson.Result, goval reflect.Value) {
 	if jsval.Type == gjson.Null {
+		if jsval.Exists() && goval.Kind() == reflect.Ptr {
+			goval.Set(reflect.New(goval.Type().Elem()))
+		}
 		return
 	}
 	t := goval.Type()
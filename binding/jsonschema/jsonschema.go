@@ -0,0 +1,297 @@
+// Package jsonschema generates the JSON Schema (draft-07) document of the JSON-bound shape of the struct,
+// e.g. for AJV or the OpenAPI tooling.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bytedance/go-tagexpr/binding/internal/typeinfo"
+)
+
+const draft07 = "http://json-schema.org/draft-07/schema#"
+
+// The tags of the schema keywords, such as `description:"the user name" minlen:"1" maxlen:"32"`.
+const (
+	tagDescription = "description"
+	tagExample     = "example"
+	tagDefault     = "default"
+	tagMin         = "min"
+	tagMax         = "max"
+	tagMinLen      = "minlen"
+	tagMaxLen      = "maxlen"
+	tagRegexp      = "regexp"
+)
+
+// schema the JSON Schema document, whose fields are in the order of the output.
+type schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	AdditionalProperties *schema            `json:"additionalProperties,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Examples             []interface{}      `json:"examples,omitempty"`
+	Definitions          map[string]*schema `json:"definitions,omitempty"`
+}
+
+// GenerateSchema returns the JSON Schema (draft-07) document of the struct type.
+// NOTE:
+//  The pointer to struct type is dereferenced;
+//  The property is named by the 'json' tag, the field tagged `json:"-"` is omitted;
+//  The property is required if the 'json' tag has the 'required' or 'req' option, such as `json:"id,required"`;
+//  The 'description', 'example', 'default', 'min', 'max', 'minlen', 'maxlen' and 'regexp' tags
+//  populate the keywords of the property, 'minlen' and 'maxlen' are 'minItems' and 'maxItems' for the slice;
+//  The referenced named structs are in the 'definitions', and the anonymous structs are inlined;
+//  If the tag value is invalid, such as a non-numeric 'min', return error with the field named.
+func GenerateSchema(t reflect.Type) ([]byte, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("jsonschema: %s is not a struct type", t)
+	}
+	g := &generator{root: t, defs: make(map[string]*schema)}
+	root, err := g.structSchema(t)
+	if err != nil {
+		return nil, err
+	}
+	root.Schema = draft07
+	root.Title = t.Name()
+	if len(g.defs) > 0 {
+		root.Definitions = g.defs
+	}
+	return json.MarshalIndent(root, "", "  ")
+}
+
+type generator struct {
+	root reflect.Type
+	defs map[string]*schema
+}
+
+// structSchema returns the object schema of the struct.
+func (g *generator) structSchema(t reflect.Type) (*schema, error) {
+	s := &schema{Type: "object", Properties: make(map[string]*schema)}
+	if err := g.addFields(s, t); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// addFields adds the properties of the struct, including the promoted ones.
+func (g *generator) addFields(s *schema, t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, tagged := field.Tag.Lookup("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := typeinfo.ParseJSONTag(tag)
+		ft := field.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if field.Anonymous && !tagged && ft.Kind() == reflect.Struct {
+			if err := g.addFields(s, ft); err != nil {
+				return err
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		var p *schema
+		var err error
+		if opts["string"] {
+			p = &schema{Type: "string"}
+		} else if p, err = g.typeSchema(field.Type); err != nil {
+			return err
+		}
+		if err = setKeywords(p, field, ft); err != nil {
+			return fmt.Errorf("jsonschema: %s.%s: %s", t.Name(), field.Name, err.Error())
+		}
+		s.Properties[name] = p
+		if opts["required"] || opts["req"] {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return nil
+}
+
+// typeSchema returns the schema of the Go type.
+func (g *generator) typeSchema(t reflect.Type) (*schema, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == typeinfo.TimeType:
+		return &schema{Type: "string", Format: "date-time"}, nil
+	case t == typeinfo.RawMessageType:
+		return &schema{}, nil
+	case t.Implements(typeinfo.JSONMarshalerType) || reflect.PtrTo(t).Implements(typeinfo.JSONMarshalerType):
+		return &schema{}, nil
+	case t.Implements(typeinfo.TextMarshalerType) || reflect.PtrTo(t).Implements(typeinfo.TextMarshalerType):
+		return &schema{Type: "string"}, nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return &schema{Type: "string"}, nil
+	case reflect.Bool:
+		return &schema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &schema{Type: "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &schema{Type: "number"}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			// base64 encoded by encoding/json
+			return &schema{Type: "string", ContentEncoding: "base64"}, nil
+		}
+		items, err := g.typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &schema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		values, err := g.typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &schema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		if t == g.root {
+			return &schema{Ref: "#"}, nil
+		}
+		name := typeinfo.Name(t)
+		if _, ok := g.defs[name]; !ok {
+			// placeholder for the cyclic reference
+			g.defs[name] = nil
+			s, err := g.structSchema(t)
+			if err != nil {
+				return nil, err
+			}
+			g.defs[name] = s
+		}
+		return &schema{Ref: "#/definitions/" + name}, nil
+	}
+	// the interface and the others accept any value
+	return &schema{}, nil
+}
+
+// setKeywords sets the keywords of the property by the tags of the field.
+func setKeywords(s *schema, field reflect.StructField, t reflect.Type) error {
+	tag := field.Tag
+	if v, ok := tag.Lookup(tagDescription); ok {
+		s.Description = v
+	}
+	for _, name := range []string{tagMin, tagMax} {
+		v, ok := tag.Lookup(name)
+		if !ok {
+			continue
+		}
+		if s.Type != "integer" && s.Type != "number" {
+			return fmt.Errorf("%s tag is not supported for the type %s", name, t)
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s tag: %q", name, v)
+		}
+		if name == tagMin {
+			s.Minimum = &f
+		} else {
+			s.Maximum = &f
+		}
+	}
+	for _, name := range []string{tagMinLen, tagMaxLen} {
+		v, ok := tag.Lookup(name)
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid %s tag: %q", name, v)
+		}
+		switch {
+		case s.Type == "array" && name == tagMinLen:
+			s.MinItems = &n
+		case s.Type == "array":
+			s.MaxItems = &n
+		case name == tagMinLen:
+			s.MinLength = &n
+		default:
+			s.MaxLength = &n
+		}
+	}
+	if v, ok := tag.Lookup(tagRegexp); ok {
+		if _, err := regexp.Compile(v); err != nil {
+			return fmt.Errorf("invalid %s tag: %s", tagRegexp, err.Error())
+		}
+		s.Pattern = v
+	}
+	if v, ok := tag.Lookup(tagDefault); ok {
+		d, err := tagValue(s, v)
+		if err != nil {
+			return fmt.Errorf("invalid %s tag: %q", tagDefault, v)
+		}
+		s.Default = d
+	}
+	if v, ok := tag.Lookup(tagExample); ok {
+		e, err := tagValue(s, v)
+		if err != nil {
+			return fmt.Errorf("invalid %s tag: %q", tagExample, v)
+		}
+		s.Examples = []interface{}{e}
+	}
+	return nil
+}
+
+// tagValue returns the JSON value of the 'default' or 'example' tag by the type of the property,
+// the string property uses the tag value literally, and the others parse it as JSON.
+func tagValue(s *schema, v string) (interface{}, error) {
+	if s.Type == "string" {
+		return v, nil
+	}
+	var r interface{}
+	if err := json.Unmarshal([]byte(v), &r); err != nil {
+		return nil, err
+	}
+	switch s.Type {
+	case "integer":
+		if f, ok := r.(float64); !ok || f != float64(int64(f)) {
+			return nil, fmt.Errorf("not an integer")
+		}
+	case "number":
+		if _, ok := r.(float64); !ok {
+			return nil, fmt.Errorf("not a number")
+		}
+	case "boolean":
+		if _, ok := r.(bool); !ok {
+			return nil, fmt.Errorf("not a boolean")
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,181 @@
+package jsonschema_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bytedance/go-tagexpr/binding/jsonschema"
+	"github.com/stretchr/testify/assert"
+)
+
+type Address struct {
+	City string `json:"city,required" minlen:"1"`
+}
+
+type Node struct {
+	Value    int     `json:"value"`
+	Children []*Node `json:"children"`
+}
+
+type User struct {
+	ID      int64             `json:"id,required" description:"the user ID" min:"1" example:"42"`
+	Name    string            `json:"name,required" minlen:"1" maxlen:"32" regexp:"^[a-z]+$" default:"guest"`
+	Score   float64           `json:"score" min:"0" max:"100" default:"60.5"`
+	Admin   *bool             `json:"admin" default:"false"`
+	Tags    []string          `json:"tags" maxlen:"3"`
+	Attrs   map[string]int    `json:"attrs"`
+	Address *Address          `json:"address"`
+	Created time.Time         `json:"created_at"`
+	Avatar  []byte            `json:"avatar"`
+	Count   int               `json:"count,string"`
+	Extra   interface{}       `json:"extra"`
+	Meta    struct{ A bool }  `json:"meta"`
+	Tree    *Node             `json:"tree"`
+	Friends []User            `json:"friends"`
+	Ignored map[string]string `json:"-"`
+	secret  string
+}
+
+func TestGenerateSchema(t *testing.T) {
+	b, err := jsonschema.GenerateSchema(reflect.TypeOf(&User{}))
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "title": "User",
+  "type": "object",
+  "properties": {
+    "address": {
+      "$ref": "#/definitions/Address"
+    },
+    "admin": {
+      "type": "boolean",
+      "default": false
+    },
+    "attrs": {
+      "type": "object",
+      "additionalProperties": {
+        "type": "integer"
+      }
+    },
+    "avatar": {
+      "type": "string",
+      "contentEncoding": "base64"
+    },
+    "count": {
+      "type": "string"
+    },
+    "created_at": {
+      "type": "string",
+      "format": "date-time"
+    },
+    "extra": {},
+    "friends": {
+      "type": "array",
+      "items": {
+        "$ref": "#"
+      }
+    },
+    "id": {
+      "description": "the user ID",
+      "type": "integer",
+      "minimum": 1,
+      "examples": [
+        42
+      ]
+    },
+    "meta": {
+      "type": "object",
+      "properties": {
+        "A": {
+          "type": "boolean"
+        }
+      }
+    },
+    "name": {
+      "type": "string",
+      "minLength": 1,
+      "maxLength": 32,
+      "pattern": "^[a-z]+$",
+      "default": "guest"
+    },
+    "score": {
+      "type": "number",
+      "minimum": 0,
+      "maximum": 100,
+      "default": 60.5
+    },
+    "tags": {
+      "type": "array",
+      "items": {
+        "type": "string"
+      },
+      "maxItems": 3
+    },
+    "tree": {
+      "$ref": "#/definitions/Node"
+    }
+  },
+  "required": [
+    "id",
+    "name"
+  ],
+  "definitions": {
+    "Address": {
+      "type": "object",
+      "properties": {
+        "city": {
+          "type": "string",
+          "minLength": 1
+        }
+      },
+      "required": [
+        "city"
+      ]
+    },
+    "Node": {
+      "type": "object",
+      "properties": {
+        "children": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/Node"
+          }
+        },
+        "value": {
+          "type": "integer"
+        }
+      }
+    }
+  }
+}`, string(b))
+}
+
+func TestGenerateSchemaError(t *testing.T) {
+	_, err := jsonschema.GenerateSchema(reflect.TypeOf(1))
+	assert.EqualError(t, err, "jsonschema: int is not a struct type")
+
+	type T1 struct {
+		A int `min:"x"`
+	}
+	_, err = jsonschema.GenerateSchema(reflect.TypeOf(T1{}))
+	assert.EqualError(t, err, `jsonschema: T1.A: invalid min tag: "x"`)
+
+	type T2 struct {
+		A string `min:"1"`
+	}
+	_, err = jsonschema.GenerateSchema(reflect.TypeOf(T2{}))
+	assert.EqualError(t, err, "jsonschema: T2.A: min tag is not supported for the type string")
+
+	type T3 struct {
+		A int `default:"1.5"`
+	}
+	_, err = jsonschema.GenerateSchema(reflect.TypeOf(T3{}))
+	assert.EqualError(t, err, `jsonschema: T3.A: invalid default tag: "1.5"`)
+
+	type T4 struct {
+		A string `regexp:"("`
+	}
+	_, err = jsonschema.GenerateSchema(reflect.TypeOf(T4{}))
+	assert.Error(t, err)
+}
@@ -0,0 +1,143 @@
+package binding
+
+import (
+	jsonpkg "encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/go-tagexpr"
+	jwtpkg "github.com/golang-jwt/jwt/v4"
+	"github.com/henrylee2cn/goutil"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// ErrJWTInvalid the error returned when the bearer token of the request is invalid or expired,
+// the fields tagged `jwt:"claim"` are not bound.
+type ErrJWTInvalid struct {
+	// Err the error of the parsing or the verification, such as jwt.ErrTokenExpired
+	Err error
+}
+
+// Error implements error interface.
+func (e *ErrJWTInvalid) Error() string {
+	return "binding: invalid JWT: " + e.Err.Error()
+}
+
+// Unwrap returns the error of the parsing or the verification.
+func (e *ErrJWTInvalid) Unwrap() error {
+	return e.Err
+}
+
+// SetJWTKeyFunc sets the function which returns the key verifying the signature of the bearer token,
+// whose claims are bound to the fields tagged `jwt:"claim"`, such as `jwt:"sub"`.
+// NOTE:
+//  The token is in the 'Authorization: Bearer <token>' header, and is verified once per request;
+//  If the token is invalid or expired, return *ErrJWTInvalid, and if no token, the claims are missing;
+//  The numeric date claims, such as 'exp' and 'iat', are bound to the time.Time fields,
+//  and the other claims, such as 'iss', are bound like the header values;
+//  If fn is nil, all the tokens are invalid.
+func (b *Binding) SetJWTKeyFunc(fn jwtpkg.Keyfunc) *Binding {
+	return b.update(WithJWTKeyFunc(fn))
+}
+
+// getJWTClaims returns the claims of the verified bearer token, nil if no token.
+func (r *receiver) getJWTClaims(rc *requestCache, keyFunc jwtpkg.Keyfunc) (jwtpkg.MapClaims, error) {
+	if !r.hasJWT {
+		return nil, nil
+	}
+	if !rc.jwtParsed {
+		rc.jwtParsed = true
+		rc.jwtClaims, rc.jwtErr = parseBearerToken(rc.req.Header.Get("Authorization"), keyFunc)
+	}
+	return rc.jwtClaims, rc.jwtErr
+}
+
+func parseBearerToken(authorization string, keyFunc jwtpkg.Keyfunc) (jwtpkg.MapClaims, error) {
+	const prefix = "Bearer "
+	if len(authorization) <= len(prefix) || !strings.EqualFold(authorization[:len(prefix)], prefix) {
+		return nil, nil
+	}
+	token, err := jwtpkg.Parse(strings.TrimSpace(authorization[len(prefix):]), keyFunc)
+	if err != nil {
+		return nil, &ErrJWTInvalid{Err: err}
+	}
+	claims, _ := token.Claims.(jwtpkg.MapClaims)
+	return claims, nil
+}
+
+func (p *paramInfo) bindJWT(info *tagInfo, expr *tagexpr.TagExpr, claims jwtpkg.MapClaims) (bool, error) {
+	claim, ok := claims[info.paramName]
+	if !ok || claim == nil {
+		if info.required {
+			return false, info.requiredError
+		}
+		return false, nil
+	}
+	if goutil.DereferenceType(p.structField.Type) == timeType {
+		if f, ok := claimNumber(claim); ok {
+			v, err := p.getField(expr, true)
+			if err != nil || !v.IsValid() {
+				return true, err
+			}
+			sec, frac := int64(f), f-float64(int64(f))
+			goutil.DereferenceValue(v).Set(reflect.ValueOf(time.Unix(sec, int64(frac*1e9))))
+			return true, nil
+		}
+	}
+	switch c := claim.(type) {
+	case []interface{}:
+		a := make([]string, len(c))
+		for i, e := range c {
+			a[i] = claimString(e)
+		}
+		if len(a) == 0 {
+			return true, nil
+		}
+		return true, p.bindStringSlice(info, expr, a)
+	case map[string]interface{}:
+		v, err := p.getField(expr, true)
+		if err != nil || !v.IsValid() {
+			return true, err
+		}
+		b, _ := jsonpkg.Marshal(c)
+		if jsonpkg.Unmarshal(b, v.Addr().Interface()) != nil {
+			return true, info.typeError
+		}
+		if p.sanitizer != nil {
+			return true, sanitizeValue(p.sanitizer, p.bindErrFactory, v, p.fieldSelector)
+		}
+		return true, nil
+	}
+	return true, p.bindStringSlice(info, expr, []string{claimString(claim)})
+}
+
+// claimNumber returns the number of the claim, such as the NumericDate of 'exp'.
+func claimNumber(claim interface{}) (float64, bool) {
+	switch c := claim.(type) {
+	case float64:
+		return c, true
+	case jsonpkg.Number:
+		f, err := c.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// claimString returns the claim as the parameter string, such as '1700000000' for the NumericDate.
+func claimString(claim interface{}) string {
+	switch c := claim.(type) {
+	case string:
+		return c
+	case float64:
+		return strconv.FormatFloat(c, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(c)
+	case jsonpkg.Number:
+		return c.String()
+	}
+	b, _ := jsonpkg.Marshal(claim)
+	return string(b)
+}
@@ -0,0 +1,64 @@
+package binding
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/henrylee2cn/goutil"
+)
+
+const (
+	tagKafkaHeader = "kafka_header"
+	tagKafkaKey    = "kafka_key"
+)
+
+// KafkaHeader the header of Kafka message
+type KafkaHeader struct {
+	Key   string
+	Value []byte
+}
+
+// KafkaMessage the Kafka message, which is implemented by the adapters of Kafka clients
+type KafkaMessage interface {
+	Key() []byte
+	Value() []byte
+	Headers() []KafkaHeader
+}
+
+// BindKafkaMessage binds the Kafka message to the struct.
+// NOTE:
+//  The headers are bound to the fields tagged `kafka_header:"$name"`;
+//  The key is bound to the field tagged `kafka_key:"true"`;
+//  The value is bound to the body fields by the 'content-type' header, the default is application/json;
+//  If msg==nil, ErrNilMessage is returned.
+func (b *Binding) BindKafkaMessage(msg KafkaMessage, structPointer interface{}) error {
+	if msg == nil {
+		return ErrNilMessage
+	}
+	var contentType string
+	headers := msg.Headers()
+	for _, h := range headers {
+		if strings.EqualFold(h.Key, "content-type") {
+			contentType = goutil.BytesToString(h.Value)
+		}
+	}
+	return b.BindMessage(structPointer, msg.Value(), contentType, func(field reflect.StructField) ([]byte, bool) {
+		if name, ok := field.Tag.Lookup(tagKafkaHeader); ok {
+			if name == "" {
+				name = field.Name
+			}
+			var data []byte
+			var found bool
+			for _, h := range headers {
+				if h.Key == name {
+					data, found = h.Value, true
+				}
+			}
+			return data, found
+		}
+		if field.Tag.Get(tagKafkaKey) == "true" {
+			return msg.Key(), true
+		}
+		return nil, false
+	})
+}
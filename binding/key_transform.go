@@ -0,0 +1,107 @@
+package binding
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+const tagKeyTransform = "key_transform"
+
+// keyTransforms the key transforms of the 'key_transform' tag
+var keyTransforms = map[string]func(key string) string{
+	"snake": SnakeCase,
+	"camel": CamelCase,
+	"kebab": KebabCase,
+}
+
+// SetKeyTransform sets the function which normalizes the incoming query parameter keys before lookup,
+// such as SnakeCase, CamelCase or KebabCase, nil to disable it.
+// NOTE:
+//  The 'key_transform' tag of the field overrides it, such as `query:"user_id" key_transform:"snake"`;
+//  The values of the keys normalized to the same one are merged.
+func (b *Binding) SetKeyTransform(fn func(key string) string) *Binding {
+	return b.update(WithKeyTransform(fn))
+}
+
+// SnakeCase converts the camelCase, PascalCase or kebab-case key to snake_case,
+// such as 'userID' to 'user_id'.
+func SnakeCase(key string) string {
+	return strings.Join(keyWords(key), "_")
+}
+
+// KebabCase converts the camelCase, PascalCase or snake_case key to kebab-case,
+// such as 'userID' to 'user-id'.
+func KebabCase(key string) string {
+	return strings.Join(keyWords(key), "-")
+}
+
+// CamelCase converts the snake_case, kebab-case or PascalCase key to camelCase,
+// such as 'user_id' to 'userId'.
+func CamelCase(key string) string {
+	words := keyWords(key)
+	for i := 1; i < len(words); i++ {
+		r := []rune(words[i])
+		r[0] = unicode.ToUpper(r[0])
+		words[i] = string(r)
+	}
+	return strings.Join(words, "")
+}
+
+// keyWords splits the key into the lowercase words,
+// at the underscores, hyphens, spaces and the case changes, such as 'HTTPServerID' to 'http', 'server', 'id'.
+func keyWords(key string) []string {
+	var words []string
+	var word []rune
+	runes := []rune(key)
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == ' ':
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = word[:0]
+			}
+			continue
+		case unicode.IsUpper(r) && len(word) > 0:
+			prev := runes[i-1]
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				// the end of an acronym, such as 'S' of 'HTTPServer'
+				(unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				words = append(words, string(word))
+				word = word[:0]
+			}
+		}
+		word = append(word, unicode.ToLower(r))
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
+
+// queryOf returns the query values of the param,
+// whose keys are normalized by the 'key_transform' tag if specified.
+func (p *paramInfo) queryOf(rc *requestCache, queryValues url.Values) url.Values {
+	if p.keyTransform == "" || rc.queryValues == nil {
+		return queryValues
+	}
+	if v, ok := rc.transformedQueries[p.keyTransform]; ok {
+		return v
+	}
+	if rc.transformedQueries == nil {
+		rc.transformedQueries = make(map[string]url.Values, 1)
+	}
+	v := transformKeys(rc.queryValues, keyTransforms[p.keyTransform])
+	rc.transformedQueries[p.keyTransform] = v
+	return v
+}
+
+// transformKeys returns the values with the keys normalized by fn.
+func transformKeys(values url.Values, fn func(key string) string) url.Values {
+	r := make(url.Values, len(values))
+	for k, v := range values {
+		k = fn(k)
+		r[k] = append(r[k], v...)
+	}
+	return r
+}
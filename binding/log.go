@@ -0,0 +1,83 @@
+package binding
+
+import (
+	"context"
+	"strings"
+)
+
+// tagDeprecated the tag of the deprecated field, such as `deprecated:"use page_size instead"`,
+// which is warned by the logger when it is bound, see SetLogger
+const tagDeprecated = "deprecated"
+
+// redactedValue replaces the raw value of the sensitive field in the log records
+const redactedValue = "[REDACTED]"
+
+// sensitiveNames the parts of the normalized parameter names which are sensitive,
+// such as the Authorization and X-API-Key headers and the access_token query parameter
+var sensitiveNames = []string{
+	"authorization",
+	"apikey",
+	"token",
+	"secret",
+	"password",
+	"passwd",
+	"credential",
+	"cookie",
+	"session",
+}
+
+// bindLogger the structured logger of the binding, see SetLogger.
+type bindLogger interface {
+	// bound logs the field bound from the request
+	bound(ctx context.Context, field BoundField)
+	// warn logs the warning of the bound field, such as the deprecated field
+	warn(ctx context.Context, msg string, field BoundField, note string)
+}
+
+// logBound logs the bound field, and warns if it is deprecated,
+// or it is the sensitive field bound to the zero value in loose zero mode.
+// NOTE:
+//  The raw value of the sensitive field is redacted.
+func (b *Binding) logBound(rc *requestCache, param *paramInfo, info *tagInfo, field BoundField) {
+	ctx := context.Background()
+	if rc.req != nil {
+		ctx = rc.req.Context()
+	}
+	sensitive := isSensitiveParam(param, info)
+	if sensitive && field.RawValue != "" {
+		field.RawValue = redactedValue
+	}
+	b.logger.bound(ctx, field)
+	if param.deprecated {
+		b.logger.warn(ctx, "binding: deprecated field is bound", field, param.deprecation)
+	}
+	if sensitive && param.looseZeroMode && field.RawValue == "" {
+		b.logger.warn(ctx, "binding: sensitive field is bound to the zero value in loose zero mode", field, "")
+	}
+}
+
+// isSensitiveParam returns whether the raw value of the parameter is sensitive,
+// such as the Authorization header, the API key, the password, the encrypted cookie and the raw body.
+func isSensitiveParam(param *paramInfo, info *tagInfo) bool {
+	switch info.paramIn {
+	case raw_body, basic_auth:
+		return true
+	case cookie:
+		if param.cookieEncrypted {
+			return true
+		}
+	}
+	return isSensitiveName(info.paramName)
+}
+
+// isSensitiveName returns whether the parameter name is sensitive, ignoring the case, '-' and '_'.
+func isSensitiveName(name string) bool {
+	name = strings.ToLower(name)
+	name = strings.NewReplacer("-", "", "_", "").Replace(name)
+	for _, s := range sensitiveNames {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package binding
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// BindMerge binds the request parameters to the targets and validates them like BindMultiStruct,
+// then merges the bound fields of the other targets into the first one by the field names,
+// e.g. to combine the path, query and body structs into a single view model.
+// NOTE:
+//  The request is parsed only once, and the path parameters are decoded by the PathParamsDecoder;
+//  The bound field is copied to the field of the first target with the same name if its type is assignable,
+//  and the later target wins if several targets bound the field;
+//  The bound nested field, such as 'A.B', copies its top-level field 'A' as a whole;
+//  If any target fails, the errors are returned and the targets are not merged.
+func (b *Binding) BindMerge(req *http.Request, targets ...interface{}) error {
+	if len(targets) == 0 {
+		return nil
+	}
+	rc := newRequestCache(req)
+	pathParams := b.pathParamsOf(req, nil)
+	values := make([]reflect.Value, len(targets))
+	bound := make([][]BoundField, len(targets))
+	var errs []error
+	for i, target := range targets {
+		rc.bound = &bound[i]
+		v, hasVd, err := b.bindRequest(target, rc, pathParams)
+		if err != nil {
+			return err
+		}
+		if hasVd {
+			if err = b.validate(v); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		values[i] = v
+	}
+	if len(errs) > 0 {
+		return joinErrors(errs)
+	}
+	dst := values[0]
+	for i := 1; i < len(values); i++ {
+		for _, f := range bound[i] {
+			name := f.Selector
+			if j := strings.IndexByte(name, '.'); j >= 0 {
+				name = name[:j]
+			}
+			mergeField(dst, values[i], name)
+		}
+	}
+	return nil
+}
+
+// mergeField copies the field @name of @src to the field of @dst with the same name,
+// if it is settable and its type is assignable.
+func mergeField(dst, src reflect.Value, name string) {
+	to := dst.FieldByName(name)
+	from := src.FieldByName(name)
+	if !to.IsValid() || !to.CanSet() || !from.IsValid() || !from.CanInterface() || !from.Type().AssignableTo(to.Type()) {
+		return
+	}
+	to.Set(from)
+}
@@ -0,0 +1,150 @@
+package binding
+
+import (
+	"bytes"
+	jsonpkg "encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/henrylee2cn/goutil"
+)
+
+// BindWSMessage binds the WebSocket message to the struct.
+// NOTE:
+//  @contentType is the content type of the message agreed at handshake time;
+//  The supported content types are the same as the request body:
+//  application/json, application/x-protobuf, application/x-www-form-urlencoded.
+func (b *Binding) BindWSMessage(msg []byte, contentType string, structPointer interface{}) error {
+	header := make(http.Header, 1)
+	header.Set("Content-Type", contentType)
+	_, _, err := b.bindMessage(structPointer, msg, header)
+	return err
+}
+
+// ErrNilMessage the error returned when the message to bind is nil,
+// by the message queue bindings, such as BindKafkaMessage and BindAMQP.
+var ErrNilMessage = errors.New("binding: message must be non-nil")
+
+// MessageMeta returns the metadata of the message for the struct field, such as a header or the key,
+// and whether it is found.
+type MessageMeta func(field reflect.StructField) (data []byte, found bool)
+
+// BindMessage binds the message which is not from the HTTP request to the struct,
+// it is the building block of the message queue bindings.
+// NOTE:
+//  The body is bound to the body fields by @contentType, the default is application/json;
+//  Then the metadata returned by @meta is bound to the top-level fields, if meta!=nil.
+func (b *Binding) BindMessage(structPointer interface{}, body []byte, contentType string, meta MessageMeta) error {
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	header := make(http.Header, 1)
+	header.Set("Content-Type", contentType)
+	value, _, err := b.bindMessage(structPointer, body, header)
+	if err != nil || meta == nil {
+		return err
+	}
+	return b.bindMessageFields(value, meta)
+}
+
+// bindMessage binds the message which is not from the HTTP request,
+// the @header is the metadata of the message, including the content type.
+func (b *Binding) bindMessage(structPointer interface{}, msg []byte, header http.Header) (value reflect.Value, hasVd bool, err error) {
+	rc := newMessageRequestCache(msg, header)
+	if rc.bodyCodec == bodyUnsupport {
+		return value, false, b.bindErrFactory("", "unsupported content type: "+header.Get("Content-Type"))
+	}
+	return b.bindRequest(structPointer, rc, nil)
+}
+
+// newMessageRequestCache returns the request cache of the message,
+// whose body has been read.
+func newMessageRequestCache(msg []byte, header http.Header) *requestCache {
+	req := &http.Request{
+		Method:        "POST",
+		URL:           new(url.URL),
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(msg)),
+		ContentLength: int64(len(msg)),
+	}
+	rc := newRequestCache(req)
+	rc.bodyRead = true
+	rc.bodyBytes = msg
+	rc.bodyString = goutil.BytesToString(msg)
+	return rc
+}
+
+// bindMessageFields binds the metadata of the message to the top-level fields.
+func (b *Binding) bindMessageFields(value reflect.Value, lookup MessageMeta) error {
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		data, found := lookup(field)
+		if !found {
+			continue
+		}
+		fv := value.Field(i)
+		if !fv.CanSet() {
+			return b.bindErrFactory(field.Name, "field cannot be set: "+field.Name)
+		}
+		if fv.Type() == bytesType {
+			fv.SetBytes(data)
+			continue
+		}
+		if err := b.setMessageField(fv, string(data)); err != nil {
+			return b.bindErrFactory(field.Name, "parameter type does not match binding data")
+		}
+	}
+	return nil
+}
+
+var bytesType = reflect.TypeOf([]byte(nil))
+
+// setMessageField sets the message field by the string value,
+// which is parsed as JSON if it looks like JSON and the field is not a string.
+func (b *Binding) setMessageField(v reflect.Value, s string) error {
+	t := v.Type()
+	elemKind := t.Kind()
+	for elemKind == reflect.Ptr {
+		elemKind = t.Elem().Kind()
+		t = t.Elem()
+	}
+	if elemKind != reflect.String && typeUnmarshalFuncs[t] == nil && looksLikeJSON(s) {
+		ptr := reflect.New(v.Type())
+		if jsonUnmarshal, _ := b.jsonUnmarshaler(); jsonUnmarshal != nil {
+			if err := jsonUnmarshal([]byte(s), ptr.Interface()); err != nil {
+				return err
+			}
+		} else if err := jsonpkg.Unmarshal([]byte(s), ptr.Interface()); err != nil {
+			return err
+		}
+		v.Set(ptr.Elem())
+		return nil
+	}
+	vv, err := stringsToValue(v.Type(), []string{s}, b.config.LooseZeroMode, 0)
+	if err != nil {
+		return err
+	}
+	v.Set(vv.Index(0))
+	return nil
+}
+
+func looksLikeJSON(s string) bool {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 {
+		return false
+	}
+	switch s[0] {
+	case '{':
+		return s[len(s)-1] == '}'
+	case '[':
+		return s[len(s)-1] == ']'
+	case '"':
+		return s[len(s)-1] == '"'
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+// Package nats binds the NATS message of github.com/nats-io/nats.go.
+package nats
+
+import (
+	"reflect"
+
+	"github.com/bytedance/go-tagexpr/binding"
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	tagNATS       = "nats"
+	tagNATSHeader = "nats_header"
+)
+
+// ErrNilMessage the error returned when the message to bind is nil, which is binding.ErrNilMessage.
+var ErrNilMessage = binding.ErrNilMessage
+
+// BindNATS uses the default binding to bind the NATS message to the struct.
+// NOTE:
+//  The subject and reply are bound to the fields tagged `nats:"subject"` and `nats:"reply"`;
+//  The headers are bound to the fields tagged `nats_header:"$name"`;
+//  The data is bound to the body fields by the 'Content-Type' header, the default is application/json.
+func BindNATS(msg *nats.Msg, structPointer interface{}) error {
+	return Bind(binding.Default(), msg, structPointer)
+}
+
+// Bind uses the binding @b to bind the NATS message to the struct.
+// NOTE:
+//  If b==nil, the default binding is used;
+//  If msg==nil, ErrNilMessage is returned.
+func Bind(b *binding.Binding, msg *nats.Msg, structPointer interface{}) error {
+	if msg == nil {
+		return ErrNilMessage
+	}
+	if b == nil {
+		b = binding.Default()
+	}
+	return b.BindMessage(structPointer, msg.Data, msg.Header.Get("Content-Type"), func(field reflect.StructField) ([]byte, bool) {
+		if name, ok := field.Tag.Lookup(tagNATSHeader); ok {
+			if name == "" {
+				name = field.Name
+			}
+			if len(msg.Header.Values(name)) == 0 {
+				return nil, false
+			}
+			return []byte(msg.Header.Get(name)), true
+		}
+		switch field.Tag.Get(tagNATS) {
+		case "subject":
+			return []byte(msg.Subject), true
+		case "reply":
+			return []byte(msg.Reply), true
+		}
+		return nil, false
+	})
+}
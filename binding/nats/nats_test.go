@@ -0,0 +1,46 @@
+package nats_test
+
+import (
+	"testing"
+
+	bindnats "github.com/bytedance/go-tagexpr/binding/nats"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindNATS(t *testing.T) {
+	type Recv struct {
+		Subject string   `nats:"subject"`
+		Reply   string   `nats:"reply"`
+		TraceID string   `nats_header:"Trace-Id"`
+		Retry   *int     `nats_header:"Retry"`
+		A       string   `json:"a,required"`
+		B       []string `json:"b"`
+	}
+	msg := nats.NewMsg("orders.created")
+	msg.Reply = "_INBOX.1"
+	msg.Header.Set("Trace-Id", "abc")
+	msg.Header.Set("Retry", "2")
+	msg.Data = []byte(`{"a":"x","b":["y"]}`)
+	recv := new(Recv)
+	err := bindnats.BindNATS(msg, recv)
+	assert.NoError(t, err)
+	assert.Equal(t, "orders.created", recv.Subject)
+	assert.Equal(t, "_INBOX.1", recv.Reply)
+	assert.Equal(t, "abc", recv.TraceID)
+	assert.Equal(t, 2, *recv.Retry)
+	assert.Equal(t, "x", recv.A)
+	assert.Equal(t, []string{"y"}, recv.B)
+
+	msg.Header.Set("Retry", "x")
+	err = bindnats.Bind(nil, msg, new(Recv))
+	assert.EqualError(t, err, "binding Retry: parameter type does not match binding data")
+
+	msg.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	msg.Data = []byte("a=x")
+	err = bindnats.BindNATS(msg, new(Recv))
+	assert.EqualError(t, err, "binding a: missing required parameter")
+
+	err = bindnats.BindNATS(nil, new(Recv))
+	assert.Equal(t, bindnats.ErrNilMessage, err)
+}
@@ -0,0 +1,267 @@
+package binding
+
+import (
+	"context"
+	"crypto/cipher"
+
+	"github.com/bytedance/go-tagexpr/validator"
+	jwtpkg "github.com/golang-jwt/jwt/v4"
+)
+
+// Option the option of NewBinding, which sets the options of the binding.
+type Option struct {
+	// config the config of WithConfig, which is applied before the other options
+	config *Config
+	apply  func(*options)
+}
+
+// options the settings of the binding, which are collected from the options,
+// and set to the binding at once, see NewBinding.
+type options struct {
+	config         Config
+	bindErrFactory func(failField, msg string) error
+	// validatingErrFactory the error factory of the validator, nil to use the default
+	validatingErrFactory func(failField, msg string) error
+	// validator the validator of WithValidator, nil to create it by the config
+	validator     *validator.Validator
+	pathDecoder   PathParamsDecoder
+	maxFormFields int
+	maxHeaderSize int64
+	sanitizer     func(field, value string) (string, error)
+	// jsonUnmarshal the JSON unmarshal function of the binding, nil to use the global one
+	jsonUnmarshal         func(data []byte, v interface{}) error
+	jsonVerifyingRequired bool
+	// ctx the context of validation without the request context
+	ctx context.Context
+	// keyTransform normalizes the query parameter keys, nil to disable it
+	keyTransform func(key string) string
+	// collectAll reports all the binding and validation errors of BindAndValidate
+	collectAll bool
+	// trustedProxyHeaders the headers of the original protocol checked by BindSecure
+	trustedProxyHeaders []string
+	// cookieSigningKey the HMAC key of the cookies tagged `cookie_signed:"true"`
+	cookieSigningKey         []byte
+	cookieSignatureSeparator string
+	// cookieAEADs the AES-GCM ciphers of the cookies tagged `cookie_encrypted:"true"`
+	cookieAEADs []cipher.AEAD
+	// jwtKeyFunc returns the key verifying the bearer token of the fields tagged `jwt:"claim"`
+	jwtKeyFunc jwtpkg.Keyfunc
+	// apiKeyValidator returns the user ID of the API key of the fields tagged `apikey:"true"`
+	apiKeyValidator func(key string) (userID string, err error)
+	apiKeySources   []Source
+	// rateLimiter enforces the rate limits of the 'rate_limit' tags
+	rateLimiter func(field, key string, limit RateLimit) error
+	// logger logs the bound fields, nil to disable it
+	logger bindLogger
+	// tracer traces the binding operations, nil to disable it
+	tracer bindTracer
+}
+
+// newOptions returns the default options with the config.
+// NOTE:
+//  Use default tag name for config fields that are empty
+func newOptions(config *Config) options {
+	o := options{
+		bindErrFactory:           defaultBindErrFactory,
+		maxFormFields:            defaultMaxFormFields,
+		maxHeaderSize:            defaultMaxHeaderSize,
+		trustedProxyHeaders:      defaultTrustedProxyHeaders,
+		cookieSignatureSeparator: defaultCookieSignatureSeparator,
+		apiKeySources:            defaultAPIKeySources,
+	}
+	if config != nil {
+		o.config = *config
+	}
+	o.config.init()
+	return o
+}
+
+// NewBinding creates a binding tool with the options.
+// NOTE:
+//  The options are collected first, and then set to the binding at once;
+//  The config of WithConfig is applied first, and then the other options in order,
+//  so the result does not depend on the position of WithConfig;
+//  If WithConfig is specified more than once, the last one is used;
+//  The setter methods, such as SetLooseZeroMode, apply the option to the current options in the same way.
+func NewBinding(opts ...Option) *Binding {
+	var config *Config
+	for _, opt := range opts {
+		if opt.config != nil {
+			config = opt.config
+		}
+	}
+	o := newOptions(config)
+	for _, opt := range opts {
+		if opt.apply != nil {
+			opt.apply(&o)
+		}
+	}
+	return new(Binding).setOptions(o)
+}
+
+// WithConfig sets the tag names of the binding.
+// NOTE:
+//  The validator is created by the validator tag name, unless WithValidator is specified.
+func WithConfig(config *Config) Option {
+	if config == nil {
+		config = new(Config)
+	}
+	return Option{config: config}
+}
+
+// WithLooseZeroMode binds the empty string request parameter to the zero value of parameter,
+// see SetLooseZeroMode.
+func WithLooseZeroMode() Option {
+	return Option{apply: func(o *options) {
+		o.config.LooseZeroMode = true
+	}}
+}
+
+// WithErrorFactory sets the factory of the binding and validation errors,
+// see SetErrorFactory.
+func WithErrorFactory(bindErrFactory, validatingErrFactory func(failField, msg string) error) Option {
+	return Option{apply: func(o *options) {
+		if bindErrFactory == nil {
+			bindErrFactory = defaultBindErrFactory
+		}
+		o.bindErrFactory = bindErrFactory
+		o.validatingErrFactory = validatingErrFactory
+	}}
+}
+
+// WithJSONUnmarshaler sets the JSON unmarshal function of the binding,
+// see SetJSONUnmarshaler.
+func WithJSONUnmarshaler(verifyingRequired bool, fn func(data []byte, v interface{}) error) Option {
+	return Option{apply: func(o *options) {
+		o.jsonUnmarshal = fn
+		o.jsonVerifyingRequired = verifyingRequired
+	}}
+}
+
+// WithValidator sets the validator of the binding,
+// see SetValidator.
+func WithValidator(v *validator.Validator) Option {
+	return Option{apply: func(o *options) {
+		if v != nil {
+			o.validator = v
+		}
+	}}
+}
+
+// WithRequestContext sets the context of validation without the request context,
+// see SetRequestContext.
+func WithRequestContext(ctx context.Context) Option {
+	return Option{apply: func(o *options) {
+		o.ctx = ctx
+	}}
+}
+
+// WithPathParamsDecoder sets the decoder of the path parameters,
+// see SetPathParamsDecoder.
+func WithPathParamsDecoder(decoder PathParamsDecoder) Option {
+	return Option{apply: func(o *options) {
+		o.pathDecoder = decoder
+	}}
+}
+
+// WithMaxFormFields sets the maximum number of the form fields parsed from the body,
+// see SetMaxFormFields.
+func WithMaxFormFields(n int) Option {
+	return Option{apply: func(o *options) {
+		o.maxFormFields = n
+	}}
+}
+
+// WithMaxHeaderSize sets the maximum total byte length of the header values,
+// see SetMaxHeaderSize.
+func WithMaxHeaderSize(n int64) Option {
+	return Option{apply: func(o *options) {
+		o.maxHeaderSize = n
+	}}
+}
+
+// WithStringSanitizer sets the sanitizer which is called for every bound string field,
+// see SetStringSanitizer.
+func WithStringSanitizer(fn func(field, value string) (string, error)) Option {
+	return Option{apply: func(o *options) {
+		o.sanitizer = fn
+	}}
+}
+
+// WithKeyTransform sets the function which normalizes the incoming query parameter keys,
+// see SetKeyTransform.
+func WithKeyTransform(fn func(key string) string) Option {
+	return Option{apply: func(o *options) {
+		o.keyTransform = fn
+	}}
+}
+
+// WithCollectAll reports all the binding and validation errors of BindAndValidate,
+// see SetCollectAll.
+func WithCollectAll() Option {
+	return Option{apply: func(o *options) {
+		o.collectAll = true
+	}}
+}
+
+// WithTrustedProxyHeaders sets the headers of the original protocol set by the trusted TLS-terminating proxy,
+// see SetTrustedProxyHeaders.
+func WithTrustedProxyHeaders(headers ...string) Option {
+	return Option{apply: func(o *options) {
+		o.trustedProxyHeaders = append([]string(nil), headers...)
+	}}
+}
+
+// WithCookieSigningKey sets the HMAC-SHA256 key which verifies the signed cookies,
+// see SetCookieSigningKey.
+func WithCookieSigningKey(key []byte) Option {
+	return Option{apply: func(o *options) {
+		o.cookieSigningKey = append([]byte(nil), key...)
+	}}
+}
+
+// WithCookieEncryptionKeys sets the AES keys which decrypt the encrypted cookies in order,
+// see SetCookieEncryptionKeys.
+// NOTE:
+//  panic if a key is not 16, 24 or 32 bytes, use SetCookieEncryptionKeys to get the error instead.
+func WithCookieEncryptionKeys(keys ...[]byte) Option {
+	aeads, err := newCookieAEADs(keys)
+	if err != nil {
+		panic(err)
+	}
+	return Option{apply: func(o *options) {
+		o.cookieAEADs = aeads
+	}}
+}
+
+// WithJWTKeyFunc sets the function which returns the key verifying the bearer token,
+// see SetJWTKeyFunc.
+func WithJWTKeyFunc(fn jwtpkg.Keyfunc) Option {
+	return Option{apply: func(o *options) {
+		o.jwtKeyFunc = fn
+	}}
+}
+
+// WithAPIKeyValidator sets the function which validates the API key and returns the user ID,
+// see SetAPIKeyValidator.
+func WithAPIKeyValidator(fn func(key string) (userID string, err error)) Option {
+	return Option{apply: func(o *options) {
+		o.apiKeyValidator = fn
+	}}
+}
+
+// WithAPIKeySources sets the sources of the API key in the order of precedence,
+// see SetAPIKeySources.
+func WithAPIKeySources(sources ...Source) Option {
+	return Option{apply: func(o *options) {
+		o.apiKeySources = apiKeySourcesOf(sources)
+	}}
+}
+
+// WithRateLimiter sets the hook which enforces the rate limits of the 'rate_limit' tags,
+// see SetRateLimiter.
+func WithRateLimiter(fn func(field, key string, limit RateLimit) error) Option {
+	return Option{apply: func(o *options) {
+		o.rateLimiter = fn
+	}}
+}
@@ -0,0 +1,89 @@
+//go:build otel
+// +build otel
+
+package binding
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// tracerName the instrumentation name of the tracer
+	tracerName = "github.com/bytedance/go-tagexpr/binding"
+	// spanName the name of the span of the binding operations
+	spanName = "http.binding"
+)
+
+// SetTracerProvider sets the provider of the OpenTelemetry tracer,
+// which traces Bind, BindAndValidate and BindAndValidateContext by the span named 'http.binding'.
+// NOTE:
+//  It is built with the 'otel' build tag;
+//  The span is the child of the span in the request context, or the context of BindAndValidateContext,
+//  with the attributes of the struct type, the content type, and whether the validation passed if the struct is validated;
+//  The context of the span is passed to the logger and the validator functions registered by validator.RegCtxFunc;
+//  The error of the binding or the validation is recorded as the Error status of the span;
+//  If tp is nil, the operations are not traced.
+func (b *Binding) SetTracerProvider(tp trace.TracerProvider) *Binding {
+	return b.update(WithTracerProvider(tp))
+}
+
+// SetTracerProvider sets the provider of the OpenTelemetry tracer, see Binding.SetTracerProvider.
+// NOTE:
+//  If tp is nil, the operations are not traced.
+func SetTracerProvider(tp trace.TracerProvider) {
+	defaultBinding.SetTracerProvider(tp)
+}
+
+// WithTracerProvider sets the provider of the OpenTelemetry tracer, see SetTracerProvider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return Option{apply: func(o *options) {
+		if tp == nil {
+			o.tracer = nil
+		} else {
+			o.tracer = otelTracer{tp.Tracer(tracerName)}
+		}
+	}}
+}
+
+// otelTracer the bindTracer of the OpenTelemetry tracer
+type otelTracer struct {
+	tracer trace.Tracer
+}
+
+func (t otelTracer) start(ctx context.Context, req *http.Request, structPointer interface{}) (context.Context, bindSpan) {
+	var structName string
+	if typ := reflect.TypeOf(structPointer); typ != nil {
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		structName = typ.String()
+	}
+	ctx, span := t.tracer.Start(ctx, spanName, trace.WithAttributes(
+		attribute.String("binding.struct", structName),
+		attribute.String("http.request.content_type", req.Header.Get("Content-Type")),
+	))
+	return ctx, otelSpan{span}
+}
+
+// otelSpan the bindSpan of the OpenTelemetry span
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s otelSpan) validated(passed bool) {
+	s.span.SetAttributes(attribute.Bool("binding.validation.passed", passed))
+}
+
+func (s otelSpan) end(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
@@ -0,0 +1,101 @@
+//go:build otel
+// +build otel
+
+package binding_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bytedance/go-tagexpr/binding"
+	vd "github.com/bytedance/go-tagexpr/validator"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSetTracerProvider(t *testing.T) {
+	type Recv struct {
+		ID   int    `query:"id,required"`
+		Name string `query:"name" vd:"len($)>0"`
+	}
+	sr := tracetest.NewSpanRecorder()
+	binder := binding.NewBinding(binding.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))))
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	attrsOf := func(span sdktrace.ReadOnlySpan) map[attribute.Key]interface{} {
+		m := make(map[attribute.Key]interface{})
+		for _, kv := range span.Attributes() {
+			m[kv.Key] = kv.Value.AsInterface()
+		}
+		return m
+	}
+
+	req := newRequest("http://localhost/?id=1&name=a", header, nil, nil)
+	assert.NoError(t, binder.BindAndValidate(new(Recv), req, nil))
+	// the span of the validation failure
+	req = newRequest("http://localhost/?id=1", header, nil, nil)
+	assert.Error(t, binder.BindAndValidate(new(Recv), req, nil))
+	// the span of the binding failure
+	req = newRequest("http://localhost/?name=a", header, nil, nil)
+	assert.Error(t, binder.Bind(new(Recv), req, nil))
+
+	spans := sr.Ended()
+	if assert.Len(t, spans, 3) {
+		for _, span := range spans {
+			assert.Equal(t, "http.binding", span.Name())
+		}
+		assert.Equal(t, map[attribute.Key]interface{}{
+			"binding.struct":            "binding_test.Recv",
+			"http.request.content_type": "application/json",
+			"binding.validation.passed": true,
+		}, attrsOf(spans[0]))
+		assert.Equal(t, codes.Unset, spans[0].Status().Code)
+		assert.Equal(t, false, attrsOf(spans[1])["binding.validation.passed"])
+		assert.Equal(t, codes.Error, spans[1].Status().Code)
+		assert.NotContains(t, attrsOf(spans[2]), attribute.Key("binding.validation.passed"))
+		assert.Equal(t, codes.Error, spans[2].Status().Code)
+	}
+
+	// the validation of collecting all the errors
+	binder.SetCollectAll(true)
+	req = newRequest("http://localhost/?id=1", header, nil, nil)
+	assert.Error(t, binder.BindAndValidate(new(Recv), req, nil))
+	binder.SetCollectAll(false)
+	if spans := sr.Ended(); assert.Len(t, spans, 4) {
+		assert.Equal(t, false, attrsOf(spans[3])["binding.validation.passed"])
+	}
+
+	binder.SetTracerProvider(nil)
+	assert.NoError(t, binder.Bind(new(Recv), newRequest("http://localhost/?id=1", header, nil, nil), nil))
+	assert.Len(t, sr.Ended(), 4)
+}
+
+func TestTracerContext(t *testing.T) {
+	var spanID trace.SpanID
+	vd.MustRegCtxFunc("traced", func(ctx context.Context, args ...interface{}) (bool, error) {
+		spanID = trace.SpanContextFromContext(ctx).SpanID()
+		return true, nil
+	}, true)
+	type Recv struct {
+		Name string `json:"name" vd:"traced($)"`
+	}
+	sr := tracetest.NewSpanRecorder()
+	binder := binding.NewBinding(binding.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))))
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	req := newRequest("http://localhost/", header, nil, strings.NewReader(`{"name":"a"}`))
+	assert.NoError(t, binder.BindAndValidateContext(nil, new(Recv), req, nil))
+	if spans := sr.Ended(); assert.Len(t, spans, 1) {
+		assert.Equal(t, spans[0].SpanContext().SpanID(), spanID)
+	}
+	// the body is restored in the original request
+	recv := new(Recv)
+	assert.NoError(t, binding.Bind(recv, req, nil))
+	assert.Equal(t, "a", recv.Name)
+}
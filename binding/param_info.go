@@ -5,12 +5,30 @@ import (
 	"net/url"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/bytedance/go-tagexpr"
 	"github.com/henrylee2cn/goutil"
 	"github.com/tidwall/gjson"
 )
 
+// headerAll the name of the header tag which binds all the headers to the sync.Map field, i.e. `header:"*"`
+const headerAll = "*"
+
+var syncMapType = reflect.TypeOf((*sync.Map)(nil)).Elem()
+
+// isOpaqueField returns whether the field is nested in one of the opaque fields,
+// such as the internal fields of the sync.Map, which are not bound.
+func isOpaqueField(fieldSelector string, opaqueSelectors []string) bool {
+	for _, s := range opaqueSelectors {
+		if strings.HasPrefix(fieldSelector, s) && len(fieldSelector) > len(s) && fieldSelector[len(s)] == '.' {
+			return true
+		}
+	}
+	return false
+}
+
 type paramInfo struct {
 	fieldSelector  string
 	structField    reflect.StructField
@@ -18,6 +36,21 @@ type paramInfo struct {
 	omitIns        map[in]bool
 	bindErrFactory func(failField, msg string) error
 	looseZeroMode  bool
+	// sanitizer the string sanitizer of the binding, see SetStringSanitizer
+	sanitizer func(field, value string) (string, error)
+	// sizeHint the capacity of the bound slice, specified by the 'size_hint' tag
+	sizeHint int
+	// keyTransform the name of the query key transform, specified by the 'key_transform' tag
+	keyTransform string
+	// cookieSigned whether the cookie is signed, specified by the 'cookie_signed' tag
+	cookieSigned bool
+	// cookieEncrypted whether the cookie is encrypted, specified by the 'cookie_encrypted' tag
+	cookieEncrypted bool
+	// rateLimit the rate limit of the key field, specified by the 'rate_limit' tag
+	rateLimit *RateLimit
+	// deprecated whether the field is tagged 'deprecated', and deprecation is the tag value
+	deprecated  bool
+	deprecation string
 }
 
 func (p *paramInfo) name(paramIn in) string {
@@ -65,7 +98,11 @@ func (p *paramInfo) bindRawBody(info *tagInfo, expr *tagexpr.TagExpr, bodyBytes
 		v.Set(reflect.ValueOf(bodyBytes))
 		return nil
 	case reflect.String:
-		v.Set(reflect.ValueOf(goutil.BytesToString(bodyBytes)))
+		a, err := p.sanitizeStrings(info, []string{goutil.BytesToString(bodyBytes)})
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(a[0]))
 		return nil
 	default:
 		return info.typeError
@@ -91,14 +128,51 @@ func (p *paramInfo) bindQuery(info *tagInfo, expr *tagexpr.TagExpr, queryValues
 }
 
 func (p *paramInfo) bindHeader(info *tagInfo, expr *tagexpr.TagExpr, header http.Header) (bool, error) {
+	if info.paramName == headerAll {
+		return p.bindHeaderMap(info, expr, header)
+	}
 	return p.bindMapStrings(info, expr, header)
 }
 
-func (p *paramInfo) bindCookie(info *tagInfo, expr *tagexpr.TagExpr, cookies []*http.Cookie) error {
+// bindHeaderMap stores all the headers in the sync.Map field tagged `header:"*"`,
+// the key is the canonical header name, and the value is string, or []string for the multi-value header.
+func (p *paramInfo) bindHeaderMap(info *tagInfo, expr *tagexpr.TagExpr, header http.Header) (bool, error) {
+	if len(header) == 0 {
+		if info.required {
+			return false, info.requiredError
+		}
+		return false, nil
+	}
+	v, err := p.getField(expr, true)
+	if err != nil || !v.IsValid() {
+		return false, err
+	}
+	m := goutil.DereferenceValue(v).Addr().Interface().(*sync.Map)
+	for key, values := range header {
+		switch len(values) {
+		case 0:
+		case 1:
+			m.Store(key, values[0])
+		default:
+			m.Store(key, append([]string(nil), values...))
+		}
+	}
+	return true, nil
+}
+
+// bindCookie binds the cookies, which are verified or decrypted by decode if decode!=nil.
+func (p *paramInfo) bindCookie(info *tagInfo, expr *tagexpr.TagExpr, cookies []*http.Cookie, decode func(value string) (string, error)) error {
 	var r []string
 	for _, c := range cookies {
 		if c.Name == info.paramName {
-			r = append(r, c.Value)
+			value := c.Value
+			if decode != nil {
+				var err error
+				if value, err = decode(value); err != nil {
+					return p.bindErrFactory(info.namePath, err.Error())
+				}
+			}
+			r = append(r, value)
 		}
 	}
 	if len(r) == 0 {
@@ -107,15 +181,20 @@ func (p *paramInfo) bindCookie(info *tagInfo, expr *tagexpr.TagExpr, cookies []*
 		}
 		return nil
 	}
+	if p.cookieEncrypted {
+		if ok, err := p.bindJSONCookie(info, expr, r[0]); ok {
+			return err
+		}
+	}
 	return p.bindStringSlice(info, expr, r)
 }
 
-func (p *paramInfo) bindOrRequireBody(info *tagInfo, expr *tagexpr.TagExpr, bodyCodec codec, bodyString string, postForm map[string][]string) (bool, error) {
+func (p *paramInfo) bindOrRequireBody(info *tagInfo, expr *tagexpr.TagExpr, bodyCodec codec, bodyString string, postForm map[string][]string, jsonIndependentRequired bool) (bool, error) {
 	switch bodyCodec {
 	case bodyForm:
 		return p.bindMapStrings(info, expr, postForm)
 	case bodyJSON:
-		err := p.checkRequireJSON(info, expr, bodyString, false)
+		err := p.checkRequireJSON(info, expr, bodyString, false, jsonIndependentRequired)
 		return err == nil, err
 	case bodyProtobuf:
 		err := p.checkRequireProtobuf(info, expr, false)
@@ -135,7 +214,7 @@ func (p *paramInfo) checkRequireProtobuf(info *tagInfo, expr *tagexpr.TagExpr, c
 	return nil
 }
 
-func (p *paramInfo) checkRequireJSON(info *tagInfo, expr *tagexpr.TagExpr, bodyString string, checkOpt bool) error {
+func (p *paramInfo) checkRequireJSON(info *tagInfo, expr *tagexpr.TagExpr, bodyString string, checkOpt, jsonIndependentRequired bool) error {
 	if jsonIndependentRequired && (checkOpt || info.required) {
 		r := gjson.Get(bodyString, info.namePath)
 		if !r.Exists() {
@@ -160,14 +239,45 @@ func (p *paramInfo) bindMapStrings(info *tagInfo, expr *tagexpr.TagExpr, values
 	return true, p.bindStringSlice(info, expr, r)
 }
 
+// bindString sets the first value to the string type field directly.
+func (p *paramInfo) bindString(info *tagInfo, expr *tagexpr.TagExpr, values map[string][]string) (bool, error) {
+	r := values[info.paramName]
+	if len(r) == 0 {
+		if info.required {
+			return false, info.requiredError
+		}
+		return false, nil
+	}
+	r, err := p.sanitizeStrings(info, r)
+	if err != nil {
+		return true, err
+	}
+	v, err := p.getField(expr, true)
+	if err != nil || !v.IsValid() {
+		return false, err
+	}
+	v.SetString(r[0])
+	return true, nil
+}
+
 // NOTE: len(a)>0
 func (p *paramInfo) bindStringSlice(info *tagInfo, expr *tagexpr.TagExpr, a []string) error {
+	a, err := p.sanitizeStrings(info, a)
+	if err != nil {
+		return err
+	}
 	v, err := p.getField(expr, true)
 	if err != nil || !v.IsValid() {
 		return err
 	}
 
 	v = goutil.DereferenceValue(v)
+	if isBig, err := setBigNumber(v, a[0], p.looseZeroMode); isBig {
+		if err != nil {
+			return info.typeError
+		}
+		return nil
+	}
 	switch v.Kind() {
 	case reflect.String:
 		v.Set(reflect.ValueOf(a[0]))
@@ -251,7 +361,7 @@ func (p *paramInfo) bindStringSlice(info *tagInfo, expr *tagexpr.TagExpr, a []st
 			return nil
 		}
 	case reflect.Slice:
-		vv, err := stringsToValue(v.Type().Elem(), a, p.looseZeroMode)
+		vv, err := stringsToValue(v.Type().Elem(), a, p.looseZeroMode, p.sizeHint)
 		if err == nil {
 			v.Set(vv)
 			return nil
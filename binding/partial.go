@@ -0,0 +1,81 @@
+package binding
+
+import "net/http"
+
+// Source the source of the request parameters.
+type Source uint8
+
+// The sources of the request parameters
+const (
+	SourcePath      = Source(path)
+	SourceForm      = Source(form)
+	SourceQuery     = Source(query)
+	SourceCookie    = Source(cookie)
+	SourceHeader    = Source(header)
+	SourceProtobuf  = Source(protobuf)
+	SourceJSON      = Source(json)
+	SourceRawBody   = Source(raw_body)
+	SourceJWT       = Source(jwt)
+	SourceAPIKey    = Source(apikey)
+	SourceBasicAuth = Source(basic_auth)
+)
+
+// String returns the default tag name of the source, such as 'query'.
+func (s Source) String() string {
+	switch in(s) {
+	case path:
+		return defaultTagPath
+	case form:
+		return defaultTagForm
+	case query:
+		return defaultTagQuery
+	case cookie:
+		return defaultTagCookie
+	case header:
+		return defaultTagHeader
+	case protobuf:
+		return tagProtobuf
+	case json:
+		return tagJSON
+	case raw_body:
+		return defaultTagRawbody
+	case jwt:
+		return tagJWT
+	case apikey:
+		return tagAPIKey
+	case basic_auth:
+		return tagBasicAuth
+	}
+	return "undefined"
+}
+
+// BindPartial binds only the request parameters from the specified sources.
+// NOTE:
+//  The fields which do not accept the sources are not processed, even if they are required;
+//  The body is not decoded unless its codec source is specified;
+//  The path parameters are decoded by the decoder set by SetPathParamsDecoder.
+func (b *Binding) BindPartial(req *http.Request, structPointer interface{}, sources ...Source) error {
+	rc := newRequestCache(req)
+	rc.sources = new([maxIn]bool)
+	for _, s := range sources {
+		if in(s) > undefined && in(s) < maxIn {
+			rc.sources[s] = true
+		}
+	}
+	if !rc.sources[rc.bodyCodec] {
+		rc.bodyCodec = bodyUnsupport
+	}
+	_, _, err := b.bindRequest(structPointer, rc, b.pathParamsOf(req, nil))
+	return err
+}
+
+// filterTagInfos returns the tag infos whose sources are specified.
+func filterTagInfos(tagInfos []*tagInfo, sources *[maxIn]bool) []*tagInfo {
+	a := make([]*tagInfo, 0, len(tagInfos))
+	for _, info := range tagInfos {
+		if sources[info.paramIn] {
+			a = append(a, info)
+		}
+	}
+	return a
+}
@@ -1,8 +1,14 @@
 package binding
 
+import "net/http"
+
 // PathParams parameter acquisition interface on the URL path
 type PathParams interface {
 	// Get returns the value of the first parameter which key matches the given name.
 	// If no matching parameter is found, an empty string is returned.
 	Get(name string) (string, bool)
 }
+
+// PathParamsDecoder gets the path parameters from the request,
+// used when the pathParams argument of binding is nil.
+type PathParamsDecoder func(req *http.Request) PathParams
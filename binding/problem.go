@@ -0,0 +1,103 @@
+package binding
+
+import (
+	jsonpkg "encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/bytedance/go-tagexpr/validator"
+)
+
+// ProblemContentType the media type of RFC 7807 Problem Details
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails the RFC 7807 Problem Details of the binding error.
+type ProblemDetails struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	InvalidParams []InvalidParam `json:"invalid-params,omitempty"`
+}
+
+// InvalidParam the field error in the 'invalid-params' extension of the Problem Details.
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+	Code   string `json:"code,omitempty"`
+}
+
+// NewProblemDetails creates the Problem Details of the error.
+// NOTE:
+//  The type is 'about:blank' and the title is the status text;
+//  The field of *Error or *validator.Error is listed in the 'invalid-params',
+//  and each of validator.Errors is listed.
+func NewProblemDetails(err error, status int) *ProblemDetails {
+	p := &ProblemDetails{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+	}
+	if err == nil {
+		return p
+	}
+	p.Detail = err.Error()
+	errs, ok := err.(validator.Errors)
+	if !ok {
+		errs = validator.Errors{err}
+	}
+	for _, err := range errs {
+		if param, ok := invalidParamOf(err); ok {
+			p.InvalidParams = append(p.InvalidParams, param)
+		}
+	}
+	return p
+}
+
+// invalidParamOf returns the field error of *Error or *validator.Error.
+func invalidParamOf(err error) (InvalidParam, bool) {
+	var bindErr *Error
+	var vdErr *validator.Error
+	switch {
+	case errors.As(err, &bindErr):
+		reason := bindErr.Msg
+		if reason == "" {
+			reason = bindErr.ErrType + " fail"
+		}
+		return InvalidParam{Name: bindErr.FailField, Reason: reason, Code: bindErr.Code}, true
+	case errors.As(err, &vdErr):
+		reason := vdErr.Msg
+		if reason == "" {
+			reason = "invalid parameter"
+		}
+		return InvalidParam{Name: vdErr.FailPath, Reason: reason, Code: vdErr.Code}, true
+	}
+	return InvalidParam{}, false
+}
+
+// WriteProblemDetails writes the error as the RFC 7807 Problem Details JSON object.
+func WriteProblemDetails(w http.ResponseWriter, err error, status int) error {
+	b, marshalErr := jsonpkg.Marshal(NewProblemDetails(err, status))
+	if marshalErr != nil {
+		return marshalErr
+	}
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(status)
+	_, writeErr := w.Write(b)
+	return writeErr
+}
+
+// AcceptsProblemDetails returns whether the 'Accept' header of the request includes application/problem+json.
+func AcceptsProblemDetails(req *http.Request) bool {
+	for _, accept := range req.Header.Values("Accept") {
+		for _, s := range strings.Split(accept, ",") {
+			mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(s))
+			if err == nil && mediaType == ProblemContentType {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,261 @@
+// Package protodesc generates the proto3 descriptor (.proto file) of the structs by their tags,
+// e.g. to generate the protobuf messages from the Go API definitions.
+package protodesc
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/bytedance/go-tagexpr/binding/internal/typeinfo"
+)
+
+const (
+	// tagProtobufField the tag of the field number, such as `protobuf_field:"1"`
+	tagProtobufField = "protobuf_field"
+	// tagProtobuf the tag of the field name, such as `protobuf:"user_id"`,
+	// or the tag generated by protoc-gen-go, such as `protobuf:"varint,1,opt,name=user_id,proto3"`
+	tagProtobuf = "protobuf"
+	// tagProtoName the tag of the field name, which is used by BindGRPCRequest
+	tagProtoName = "proto_name"
+)
+
+// well-known types
+const (
+	timestampType  = "google.protobuf.Timestamp"
+	timestampProto = "google/protobuf/timestamp.proto"
+	anyType        = "google.protobuf.Any"
+	anyProto       = "google/protobuf/any.proto"
+)
+
+// GenerateProto3 returns the proto3 file of the package, which defines the messages of the struct types,
+// and of the named struct types referenced by their fields.
+// NOTE:
+//  The pointer to struct type is dereferenced, and the other types are ignored;
+//  The field number is specified by the 'protobuf_field' tag, or by the protoc-gen-go style 'protobuf' tag,
+//  the field without the number is omitted;
+//  The field name is specified by the 'proto_name' tag, the 'protobuf' tag or its 'name=' option,
+//  the default is the snake case of the Go field name;
+//  The pointer to scalar is 'optional', the slice is 'repeated', time.Time is google.protobuf.Timestamp,
+//  and interface{} is google.protobuf.Any;
+//  The field of the unsupported type, such as the channel, is commented out.
+func GenerateProto3(pkgName string, types ...reflect.Type) string {
+	g := &generator{seen: make(map[reflect.Type]bool, len(types)), imports: make(map[string]bool)}
+	for _, t := range types {
+		g.enqueue(t)
+	}
+	var messages strings.Builder
+	for i := 0; i < len(g.queue); i++ {
+		messages.WriteByte('\n')
+		g.writeMessage(&messages, g.queue[i])
+	}
+	var b strings.Builder
+	b.WriteString("syntax = \"proto3\";\n")
+	if pkgName != "" {
+		b.WriteString("\npackage ")
+		b.WriteString(pkgName)
+		b.WriteString(";\n")
+	}
+	if len(g.imports) > 0 {
+		imports := make([]string, 0, len(g.imports))
+		for imp := range g.imports {
+			imports = append(imports, imp)
+		}
+		sort.Strings(imports)
+		b.WriteByte('\n')
+		for _, imp := range imports {
+			b.WriteString("import \"")
+			b.WriteString(imp)
+			b.WriteString("\";\n")
+		}
+	}
+	b.WriteString(messages.String())
+	return b.String()
+}
+
+type generator struct {
+	queue   []reflect.Type
+	seen    map[reflect.Type]bool
+	imports map[string]bool
+}
+
+// enqueue adds the named struct type to be defined, and reports whether it is a named struct type.
+func (g *generator) enqueue(t reflect.Type) bool {
+	t = typeinfo.Deref(t)
+	if t.Kind() != reflect.Struct || t.Name() == "" || t == typeinfo.TimeType {
+		return false
+	}
+	if !g.seen[t] {
+		g.seen[t] = true
+		g.queue = append(g.queue, t)
+	}
+	return true
+}
+
+func (g *generator) writeMessage(b *strings.Builder, t reflect.Type) {
+	b.WriteString("message ")
+	b.WriteString(typeinfo.Name(t))
+	b.WriteString(" {\n")
+	g.writeFields(b, t)
+	b.WriteString("}\n")
+}
+
+// writeFields writes the fields of the struct, including the promoted ones.
+func (g *generator) writeFields(b *strings.Builder, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		number, name := fieldNumberAndName(field)
+		if number == 0 {
+			if ft := typeinfo.Deref(field.Type); field.Anonymous && ft.Kind() == reflect.Struct {
+				g.writeFields(b, ft)
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		typ, ok := g.fieldType(field.Type)
+		b.WriteString("  ")
+		if !ok {
+			b.WriteString("// ")
+			b.WriteString(name)
+			b.WriteString(": unsupported type ")
+			b.WriteString(field.Type.String())
+			b.WriteByte('\n')
+			continue
+		}
+		b.WriteString(typ)
+		b.WriteByte(' ')
+		b.WriteString(name)
+		b.WriteString(" = ")
+		b.WriteString(strconv.Itoa(number))
+		b.WriteString(";\n")
+	}
+}
+
+// fieldType returns the type of the field with the 'optional' or 'repeated' label.
+func (g *generator) fieldType(t reflect.Type) (string, bool) {
+	switch t.Kind() {
+	case reflect.Ptr:
+		typ, ok := g.scalarType(t.Elem())
+		if ok && t.Elem().Kind() != reflect.Struct {
+			return "optional " + typ, true
+		}
+		return g.scalarType(t)
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "bytes", true
+		}
+		typ, ok := g.scalarType(t.Elem())
+		return "repeated " + typ, ok
+	case reflect.Map:
+		key, ok := g.scalarType(t.Key())
+		if !ok || !isMapKey(key) {
+			return "", false
+		}
+		value, ok := g.scalarType(t.Elem())
+		return "map<" + key + ", " + value + ">", ok
+	}
+	return g.scalarType(t)
+}
+
+// scalarType returns the type of the value, which is the scalar, the message or the well-known type.
+func (g *generator) scalarType(t reflect.Type) (string, bool) {
+	t = typeinfo.Deref(t)
+	if t == typeinfo.TimeType {
+		g.imports[timestampProto] = true
+		return timestampType, true
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string", true
+	case reflect.Bool:
+		return "bool", true
+	case reflect.Int, reflect.Int64:
+		return "int64", true
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return "int32", true
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return "uint64", true
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return "uint32", true
+	case reflect.Float32:
+		return "float", true
+	case reflect.Float64:
+		return "double", true
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "bytes", true
+		}
+	case reflect.Interface:
+		g.imports[anyProto] = true
+		return anyType, true
+	case reflect.Struct:
+		if g.enqueue(t) {
+			return typeinfo.Name(t), true
+		}
+	}
+	return "", false
+}
+
+// fieldNumberAndName returns the number and the name of the field, the number is 0 if not specified.
+func fieldNumberAndName(field reflect.StructField) (int, string) {
+	var number int
+	var name string
+	if s, ok := field.Tag.Lookup(tagProtobufField); ok {
+		number, _ = strconv.Atoi(strings.TrimSpace(s))
+	}
+	if s, ok := field.Tag.Lookup(tagProtobuf); ok {
+		a := strings.Split(s, ",")
+		if len(a) == 1 {
+			name = strings.TrimSpace(a[0])
+		} else if n, err := strconv.Atoi(strings.TrimSpace(a[1])); err == nil {
+			// protoc-gen-go style, such as `protobuf:"varint,1,opt,name=user_id,proto3"`
+			if number == 0 {
+				number = n
+			}
+			for _, opt := range a[2:] {
+				if strings.HasPrefix(opt, "name=") {
+					name = opt[len("name="):]
+				}
+			}
+		}
+	}
+	if s, ok := field.Tag.Lookup(tagProtoName); ok {
+		name = strings.TrimSpace(s)
+	}
+	if number <= 0 {
+		return 0, ""
+	}
+	if name == "" {
+		name = snakeCase(field.Name)
+	}
+	return number, name
+}
+
+func isMapKey(typ string) bool {
+	switch typ {
+	case "string", "bool", "int32", "int64", "uint32", "uint64":
+		return true
+	}
+	return false
+}
+
+// snakeCase returns the snake case of the Go field name, e.g. 'user_id' for 'UserID'.
+func snakeCase(s string) string {
+	rs := []rune(s)
+	var b strings.Builder
+	for i, r := range rs {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(rs[i-1]) || unicode.IsDigit(rs[i-1]) ||
+				(i+1 < len(rs) && unicode.IsLower(rs[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,71 @@
+package protodesc_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bytedance/go-tagexpr/binding/protodesc"
+	"github.com/stretchr/testify/assert"
+)
+
+type Address struct {
+	City string `protobuf_field:"1"`
+}
+
+type Base struct {
+	TraceID string `protobuf_field:"15"`
+}
+
+type User struct {
+	Base
+	ID       int64             `protobuf:"varint,1,opt,name=id,proto3"`
+	Name     string            `protobuf_field:"2" protobuf:"user_name"`
+	Email    *string           `protobuf_field:"3"`
+	Age      int32             `protobuf_field:"4" proto_name:"age_years"`
+	Score    float64           `protobuf_field:"5"`
+	Tags     []string          `protobuf_field:"6"`
+	Attrs    map[string]uint32 `protobuf_field:"7"`
+	Avatar   []byte            `protobuf_field:"8"`
+	Address  *Address          `protobuf_field:"9"`
+	Others   []Address         `protobuf_field:"10"`
+	Created  time.Time         `protobuf_field:"11"`
+	Extra    interface{}       `protobuf_field:"12"`
+	Events   chan int          `protobuf_field:"13"`
+	IsAdmin  bool              `protobuf_field:"14"`
+	Untagged string
+}
+
+func TestGenerateProto3(t *testing.T) {
+	assert.Equal(t, `syntax = "proto3";
+
+package api.v1;
+
+import "google/protobuf/any.proto";
+import "google/protobuf/timestamp.proto";
+
+message User {
+  string trace_id = 15;
+  int64 id = 1;
+  string user_name = 2;
+  optional string email = 3;
+  int32 age_years = 4;
+  double score = 5;
+  repeated string tags = 6;
+  map<string, uint32> attrs = 7;
+  bytes avatar = 8;
+  Address address = 9;
+  repeated Address others = 10;
+  google.protobuf.Timestamp created = 11;
+  google.protobuf.Any extra = 12;
+  // events: unsupported type chan int
+  bool is_admin = 14;
+}
+
+message Address {
+  string city = 1;
+}
+`, protodesc.GenerateProto3("api.v1", reflect.TypeOf(&User{}), reflect.TypeOf(1)))
+
+	assert.Equal(t, "syntax = \"proto3\";\n\nmessage Address {\n  string city = 1;\n}\n", protodesc.GenerateProto3("", reflect.TypeOf(Address{})))
+}
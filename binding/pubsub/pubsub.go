@@ -0,0 +1,71 @@
+// Package pubsub binds the Google Cloud Pub/Sub message,
+// without importing the GCP SDK.
+package pubsub
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/bytedance/go-tagexpr/binding"
+)
+
+const (
+	tagPubSub   = "pubsub"
+	tagPubSubID = "pubsub_id"
+
+	contentTypeAttribute = "Content-Type"
+)
+
+// ErrNilMessage the error returned when the message to bind is nil, which is binding.ErrNilMessage.
+var ErrNilMessage = binding.ErrNilMessage
+
+// PubSubMessage the Pub/Sub message,
+// the *pubsub.Message of cloud.google.com/go/pubsub can be adapted to it.
+type PubSubMessage interface {
+	ID() string
+	Data() []byte
+	Attributes() map[string]string
+}
+
+// BindPubSubMessage uses the default binding to bind the Pub/Sub message to the struct.
+// NOTE:
+//  The attributes are bound to the fields tagged `pubsub:"$name"`;
+//  The message ID is bound to the field tagged `pubsub_id:"true"`;
+//  The data is bound to the body fields by the 'Content-Type' attribute, the default is application/json.
+func BindPubSubMessage(msg PubSubMessage, structPointer interface{}) error {
+	return Bind(binding.Default(), msg, structPointer)
+}
+
+// Bind uses the binding @b to bind the Pub/Sub message to the struct.
+// NOTE:
+//  If b==nil, the default binding is used;
+//  If msg==nil, ErrNilMessage is returned.
+func Bind(b *binding.Binding, msg PubSubMessage, structPointer interface{}) error {
+	if msg == nil {
+		return ErrNilMessage
+	}
+	if b == nil {
+		b = binding.Default()
+	}
+	attrs := msg.Attributes()
+	var contentType string
+	for k, v := range attrs {
+		if strings.EqualFold(k, contentTypeAttribute) {
+			contentType = v
+			break
+		}
+	}
+	return b.BindMessage(structPointer, msg.Data(), contentType, func(field reflect.StructField) ([]byte, bool) {
+		if field.Tag.Get(tagPubSubID) == "true" {
+			return []byte(msg.ID()), true
+		}
+		if name, ok := field.Tag.Lookup(tagPubSub); ok {
+			if name == "" {
+				name = field.Name
+			}
+			v, ok := attrs[name]
+			return []byte(v), ok
+		}
+		return nil, false
+	})
+}
@@ -0,0 +1,49 @@
+package pubsub_test
+
+import (
+	"testing"
+
+	"github.com/bytedance/go-tagexpr/binding/pubsub"
+	"github.com/stretchr/testify/assert"
+)
+
+type message struct {
+	id    string
+	data  []byte
+	attrs map[string]string
+}
+
+func (m *message) ID() string                    { return m.id }
+func (m *message) Data() []byte                  { return m.data }
+func (m *message) Attributes() map[string]string { return m.attrs }
+
+func TestBindPubSubMessage(t *testing.T) {
+	type Recv struct {
+		ID      string   `pubsub_id:"true"`
+		TraceID string   `pubsub:"trace-id"`
+		Retry   int      `pubsub:"retry"`
+		Tags    []string `pubsub:"tags"`
+		A       string   `json:"a,required"`
+	}
+	msg := &message{
+		id:    "m-1",
+		data:  []byte(`{"a":"x"}`),
+		attrs: map[string]string{"trace-id": "abc", "retry": "2", "tags": `["p","q"]`},
+	}
+	recv := new(Recv)
+	err := pubsub.BindPubSubMessage(msg, recv)
+	assert.NoError(t, err)
+	assert.Equal(t, "m-1", recv.ID)
+	assert.Equal(t, "abc", recv.TraceID)
+	assert.Equal(t, 2, recv.Retry)
+	assert.Equal(t, []string{"p", "q"}, recv.Tags)
+	assert.Equal(t, "x", recv.A)
+
+	msg.attrs["content-type"] = "application/x-www-form-urlencoded"
+	msg.data = []byte("b=1")
+	err = pubsub.BindPubSubMessage(msg, new(Recv))
+	assert.EqualError(t, err, "binding a: missing required parameter")
+
+	err = pubsub.BindPubSubMessage(nil, new(Recv))
+	assert.Equal(t, pubsub.ErrNilMessage, err)
+}
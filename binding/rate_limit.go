@@ -0,0 +1,113 @@
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/go-tagexpr"
+	"github.com/henrylee2cn/goutil"
+)
+
+// tagRateLimit the tag of the rate limit hint, such as `rate_limit:"100/min"`,
+// on the key field, or on the blank field `_ struct{}` for the struct itself
+const tagRateLimit = "rate_limit"
+
+// RateLimit the rate limit specified by the 'rate_limit' tag, such as `rate_limit:"100/min"`.
+type RateLimit struct {
+	// Count the number of the requests allowed in the period
+	Count int
+	// Period the period of the limit, such as time.Minute
+	Period time.Duration
+}
+
+// String returns the rate limit in the tag format, such as '100/1m0s'.
+func (r RateLimit) String() string {
+	return strconv.Itoa(r.Count) + "/" + r.Period.String()
+}
+
+var rateLimitPeriods = map[string]time.Duration{
+	"s":      time.Second,
+	"sec":    time.Second,
+	"second": time.Second,
+	"m":      time.Minute,
+	"min":    time.Minute,
+	"minute": time.Minute,
+	"h":      time.Hour,
+	"hour":   time.Hour,
+	"d":      24 * time.Hour,
+	"day":    24 * time.Hour,
+}
+
+// parseRateLimit parses the rate limit, such as '100/min', '10/s' or '500/30m',
+// the period is the unit name or the duration string.
+func parseRateLimit(s string) (RateLimit, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexByte(s, '/')
+	if i < 0 {
+		return RateLimit{}, errors.New("missing '/'")
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(s[:i]))
+	if err != nil || count <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid count %q", s[:i])
+	}
+	unit := strings.TrimSpace(s[i+1:])
+	period, ok := rateLimitPeriods[unit]
+	if !ok {
+		if period, err = time.ParseDuration(unit); err != nil || period <= 0 {
+			return RateLimit{}, fmt.Errorf("invalid period %q", unit)
+		}
+	}
+	return RateLimit{Count: count, Period: period}, nil
+}
+
+// SetRateLimiter sets the hook which enforces the rate limits of the 'rate_limit' tags,
+// such as `rate_limit:"100/min"`, the binding does not limit the requests itself.
+// NOTE:
+//  The hook is called after the tagged field is bound, with the field selector and the bound value as the key,
+//  e.g. to limit the requests of each user by `query:"user_id" rate_limit:"100/min"`;
+//  The blank field `_ struct{}` tagged `rate_limit` is the limit of the struct itself,
+//  the hook is called after all the fields are bound, with the empty field and key;
+//  The error returned by fn aborts the binding and is returned as is, e.g. to respond 429 Too Many Requests;
+//  If fn is nil, the tags are ignored.
+func (b *Binding) SetRateLimiter(fn func(field, key string, limit RateLimit) error) *Binding {
+	return b.update(WithRateLimiter(fn))
+}
+
+// structRateLimit returns the rate limit of the struct itself,
+// which is specified by the blank field `_ struct{} rate_limit:"100/min"`.
+func structRateLimit(t reflect.Type) (*RateLimit, error) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Name != "_" {
+			continue
+		}
+		if s, ok := f.Tag.Lookup(tagRateLimit); ok {
+			limit, err := parseRateLimit(s)
+			if err != nil {
+				return nil, err
+			}
+			return &limit, nil
+		}
+	}
+	return nil, nil
+}
+
+// limitRate calls the rate limiter with the bound value of the field tagged `rate_limit`.
+func (b *Binding) limitRate(param *paramInfo, expr *tagexpr.TagExpr) error {
+	if b.rateLimiter == nil || param.rateLimit == nil {
+		return nil
+	}
+	var key string
+	v, err := param.getField(expr, false)
+	if err != nil {
+		return err
+	}
+	if v = goutil.DereferenceValue(v); v.IsValid() && v.CanInterface() {
+		key = fmt.Sprint(v.Interface())
+	}
+	return b.rateLimiter(param.fieldSelector, key, *param.rateLimit)
+}
@@ -5,11 +5,11 @@ import (
 	"net/http"
 	"net/url"
 	"reflect"
-	"strings"
 
 	"github.com/bytedance/go-tagexpr"
 	"github.com/bytedance/go-tagexpr/binding/jsonparam"
 	"github.com/gogo/protobuf/proto"
+	jwtpkg "github.com/golang-jwt/jwt/v4"
 	"github.com/henrylee2cn/goutil"
 	"github.com/tidwall/gjson"
 )
@@ -26,6 +26,9 @@ const (
 	protobuf
 	json
 	raw_body
+	jwt
+	apikey
+	basic_auth
 	maxIn
 )
 
@@ -56,11 +59,24 @@ const (
 )
 
 type receiver struct {
-	hasPath, hasQuery, hasBody, hasCookie, hasVd bool
+	hasPath, hasQuery, hasBody, hasCookie, hasHeader, hasJWT, hasVd bool
+
+	// needsSlowPath indicates that the receiver can not be bound by bindStringOnly, see initSlowPath
+	needsSlowPath bool
 
 	params []*paramInfo
 
 	looseZeroMode bool
+	// sanitizer the string sanitizer of the binding, see SetStringSanitizer
+	sanitizer func(field, value string) (string, error)
+
+	// rateLimit the rate limit of the struct itself, see structRateLimit
+	rateLimit *RateLimit
+	// rateLimited indicates that the struct or a field is tagged 'rate_limit'
+	rateLimited bool
+
+	// embeddedIfaces the selectors of the embedded interfaces, see bindEmbeddedIfaces
+	embeddedIfaces []string
 }
 
 func (r *receiver) assginIn(i in, v bool) {
@@ -73,6 +89,10 @@ func (r *receiver) assginIn(i in, v bool) {
 		r.hasBody = v
 	case cookie:
 		r.hasCookie = v
+	case header:
+		r.hasHeader = v
+	case jwt:
+		r.hasJWT = v
 	}
 }
 
@@ -85,6 +105,58 @@ func (r *receiver) getParam(fieldSelector string) *paramInfo {
 	return nil
 }
 
+// isRepeatedStruct returns whether the struct type of the field is also the type of the root or the parent fields,
+// i.e. the field closes a cycle, such as Next of `type Node struct { Next *Node }`.
+func isRepeatedStruct(root reflect.Type, fh *tagexpr.FieldHandler) bool {
+	t := goutil.DereferenceType(fh.StructField().Type)
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	if t == root {
+		return true
+	}
+	paths, _ := tagexpr.FieldSelector(fh.StringSelector()).Split()
+	parent := root
+	for _, name := range paths {
+		f, ok := parent.FieldByName(name)
+		if !ok {
+			return false
+		}
+		if parent = goutil.DereferenceType(f.Type); parent == t {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRequestParams returns whether the struct type or its nested structs have the fields
+// bound from the request parameters, such as the query and the header, instead of the body as a whole.
+func (b *Binding) hasRequestParams(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		for _, kv := range b.config.parse(f) {
+			switch kv.name {
+			case b.config.Validator, b.config.jsonBody, b.config.protobufBody, b.config.RawBody:
+			default:
+				if kv.value != "-" {
+					return true
+				}
+			}
+		}
+		if ft := goutil.DereferenceType(f.Type); ft.Kind() == reflect.Struct && b.hasRequestParams(ft, seen) {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *receiver) getOrAddParam(fh *tagexpr.FieldHandler, bindErrFactory func(failField, msg string) error) *paramInfo {
 	fieldSelector := fh.StringSelector()
 	p := r.getParam(fieldSelector)
@@ -97,16 +169,73 @@ func (r *receiver) getOrAddParam(fh *tagexpr.FieldHandler, bindErrFactory func(f
 		omitIns:        make(map[in]bool, maxIn),
 		bindErrFactory: bindErrFactory,
 		looseZeroMode:  r.looseZeroMode,
+		sanitizer:      r.sanitizer,
 	}
 	r.params = append(r.params, p)
 	return p
 }
 
-func (r *receiver) getBodyCodec(req *http.Request) codec {
-	ct := req.Header.Get("Content-Type")
-	idx := strings.Index(ct, ";")
-	if idx != -1 {
-		ct = strings.TrimRight(ct[:idx], " ")
+// requestCache caches the parameters parsed from the request,
+// so that the request is parsed only once when binding multiple structs.
+type requestCache struct {
+	req           *http.Request
+	bodyCodec     codec
+	bodyBytes     []byte
+	bodyString    string
+	queryValues   url.Values
+	cookies       []*http.Cookie
+	bodyRead      bool
+	queryParsed   bool
+	cookiesParsed bool
+	// headerSizeChecked indicates that headerSizeErr is set
+	headerSizeChecked bool
+	headerSizeErr     error
+	// sources the parameter sources to bind, nil means all
+	sources *[maxIn]bool
+	// bound records the bound fields, if not nil
+	bound *[]BoundField
+	// failed records the fields which failed to be bound and continues binding, if not nil
+	failed *[]fieldError
+	// transformedQueries the query values normalized by the 'key_transform' tags
+	transformedQueries map[string]url.Values
+	// jwtClaims the claims of the verified bearer token, see getJWTClaims
+	jwtClaims jwtpkg.MapClaims
+	jwtErr    error
+	jwtParsed bool
+	// apiKeyUser the user ID of the API key validated by the binding, see apiKeyUserOf
+	apiKeyUser    string
+	apiKeyErr     error
+	apiKeyFound   bool
+	apiKeyChecked bool
+	// ifaceBinding the struct pointers being bound, see bindEmbeddedIfaces
+	ifaceBinding []uintptr
+}
+
+// tracking returns whether the bound sources are filtered, or the bound or failed fields are recorded,
+// which are not supported by bindStringOnly.
+func (rc *requestCache) tracking() bool {
+	return rc.sources != nil || rc.bound != nil || rc.failed != nil
+}
+
+func newRequestCache(req *http.Request) *requestCache {
+	return &requestCache{
+		req:       req,
+		bodyCodec: getBodyCodec(req.Header.Get("Content-Type")),
+	}
+}
+
+// getBodyCodec returns the body codec of the content type.
+// NOTE:
+//  Parse by index arithmetic to avoid allocation.
+func getBodyCodec(ct string) codec {
+	for i := 0; i < len(ct); i++ {
+		if ct[i] == ';' {
+			ct = ct[:i]
+			break
+		}
+	}
+	for len(ct) > 0 && ct[len(ct)-1] == ' ' {
+		ct = ct[:len(ct)-1]
 	}
 	switch ct {
 	case "application/json":
@@ -120,25 +249,29 @@ func (r *receiver) getBodyCodec(req *http.Request) codec {
 	}
 }
 
-func (r *receiver) getBody(req *http.Request) ([]byte, string, error) {
+func (r *receiver) getBody(rc *requestCache) ([]byte, string, error) {
 	if r.hasBody {
-		switch req.Method {
-		case "POST", "PUT", "PATCH", "DELETE":
-			bodyBytes, err := copyBody(req)
-			if err == nil {
-				return bodyBytes, goutil.BytesToString(bodyBytes), nil
+		if !rc.bodyRead {
+			rc.bodyRead = true
+			switch rc.req.Method {
+			case "POST", "PUT", "PATCH", "DELETE":
+				bodyBytes, err := copyBody(rc.req)
+				rc.bodyBytes = bodyBytes
+				if err == nil {
+					rc.bodyString = goutil.BytesToString(bodyBytes)
+				}
 			}
-			return bodyBytes, "", nil
 		}
+		return rc.bodyBytes, rc.bodyString, nil
 	}
 	return nil, "", nil
 }
 
-func (r *receiver) prebindBody(structPointer interface{}, value reflect.Value, bodyCodec codec, bodyBytes []byte) error {
+func (r *receiver) prebindBody(structPointer interface{}, value reflect.Value, bodyCodec codec, bodyBytes []byte, jsonUnmarshal func(data []byte, v interface{}) error) error {
 	switch bodyCodec {
 	case bodyJSON:
-		if jsonUnmarshalFunc != nil {
-			return jsonUnmarshalFunc(bodyBytes, structPointer)
+		if jsonUnmarshal != nil {
+			return jsonUnmarshal(bodyBytes, structPointer)
 		}
 		jsonparam.Assign(gjson.Parse(goutil.BytesToString(bodyBytes)), value)
 	case bodyProtobuf:
@@ -154,31 +287,106 @@ func (r *receiver) prebindBody(structPointer interface{}, value reflect.Value, b
 }
 
 const (
-	defaultMaxMemory = 32 << 20 // 32 MB
+	defaultMaxMemory     = 32 << 20 // 32 MB
+	defaultMaxFormFields = 10000
+	defaultMaxHeaderSize = http.DefaultMaxHeaderBytes
 )
 
-func (r *receiver) getPostForm(req *http.Request, bodyCodec codec) (url.Values, error) {
-	if bodyCodec == bodyForm && (r.hasBody) {
-		if req.PostForm == nil {
-			req.ParseMultipartForm(defaultMaxMemory)
+// ErrTooManyFields the error returned when the number of the form fields exceeds the limit,
+// see SetMaxFormFields.
+var ErrTooManyFields = errors.New("binding: too many form fields")
+
+// ErrHeaderTooLarge the error returned when the total size of the header values exceeds the limit,
+// see SetMaxHeaderSize.
+var ErrHeaderTooLarge = errors.New("binding: header too large")
+
+func (r *receiver) getPostForm(rc *requestCache, maxFields int) (url.Values, error) {
+	if rc.bodyCodec == bodyForm && (r.hasBody) {
+		if rc.req.PostForm == nil {
+			rc.req.ParseMultipartForm(defaultMaxMemory)
 		}
-		return req.PostForm, nil
+		if maxFields > 0 && len(rc.req.PostForm) > maxFields {
+			return nil, ErrTooManyFields
+		}
+		return rc.req.PostForm, nil
 	}
 	return nil, nil
 }
 
-func (r *receiver) getQuery(req *http.Request) url.Values {
+func (r *receiver) getQuery(rc *requestCache) url.Values {
 	if r.hasQuery {
-		return req.URL.Query()
+		if !rc.queryParsed {
+			rc.queryParsed = true
+			rc.queryValues = rc.req.URL.Query()
+		}
+		return rc.queryValues
 	}
 	return nil
 }
 
-func (r *receiver) getCookies(req *http.Request) []*http.Cookie {
+func (r *receiver) getCookies(rc *requestCache, maxHeaderSize int64) ([]*http.Cookie, error) {
 	if r.hasCookie {
-		return req.Cookies()
+		if err := rc.checkHeaderSize(maxHeaderSize); err != nil {
+			return nil, err
+		}
+		if !rc.cookiesParsed {
+			rc.cookiesParsed = true
+			rc.cookies = rc.req.Cookies()
+		}
+		return rc.cookies, nil
 	}
-	return nil
+	return nil, nil
+}
+
+func (r *receiver) getHeaders(rc *requestCache, maxHeaderSize int64) (http.Header, error) {
+	if r.hasHeader {
+		if err := rc.checkHeaderSize(maxHeaderSize); err != nil {
+			return nil, err
+		}
+		return rc.req.Header, nil
+	}
+	return nil, nil
+}
+
+// checkHeaderSize returns ErrHeaderTooLarge if the total byte length of the header values exceeds the limit.
+func (rc *requestCache) checkHeaderSize(maxHeaderSize int64) error {
+	if maxHeaderSize <= 0 {
+		return nil
+	}
+	if !rc.headerSizeChecked {
+		rc.headerSizeChecked = true
+		var size int64
+		for _, values := range rc.req.Header {
+			for _, v := range values {
+				size += int64(len(v))
+			}
+		}
+		if size > maxHeaderSize {
+			rc.headerSizeErr = ErrHeaderTooLarge
+		}
+	}
+	return rc.headerSizeErr
+}
+
+// initSlowPath computes needsSlowPath once the receiver is prepared,
+// the fast path binds only the string fields from query or form, without the rate limits, the embedded interfaces and the logger.
+func (r *receiver) initSlowPath(logged bool) {
+	r.needsSlowPath = logged || r.rateLimited || len(r.embeddedIfaces) > 0 || !r.isStringOnly()
+}
+
+// isStringOnly returns whether all the fields are string types bound from query or form.
+func (r *receiver) isStringOnly() bool {
+	for _, p := range r.params {
+		if p.structField.Type.Kind() != reflect.String {
+			return false
+		}
+		for _, info := range p.tagInfos {
+			if info.paramIn != query && info.paramIn != form {
+				return false
+			}
+		}
+	}
+	return len(r.params) > 0
 }
 
 func (r *receiver) initParams() {
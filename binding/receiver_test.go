@@ -0,0 +1,60 @@
+package binding
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetBodyCodec(t *testing.T) {
+	for ct, expect := range map[string]codec{
+		"":                                  bodyUnsupport,
+		"application/json":                  bodyJSON,
+		"application/json;charset=utf-8":    bodyJSON,
+		"application/json ; charset=utf-8":  bodyJSON,
+		"application/x-protobuf":            bodyProtobuf,
+		"application/x-www-form-urlencoded": bodyForm,
+		"multipart/form-data; boundary=xyz": bodyForm,
+		"text/plain":                        bodyUnsupport,
+	} {
+		if got := getBodyCodec(ct); got != expect {
+			t.Fatalf("content type: %q, expect: %v, but got: %v", ct, expect, got)
+		}
+		if got := oldGetBodyCodec(ct); got != expect {
+			t.Fatalf("content type: %q, expect: %v, but got: %v", ct, expect, got)
+		}
+	}
+}
+
+func BenchmarkGetBodyCodec(b *testing.B) {
+	const ct = "application/json ; charset=utf-8"
+	b.Run("new", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			getBodyCodec(ct)
+		}
+	})
+	b.Run("old", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			oldGetBodyCodec(ct)
+		}
+	})
+}
+
+// oldGetBodyCodec the previous implementation, for comparison.
+func oldGetBodyCodec(ct string) codec {
+	idx := strings.Index(ct, ";")
+	if idx != -1 {
+		ct = strings.TrimRight(ct[:idx], " ")
+	}
+	switch ct {
+	case "application/json":
+		return bodyJSON
+	case "application/x-protobuf":
+		return bodyProtobuf
+	case "application/x-www-form-urlencoded", "multipart/form-data":
+		return bodyForm
+	default:
+		return bodyUnsupport
+	}
+}
@@ -0,0 +1,88 @@
+package binding
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/tidwall/gjson"
+)
+
+// BindResult the result of BindFull.
+type BindResult struct {
+	// BoundFields the fields bound from the request, in the order of the struct fields
+	BoundFields []BoundField
+}
+
+// BoundField the provenance of a bound field.
+type BoundField struct {
+	// Selector the field selector, such as 'A.B'
+	Selector string
+	// Source the source which satisfied the field
+	Source Source
+	// RawValue the first raw value from the source
+	RawValue string
+}
+
+// BindFull binds the request parameters, and returns the provenance of the bound fields.
+// NOTE:
+//  The result is returned even if the error is not nil,
+//  which contains the fields bound before the error.
+func (b *Binding) BindFull(structPointer interface{}, req *http.Request, pathParams PathParams) (*BindResult, error) {
+	result := new(BindResult)
+	rc := newRequestCache(req)
+	rc.bound = &result.BoundFields
+	_, _, err := b.bindRequest(structPointer, rc, b.pathParamsOf(req, pathParams))
+	return result, err
+}
+
+// boundRawValue returns the first raw value of the parameter from its source, and whether it exists.
+func boundRawValue(info *tagInfo, rc *requestCache, pathParams PathParams, queryValues, postForm url.Values, cookies []*http.Cookie, bodyString string) (string, bool) {
+	switch info.paramIn {
+	case path:
+		if pathParams == nil {
+			return "", false
+		}
+		return pathParams.Get(info.paramName)
+	case query:
+		return firstValue(queryValues, info.paramName)
+	case header:
+		return firstValue(rc.req.Header, info.paramName)
+	case form:
+		return firstValue(postForm, info.paramName)
+	case cookie:
+		for _, c := range cookies {
+			if c.Name == info.paramName {
+				return c.Value, true
+			}
+		}
+	case json:
+		if r := gjson.Get(bodyString, info.namePath); r.Exists() {
+			return r.String(), true
+		}
+	case protobuf:
+		return "", true
+	case raw_body:
+		return bodyString, bodyString != ""
+	case jwt:
+		if claim, ok := rc.jwtClaims[info.paramName]; ok && claim != nil {
+			return claimString(claim), true
+		}
+	case apikey:
+		// the user ID instead of the secret key
+		return rc.apiKeyUser, rc.apiKeyFound && rc.apiKeyErr == nil
+	case basic_auth:
+		if info.paramName == basicAuthPassword {
+			// the password is not recorded
+			return "", true
+		}
+		return basicAuthOf(info, rc)
+	}
+	return "", false
+}
+
+func firstValue(values map[string][]string, name string) (string, bool) {
+	if a := values[name]; len(a) > 0 {
+		return a[0], true
+	}
+	return "", false
+}
@@ -0,0 +1,126 @@
+package binding
+
+import (
+	"reflect"
+
+	"github.com/henrylee2cn/goutil"
+)
+
+// SetStringSanitizer sets the sanitizer which is called for every bound string field,
+// such as HTML escaping or SQL injection detection.
+// NOTE:
+//  @field is the field selector, such as 'A.B', and @value is the bound value;
+//  The returned string replaces the value, and the returned error rejects the binding;
+//  It is called before the value is assigned to the field, and before the validation;
+//  It is called for the string, *string and []string fields, including the named string types,
+//  and the string fields nested in the struct decoded from the JSON or protobuf body;
+//  If fn==nil, the values are not sanitized.
+func (b *Binding) SetStringSanitizer(fn func(field, value string) (string, error)) *Binding {
+	return b.update(WithStringSanitizer(fn))
+}
+
+// isStringType returns whether the type is the string, or the slice or array of strings,
+// including the pointers to them.
+func isStringType(t reflect.Type) bool {
+	t = goutil.DereferenceType(t)
+	switch t.Kind() {
+	case reflect.String:
+		return true
+	case reflect.Slice, reflect.Array:
+		return goutil.DereferenceType(t.Elem()).Kind() == reflect.String
+	}
+	return false
+}
+
+// sanitizeStrings applies the sanitizer to the values before they are bound to the string field,
+// without modifying the values of the request.
+func (p *paramInfo) sanitizeStrings(info *tagInfo, a []string) ([]string, error) {
+	if p.sanitizer == nil || !isStringType(p.structField.Type) {
+		return a, nil
+	}
+	if goutil.DereferenceType(p.structField.Type).Kind() == reflect.String {
+		// only the first value is bound
+		a = a[:1]
+	}
+	var r []string
+	for i, s := range a {
+		v, err := p.sanitizer(p.fieldSelector, s)
+		if err != nil {
+			return nil, p.bindErrFactory(info.namePath, err.Error())
+		}
+		if r == nil && v != s {
+			r = append(make([]string, 0, len(a)), a[:i]...)
+		}
+		if r != nil {
+			r = append(r, v)
+		}
+	}
+	if r == nil {
+		return a, nil
+	}
+	return r, nil
+}
+
+// sanitizeValue applies the sanitizer to the strings of the value decoded from JSON or protobuf,
+// such as the nested struct fields, before it is validated.
+func sanitizeValue(fn func(field, value string) (string, error), errFactory func(failField, msg string) error, v reflect.Value, selector string) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return sanitizeValue(fn, errFactory, v.Elem(), selector)
+	case reflect.String:
+		if !v.CanSet() {
+			return nil
+		}
+		s, err := fn(selector, v.String())
+		if err != nil {
+			return errFactory(selector, err.Error())
+		}
+		if s != v.String() {
+			v.SetString(s)
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := sanitizeValue(fn, errFactory, v.Index(i), selector); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		// the map values are not addressable, so only the string values are replaced
+		if v.Type().Elem().Kind() != reflect.String {
+			return nil
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			old := iter.Value().String()
+			s, err := fn(selector, old)
+			if err != nil {
+				return errFactory(selector, err.Error())
+			}
+			if s != old {
+				v.SetMapIndex(iter.Key(), reflect.ValueOf(s).Convert(v.Type().Elem()))
+			}
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" && !f.Anonymous {
+				continue
+			}
+			fieldSelector := f.Name
+			if selector != "" {
+				fieldSelector = selector + "." + f.Name
+			}
+			if err := sanitizeValue(fn, errFactory, v.Field(i), fieldSelector); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
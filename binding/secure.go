@@ -0,0 +1,54 @@
+package binding
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrInsecureRequest the error returned by BindSecure when the request is not over TLS,
+// see SetTrustedProxyHeaders.
+var ErrInsecureRequest = errors.New("binding: insecure request")
+
+// defaultTrustedProxyHeaders the headers of the original protocol set by the TLS-terminating proxy
+var defaultTrustedProxyHeaders = []string{"X-Forwarded-Proto"}
+
+// SetTrustedProxyHeaders sets the headers of the original protocol set by the trusted TLS-terminating proxy,
+// the request is secure for BindSecure if one of them is 'https'.
+// NOTE:
+//  The default is X-Forwarded-Proto;
+//  The first of the comma-separated values is the protocol of the client, such as 'https, http';
+//  If no headers, only the request with TLS is secure, e.g. when the server is not behind a proxy.
+func (b *Binding) SetTrustedProxyHeaders(headers ...string) *Binding {
+	return b.update(WithTrustedProxyHeaders(headers...))
+}
+
+// BindSecure binds the request parameters like Bind,
+// but returns ErrInsecureRequest without binding if the request is not over TLS,
+// e.g. to ensure the sensitive form data is only processed over HTTPS.
+// NOTE:
+//  The request is secure if req.TLS!=nil, or one of the trusted proxy headers is 'https';
+//  The path parameters are decoded by the PathParamsDecoder of the binding.
+func (b *Binding) BindSecure(req *http.Request, structPointer interface{}) error {
+	if !b.isSecure(req) {
+		return ErrInsecureRequest
+	}
+	return b.Bind(structPointer, req, nil)
+}
+
+// isSecure returns whether the request is over TLS, directly or by the trusted proxy.
+func (b *Binding) isSecure(req *http.Request) bool {
+	if req.TLS != nil {
+		return true
+	}
+	for _, header := range b.trustedProxyHeaders {
+		proto := req.Header.Get(header)
+		if i := strings.IndexByte(proto, ','); i >= 0 {
+			proto = proto[:i]
+		}
+		if strings.EqualFold(strings.TrimSpace(proto), "https") {
+			return true
+		}
+	}
+	return false
+}
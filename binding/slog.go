@@ -0,0 +1,68 @@
+//go:build go1.21
+// +build go1.21
+
+package binding
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SetLogger sets the structured logger, which logs each bound field at DEBUG level
+// with the field selector, the source and the raw value, and the warnings of the bound fields at WARN level.
+// NOTE:
+//  The warnings are the deprecated field tagged `deprecated:"message"`,
+//  and the sensitive field bound to the zero value in loose zero mode;
+//  The sensitive field is the raw body, the basic auth, the encrypted cookie,
+//  or the parameter whose name contains such as 'authorization', 'api-key', 'token', 'secret' and 'password';
+//  The raw value of the sensitive field is redacted as "[REDACTED]";
+//  If logger is nil, nothing is logged.
+func (b *Binding) SetLogger(logger *slog.Logger) *Binding {
+	return b.update(WithLogger(logger))
+}
+
+// SetLogger sets the structured logger, which logs each bound field at DEBUG level,
+// and the warnings of the bound fields at WARN level.
+// NOTE:
+//  If logger is nil, nothing is logged.
+func SetLogger(logger *slog.Logger) {
+	defaultBinding.SetLogger(logger)
+}
+
+// WithLogger sets the structured logger, see SetLogger.
+func WithLogger(logger *slog.Logger) Option {
+	return Option{apply: func(o *options) {
+		if logger == nil {
+			o.logger = nil
+		} else {
+			o.logger = slogLogger{logger}
+		}
+	}}
+}
+
+// slogLogger the bindLogger of *slog.Logger
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+func (l slogLogger) bound(ctx context.Context, field BoundField) {
+	if !l.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	l.logger.LogAttrs(ctx, slog.LevelDebug, "binding: field is bound",
+		slog.String("field", field.Selector),
+		slog.String("source", field.Source.String()),
+		slog.String("raw_value", field.RawValue),
+	)
+}
+
+func (l slogLogger) warn(ctx context.Context, msg string, field BoundField, note string) {
+	attrs := []slog.Attr{
+		slog.String("field", field.Selector),
+		slog.String("source", field.Source.String()),
+	}
+	if note != "" {
+		attrs = append(attrs, slog.String("note", note))
+	}
+	l.logger.LogAttrs(ctx, slog.LevelWarn, msg, attrs...)
+}
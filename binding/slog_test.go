@@ -0,0 +1,76 @@
+//go:build go1.21
+// +build go1.21
+
+package binding_test
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bytedance/go-tagexpr/binding"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetLogger(t *testing.T) {
+	type Recv struct {
+		ID     int    `query:"id"`
+		Page   int    `query:"page" deprecated:"use cursor instead"`
+		Auth   string `header:"Authorization"`
+		Token  string `header:"X-Token"`
+		Region string `header:"X-Region,required"`
+		Name   string `query:"name"`
+	}
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	binder := binding.NewBinding(binding.WithLooseZeroMode(), binding.WithLogger(logger))
+	header := make(http.Header)
+	header.Set("Authorization", "Bearer secret")
+	header.Set("X-Token", "")
+	header.Set("X-Region", "")
+	req := newRequest("http://localhost/?id=1&page=2", header, nil, nil)
+	recv := new(Recv)
+	assert.NoError(t, binder.Bind(recv, req, nil))
+	assert.Equal(t, &Recv{ID: 1, Page: 2, Auth: "Bearer secret"}, recv)
+	assert.Equal(t, []string{
+		`level=DEBUG msg="binding: field is bound" field=ID source=query raw_value=1`,
+		`level=DEBUG msg="binding: field is bound" field=Page source=query raw_value=2`,
+		`level=WARN msg="binding: deprecated field is bound" field=Page source=query note="use cursor instead"`,
+		`level=DEBUG msg="binding: field is bound" field=Auth source=header raw_value=[REDACTED]`,
+		`level=DEBUG msg="binding: field is bound" field=Token source=header raw_value=""`,
+		`level=WARN msg="binding: sensitive field is bound to the zero value in loose zero mode" field=Token source=header`,
+		`level=DEBUG msg="binding: field is bound" field=Region source=header raw_value=""`,
+	}, strings.Split(strings.TrimSpace(buf.String()), "\n"))
+	assert.NotContains(t, buf.String(), "secret")
+
+	// the debug records are disabled by the level
+	buf.Reset()
+	binder.SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+	assert.NoError(t, binder.Bind(new(Recv), req, nil))
+	assert.Equal(t, 2, strings.Count(buf.String(), "level=WARN"))
+	assert.NotContains(t, buf.String(), "level=DEBUG")
+
+	buf.Reset()
+	binder.SetLogger(nil)
+	assert.NoError(t, binder.Bind(new(Recv), req, nil))
+	assert.Empty(t, buf.String())
+
+	// the string only struct prepared without the logger is logged after it is set
+	type Query struct {
+		Name string `query:"name"`
+	}
+	assert.NoError(t, binder.Bind(new(Query), req, nil))
+	binder.SetLogger(logger)
+	assert.NoError(t, binder.Bind(new(Query), newRequest("http://localhost/?name=a", nil, nil, nil), nil))
+	assert.Equal(t, `level=DEBUG msg="binding: field is bound" field=Name source=query raw_value=a`, strings.TrimSpace(buf.String()))
+}
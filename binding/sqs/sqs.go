@@ -0,0 +1,79 @@
+// Package sqs binds the AWS SQS message of github.com/aws/aws-sdk-go-v2/service/sqs.
+package sqs
+
+import (
+	"reflect"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/bytedance/go-tagexpr/binding"
+)
+
+const (
+	tagSQS   = "sqs"
+	tagSQSID = "sqs_id"
+
+	contentTypeAttribute = "Content-Type"
+)
+
+// ErrNilMessage the error returned when the message to bind is nil, which is binding.ErrNilMessage.
+var ErrNilMessage = binding.ErrNilMessage
+
+// BindSQS uses the default binding to bind the SQS message to the struct.
+// NOTE:
+//  The message attributes are bound to the fields tagged `sqs:"$name"`;
+//  The message ID is bound to the field tagged `sqs_id:"true"`;
+//  The body is bound to the body fields by the 'Content-Type' message attribute, the default is application/json.
+func BindSQS(msg *types.Message, structPointer interface{}) error {
+	return Bind(binding.Default(), msg, structPointer)
+}
+
+// Bind uses the binding @b to bind the SQS message to the struct.
+// NOTE:
+//  If b==nil, the default binding is used;
+//  If msg==nil, ErrNilMessage is returned.
+func Bind(b *binding.Binding, msg *types.Message, structPointer interface{}) error {
+	if msg == nil {
+		return ErrNilMessage
+	}
+	if b == nil {
+		b = binding.Default()
+	}
+	var body []byte
+	if msg.Body != nil {
+		body = []byte(*msg.Body)
+	}
+	var contentType string
+	if data, ok := attributeOf(msg, contentTypeAttribute); ok {
+		contentType = string(data)
+	}
+	return b.BindMessage(structPointer, body, contentType, func(field reflect.StructField) ([]byte, bool) {
+		if field.Tag.Get(tagSQSID) == "true" {
+			if msg.MessageId == nil {
+				return nil, false
+			}
+			return []byte(*msg.MessageId), true
+		}
+		if name, ok := field.Tag.Lookup(tagSQS); ok {
+			if name == "" {
+				name = field.Name
+			}
+			return attributeOf(msg, name)
+		}
+		return nil, false
+	})
+}
+
+// attributeOf returns the string or binary value of the message attribute.
+func attributeOf(msg *types.Message, name string) ([]byte, bool) {
+	attr, ok := msg.MessageAttributes[name]
+	if !ok {
+		return nil, false
+	}
+	if attr.StringValue != nil {
+		return []byte(*attr.StringValue), true
+	}
+	if attr.BinaryValue != nil {
+		return attr.BinaryValue, true
+	}
+	return nil, false
+}
@@ -0,0 +1,46 @@
+package sqs_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	bindsqs "github.com/bytedance/go-tagexpr/binding/sqs"
+	"github.com/stretchr/testify/assert"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestBindSQS(t *testing.T) {
+	type Recv struct {
+		ID      string `sqs_id:"true"`
+		TraceID string `sqs:"trace-id"`
+		Retry   *int   `sqs:"retry"`
+		Raw     []byte `sqs:"raw"`
+		A       string `json:"a,required"`
+	}
+	msg := &types.Message{
+		MessageId: strPtr("m-1"),
+		Body:      strPtr(`{"a":"x"}`),
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			"trace-id": {DataType: strPtr("String"), StringValue: strPtr("abc")},
+			"retry":    {DataType: strPtr("Number"), StringValue: strPtr("2")},
+			"raw":      {DataType: strPtr("Binary"), BinaryValue: []byte{1, 2}},
+		},
+	}
+	recv := new(Recv)
+	err := bindsqs.BindSQS(msg, recv)
+	assert.NoError(t, err)
+	assert.Equal(t, "m-1", recv.ID)
+	assert.Equal(t, "abc", recv.TraceID)
+	assert.Equal(t, 2, *recv.Retry)
+	assert.Equal(t, []byte{1, 2}, recv.Raw)
+	assert.Equal(t, "x", recv.A)
+
+	msg.MessageAttributes["Content-Type"] = types.MessageAttributeValue{DataType: strPtr("String"), StringValue: strPtr("application/x-www-form-urlencoded")}
+	msg.Body = strPtr("b=1")
+	err = bindsqs.BindSQS(msg, new(Recv))
+	assert.EqualError(t, err, "binding a: missing required parameter")
+
+	err = bindsqs.BindSQS(nil, new(Recv))
+	assert.Equal(t, bindsqs.ErrNilMessage, err)
+}
@@ -0,0 +1,52 @@
+package binding
+
+import "strconv"
+
+const tagSSE = "sse"
+
+// SSEEvent the event of Server-Sent Events
+type SSEEvent struct {
+	Event string
+	Data  string
+	ID    string
+	Retry int
+}
+
+// BindSSE binds the Server-Sent Event to the struct.
+// NOTE:
+//  The fields are tagged by `sse:"event"`, `sse:"data"`, `sse:"id"` or `sse:"retry"`;
+//  The data is parsed as JSON if it looks like JSON and the field is not a string.
+func (b *Binding) BindSSE(event *SSEEvent, structPointer interface{}) error {
+	if event == nil {
+		return b.bindErrFactory("", "event must be non-nil")
+	}
+	value, err := b.structValueOf(structPointer)
+	if err != nil {
+		return err
+	}
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		var s string
+		switch name := field.Tag.Get(tagSSE); name {
+		case "event":
+			s = event.Event
+		case "data":
+			s = event.Data
+		case "id":
+			s = event.ID
+		case "retry":
+			s = strconv.Itoa(event.Retry)
+		default:
+			continue
+		}
+		fv := value.Field(i)
+		if !fv.CanSet() {
+			return b.bindErrFactory(field.Name, "field cannot be set: "+field.Name)
+		}
+		if err = b.setMessageField(fv, s); err != nil {
+			return b.bindErrFactory(field.Name, "parameter type does not match binding data")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+//go:build go1.22
+// +build go1.22
+
+// Package stdmux binds the path parameters matched by the net/http ServeMux patterns of Go 1.22+.
+package stdmux
+
+import (
+	"net/http"
+
+	"github.com/bytedance/go-tagexpr/binding"
+)
+
+// SetStdMuxPathDecoder makes the binding read path parameters by http.Request.PathValue.
+// NOTE:
+//  If b==nil, the default binding is set;
+//  The pathParams argument of binding should be nil.
+func SetStdMuxPathDecoder(b *binding.Binding) {
+	if b == nil {
+		b = binding.Default()
+	}
+	b.SetPathParamsDecoder(NewPathParams)
+}
+
+// NewPathParams returns the path parameters matched by the ServeMux pattern of the request.
+func NewPathParams(req *http.Request) binding.PathParams {
+	return pathParams{req: req}
+}
+
+type pathParams struct {
+	req *http.Request
+}
+
+// Get returns the value of the named path wildcard.
+// NOTE:
+//  An empty value is regarded as not found.
+func (p pathParams) Get(name string) (string, bool) {
+	v := p.req.PathValue(name)
+	return v, v != ""
+}
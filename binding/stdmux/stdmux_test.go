@@ -0,0 +1,43 @@
+//go:build go1.22
+// +build go1.22
+
+package stdmux_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bytedance/go-tagexpr/binding"
+	"github.com/bytedance/go-tagexpr/binding/stdmux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetStdMuxPathDecoder(t *testing.T) {
+	type Recv struct {
+		ID   int    `path:"id"`
+		Name string `path:"name,required"`
+		Rest string `path:"rest"`
+	}
+	b := binding.New(nil)
+	stdmux.SetStdMuxPathDecoder(b)
+
+	var recv Recv
+	var err error
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}/{name}/{rest...}", func(w http.ResponseWriter, r *http.Request) {
+		err = b.Bind(&recv, r, nil)
+	})
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		err = b.Bind(&recv, r, nil)
+	})
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/7/henry/a/b", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 7, recv.ID)
+	assert.Equal(t, "henry", recv.Name)
+	assert.Equal(t, "a/b", recv.Rest)
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/8", nil))
+	assert.EqualError(t, err, "binding Name: missing required parameter")
+}
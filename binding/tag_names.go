@@ -20,6 +20,10 @@ const (
 	defaultTagValidator = "vd"
 	tagProtobuf         = "protobuf"
 	tagJSON             = "json"
+	tagJWT              = "jwt"
+	tagAPIKey           = "apikey"
+	tagBasicAuth        = "basic_auth"
+	tagSizeHint         = "size_hint"
 )
 
 // Config the struct tag naming and so on
@@ -46,6 +50,12 @@ type Config struct {
 	protobufBody string
 	// jsonBody use 'json' by default when empty
 	jsonBody string
+	// jwtClaim use 'jwt' by default when empty
+	jwtClaim string
+	// apiKey use 'apikey' by default when empty
+	apiKey string
+	// basicAuth use 'basic_auth' by default when empty
+	basicAuth string
 
 	list []string
 }
@@ -61,6 +71,9 @@ func (t *Config) init() {
 		goutil.InitAndGetString(&t.Validator, defaultTagValidator),
 		goutil.InitAndGetString(&t.protobufBody, tagProtobuf),
 		goutil.InitAndGetString(&t.jsonBody, tagJSON),
+		goutil.InitAndGetString(&t.jwtClaim, tagJWT),
+		goutil.InitAndGetString(&t.apiKey, tagAPIKey),
+		goutil.InitAndGetString(&t.basicAuth, tagBasicAuth),
 	}
 }
 
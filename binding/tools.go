@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"errors"
 	"io/ioutil"
+	"math/big"
 	"net/http"
 	"reflect"
+	"strconv"
+	"strings"
 
 	"github.com/henrylee2cn/goutil"
 )
@@ -45,7 +48,83 @@ func evalString(eval func() interface{}) (val string, errStr string) {
 
 var errMismatch = errors.New("type mismatch")
 
-func stringsToValue(t reflect.Type, a []string, emptyAsZero bool) (reflect.Value, error) {
+var (
+	bigIntType   = reflect.TypeOf(big.Int{})
+	bigFloatType = reflect.TypeOf(big.Float{})
+	bigRatType   = reflect.TypeOf(big.Rat{})
+)
+
+// setBigNumber sets the big.Int, big.Float or big.Rat value by the string without loss,
+// and returns whether the value is of these types.
+// NOTE:
+//  The big.Int is decimal, or hexadecimal with the '0x' prefix;
+//  The big.Float is parsed with enough precision for the digits of the string;
+//  The big.Rat can be a fraction such as '1/3', or a decimal.
+func setBigNumber(v reflect.Value, s string, emptyAsZero bool) (isBig bool, err error) {
+	if !v.CanAddr() {
+		return false, nil
+	}
+	var ok bool
+	switch x := v.Addr().Interface().(type) {
+	case *big.Int:
+		if s == "" && emptyAsZero {
+			x.SetInt64(0)
+			return true, nil
+		}
+		ok = setBigInt(x, s)
+	case *big.Float:
+		if s == "" && emptyAsZero {
+			x.SetInt64(0)
+			return true, nil
+		}
+		// about 3.32 bits per decimal digit
+		prec := uint(len(s)) * 4
+		if prec < 64 {
+			prec = 64
+		}
+		_, ok = x.SetPrec(prec).SetString(s)
+	case *big.Rat:
+		if s == "" && emptyAsZero {
+			x.SetInt64(0)
+			return true, nil
+		}
+		_, ok = x.SetString(s)
+	default:
+		return false, nil
+	}
+	if !ok {
+		return true, errMismatch
+	}
+	return true, nil
+}
+
+// setBigInt sets the decimal or '0x' prefixed hexadecimal string to the big.Int.
+func setBigInt(x *big.Int, s string) bool {
+	digits := strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+	base := 10
+	if strings.HasPrefix(digits, "0x") || strings.HasPrefix(digits, "0X") {
+		digits, base = digits[2:], 16
+	}
+	// the signs and underscores in the digits are invalid
+	if digits == "" || strings.ContainsAny(digits, "+-_") {
+		return false
+	}
+	if _, ok := x.SetString(digits, base); !ok {
+		return false
+	}
+	if s[0] == '-' {
+		x.Neg(x)
+	}
+	return true
+}
+
+// stringsToValue converts the strings to the slice of type t,
+// whose capacity is at least capHint.
+func stringsToValue(t reflect.Type, a []string, emptyAsZero bool, capHint int) (reflect.Value, error) {
+	if capHint > len(a) {
+		// the slice is allocated once with the capacity, instead of copying the converted one
+		return stringsToSizedValue(t, a, emptyAsZero, capHint)
+	}
 	var i interface{}
 	var err error
 	var ptrDepth int
@@ -87,7 +166,16 @@ func stringsToValue(t reflect.Type, a []string, emptyAsZero bool) (reflect.Value
 	default:
 		fn := typeUnmarshalFuncs[t]
 		if fn == nil {
-			return reflect.Value{}, errMismatch
+			if t != bigIntType && t != bigFloatType && t != bigRatType {
+				return reflect.Value{}, errMismatch
+			}
+			v := reflect.MakeSlice(reflect.SliceOf(t), len(a), len(a))
+			for k, s := range a {
+				if _, err := setBigNumber(v.Index(k), s, emptyAsZero); err != nil {
+					return reflect.Value{}, errMismatch
+				}
+			}
+			return goutil.ReferenceSlice(v, ptrDepth), nil
 		}
 		v := reflect.New(reflect.SliceOf(t)).Elem()
 		for _, s := range a {
@@ -104,3 +192,73 @@ func stringsToValue(t reflect.Type, a []string, emptyAsZero bool) (reflect.Value
 	}
 	return goutil.ReferenceSlice(reflect.ValueOf(i), ptrDepth), nil
 }
+
+// stringsToSizedValue converts the strings to the slice of type t with the capacity capHint,
+// and sets the elements in place.
+func stringsToSizedValue(t reflect.Type, a []string, emptyAsZero bool, capHint int) (reflect.Value, error) {
+	v := reflect.MakeSlice(reflect.SliceOf(t), len(a), capHint)
+	for k, s := range a {
+		elem := v.Index(k)
+		for elem.Kind() == reflect.Ptr {
+			elem.Set(reflect.New(elem.Type().Elem()))
+			elem = elem.Elem()
+		}
+		if err := setStringValue(elem, s, emptyAsZero); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	return v, nil
+}
+
+// setStringValue converts the string to the value of the basic type,
+// the type registered by RegTypeUnmarshal, or the big number type.
+func setStringValue(v reflect.Value, s string, emptyAsZero bool) error {
+	if s == "" && emptyAsZero {
+		switch v.Kind() {
+		case reflect.Bool, reflect.Float32, reflect.Float64,
+			reflect.Int, reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8,
+			reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8:
+			return nil
+		}
+	}
+	var err error
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(s); err == nil {
+			v.SetBool(b)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(s, v.Type().Bits()); err == nil {
+			v.SetFloat(f)
+		}
+	case reflect.Int, reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8:
+		var i int64
+		if i, err = strconv.ParseInt(s, 10, v.Type().Bits()); err == nil {
+			v.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8:
+		var u uint64
+		if u, err = strconv.ParseUint(s, 10, v.Type().Bits()); err == nil {
+			v.SetUint(u)
+		}
+	default:
+		if fn := typeUnmarshalFuncs[v.Type()]; fn != nil {
+			var vv reflect.Value
+			if vv, err = fn(s, emptyAsZero); err == nil {
+				v.Set(vv)
+			}
+		} else if isBig, bigErr := setBigNumber(v, s, emptyAsZero); isBig {
+			err = bigErr
+		} else {
+			return errMismatch
+		}
+	}
+	if err != nil {
+		return errMismatch
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package binding
+
+import (
+	"context"
+	"net/http"
+)
+
+// bindTracer the tracer of the binding operations, see SetTracerProvider.
+type bindTracer interface {
+	// start starts the span of binding the request to the struct,
+	// and returns the context of the span derived from ctx
+	start(ctx context.Context, req *http.Request, structPointer interface{}) (context.Context, bindSpan)
+}
+
+// bindSpan the span of binding the request to the struct.
+type bindSpan interface {
+	// validated records whether the validation passed
+	validated(passed bool)
+	// end ends the span with the error of the binding or the validation
+	end(err error)
+}
+
+// startSpan starts the span of binding the request to the struct,
+// and returns the request with the context of the span,
+// so that the downstream code, such as the logger and the validator functions, is attached to the span.
+func (b *Binding) startSpan(ctx context.Context, req *http.Request, structPointer interface{}) (*http.Request, bindSpan) {
+	ctx, span := b.tracer.start(ctx, req, structPointer)
+	return req.WithContext(ctx), span
+}
+
+// endSpan ends the span of the traced request derived from req,
+// and keeps the body restored after reading, so that req can still be bound later.
+func endSpan(span bindSpan, req, traced *http.Request, err error) {
+	req.Body = traced.Body
+	span.end(err)
+}
@@ -0,0 +1,180 @@
+// Package tsgen generates the TypeScript interface definitions of the JSON-bound shape of the structs,
+// e.g. for the front-end client code of the API.
+package tsgen
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/bytedance/go-tagexpr/binding/internal/typeinfo"
+)
+
+// GenerateTS returns the TypeScript interface definitions of the struct types,
+// and of the named struct types referenced by their fields.
+// NOTE:
+//  The pointer to struct type is dereferenced, and the other types are ignored;
+//  The property is named by the 'json' tag, the field tagged `json:"-"` is omitted;
+//  The property is optional (?) unless the 'json' tag has the 'required' or 'req' option, such as `json:"id,required"`;
+//  The fields of the untagged embedded struct are promoted, like encoding/json;
+//  The definitions are in the order of the types, followed by the referenced types in the order of discovery.
+func GenerateTS(types ...reflect.Type) string {
+	g := &generator{seen: make(map[reflect.Type]bool, len(types))}
+	for _, t := range types {
+		g.enqueue(t)
+	}
+	var b strings.Builder
+	for i := 0; i < len(g.queue); i++ {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		t := g.queue[i]
+		b.WriteString("export interface ")
+		b.WriteString(typeName(t))
+		b.WriteString(" {\n")
+		g.writeFields(&b, t, "  ")
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+type generator struct {
+	queue []reflect.Type
+	seen  map[reflect.Type]bool
+}
+
+// enqueue adds the named struct type to be defined, and reports whether it is a named struct type.
+func (g *generator) enqueue(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t.Name() == "" {
+		return false
+	}
+	if !g.seen[t] {
+		g.seen[t] = true
+		g.queue = append(g.queue, t)
+	}
+	return true
+}
+
+// writeFields writes the properties of the struct, including the promoted ones.
+func (g *generator) writeFields(b *strings.Builder, t reflect.Type, indent string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, tagged := field.Tag.Lookup("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := typeinfo.ParseJSONTag(tag)
+		ft := field.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if field.Anonymous && !tagged && ft.Kind() == reflect.Struct {
+			g.writeFields(b, ft, indent)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		b.WriteString(indent)
+		b.WriteString(propertyName(name))
+		if !opts["required"] && !opts["req"] {
+			b.WriteByte('?')
+		}
+		b.WriteString(": ")
+		if opts["string"] {
+			b.WriteString("string")
+		} else {
+			b.WriteString(g.tsType(field.Type, indent))
+		}
+		b.WriteString(";\n")
+	}
+}
+
+// tsType returns the TypeScript type of the Go type.
+func (g *generator) tsType(t reflect.Type, indent string) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == typeinfo.TimeType:
+		return "string"
+	case t == typeinfo.RawMessageType:
+		return "any"
+	case t.Implements(typeinfo.JSONMarshalerType) || reflect.PtrTo(t).Implements(typeinfo.JSONMarshalerType):
+		return "any"
+	case t.Implements(typeinfo.TextMarshalerType) || reflect.PtrTo(t).Implements(typeinfo.TextMarshalerType):
+		return "string"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			// base64 encoded by encoding/json
+			return "string"
+		}
+		elem := g.tsType(t.Elem(), indent)
+		if strings.ContainsAny(elem, " |") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]"
+	case reflect.Map:
+		key := "string"
+		switch t.Key().Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			key = "number"
+		}
+		return "Record<" + key + ", " + g.tsType(t.Elem(), indent) + ">"
+	case reflect.Struct:
+		if g.enqueue(t) {
+			return typeName(t)
+		}
+		// the anonymous struct is inlined
+		var b strings.Builder
+		b.WriteString("{\n")
+		g.writeFields(&b, t, indent+"  ")
+		b.WriteString(indent)
+		b.WriteByte('}')
+		return b.String()
+	}
+	return "any"
+}
+
+// typeName returns the TypeScript identifier of the named type,
+// e.g. 'Page_User' for the instantiated generic type 'Page[pkg.User]'.
+func typeName(t reflect.Type) string {
+	return strings.Map(func(r rune) rune {
+		if isIdentRune(r) {
+			return r
+		}
+		return '_'
+	}, typeinfo.Name(t))
+}
+
+// propertyName returns the property name, which is quoted if it is not an identifier.
+func propertyName(name string) string {
+	for i, r := range name {
+		if !isIdentRune(r) || (i == 0 && r >= '0' && r <= '9') {
+			b, _ := json.Marshal(name)
+			return string(b)
+		}
+	}
+	return name
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || r == '$' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
@@ -0,0 +1,79 @@
+package tsgen_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bytedance/go-tagexpr/binding/tsgen"
+	"github.com/stretchr/testify/assert"
+)
+
+type Base struct {
+	ID int64 `json:"id,required"`
+}
+
+type Address struct {
+	City string `json:"city,required"`
+	Zip  string `json:"zip"`
+}
+
+type User struct {
+	Base
+	Name      string            `json:"name,required" query:"name"`
+	Email     *string           `json:"email"`
+	Admin     bool              `json:"is_admin"`
+	Tags      []string          `json:"tags"`
+	Scores    map[string]int    `json:"scores"`
+	Address   *Address          `json:"address"`
+	Others    []Address         `json:"others"`
+	Created   time.Time         `json:"created_at"`
+	Avatar    []byte            `json:"avatar"`
+	Count     int               `json:"count,string"`
+	Extra     interface{}       `json:"extra"`
+	Meta      struct{ A int }   `json:"meta"`
+	Headers   map[int][]float64 `json:"headers"`
+	Dash      string            `json:"x-dash"`
+	Untagged  uint8
+	Ignored   string `json:"-"`
+	unexposed string
+}
+
+func TestGenerateTS(t *testing.T) {
+	assert.Equal(t, `export interface User {
+  id: number;
+  name: string;
+  email?: string;
+  is_admin?: boolean;
+  tags?: string[];
+  scores?: Record<string, number>;
+  address?: Address;
+  others?: Address[];
+  created_at?: string;
+  avatar?: string;
+  count?: string;
+  extra?: any;
+  meta?: {
+    A?: number;
+  };
+  headers?: Record<number, number[]>;
+  "x-dash"?: string;
+  Untagged?: number;
+}
+
+export interface Address {
+  city: string;
+  zip?: string;
+}
+`, tsgen.GenerateTS(reflect.TypeOf(&User{})))
+
+	assert.Equal(t, `export interface Address {
+  city: string;
+  zip?: string;
+}
+
+export interface Base {
+  id: number;
+}
+`, tsgen.GenerateTS(reflect.TypeOf(Address{}), reflect.TypeOf(Base{}), reflect.TypeOf(&Address{}), reflect.TypeOf(1)))
+}
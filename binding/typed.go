@@ -0,0 +1,111 @@
+//go:build go1.18
+// +build go1.18
+
+package binding
+
+import (
+	"net/http"
+	"reflect"
+)
+
+// BindTyped binds the request parameters to a new value of type T, and returns it.
+// NOTE:
+//  T is a struct type or a pointer to struct type, which is created by reflect.New;
+//  If b==nil, the default binding is used;
+//  The path parameters are decoded by the PathParamsDecoder of the binding;
+//  If error, the zero value of T is returned.
+func BindTyped[T any](b *Binding, req *http.Request) (T, error) {
+	if b == nil {
+		b = defaultBinding
+	}
+	var zero T
+	ptr := newTyped[T]()
+	var err error
+	if p, ok := ptr.Interface().(partialBinder); ok {
+		err = p.bindPartial(b, req)
+	} else {
+		err = b.Bind(ptr.Interface(), req, nil)
+	}
+	if err != nil {
+		return zero, err
+	}
+	return typedOf[T](ptr), nil
+}
+
+// newTyped returns the pointer to the new struct of T, which is a struct type or a pointer to struct type.
+func newTyped[T any]() reflect.Value {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() == reflect.Ptr {
+		return reflect.New(t.Elem())
+	}
+	return reflect.New(t)
+}
+
+// typedOf returns the value of T from the pointer created by newTyped.
+func typedOf[T any](ptr reflect.Value) T {
+	if v, ok := ptr.Interface().(T); ok {
+		return v
+	}
+	return ptr.Elem().Interface().(T)
+}
+
+// PartialStruct the value of T bound by BindTyped, and the presence of its fields,
+// e.g. for the PATCH requests.
+// NOTE:
+//  T is a struct type or a pointer to struct type;
+//  Present maps the selector of every field of T which accepts the request parameters, such as 'A.B',
+//  to whether the request contains the key of the field, even if the value is zero.
+type PartialStruct[T any] struct {
+	Value   T
+	Present map[string]bool
+}
+
+// partialBinder binds the request parameters with the presence of the fields.
+type partialBinder interface {
+	bindPartial(b *Binding, req *http.Request) error
+}
+
+func (p *PartialStruct[T]) bindPartial(b *Binding, req *http.Request) error {
+	ptr := newTyped[T]()
+	result, err := b.BindFull(ptr.Interface(), req, nil)
+	if err != nil {
+		return err
+	}
+	recv, err := b.getOrPrepareReceiver(ptr.Elem())
+	if err != nil {
+		return err
+	}
+	p.Present = make(map[string]bool, len(recv.params))
+	for _, param := range recv.params {
+		p.Present[param.fieldSelector] = false
+	}
+	for _, f := range result.BoundFields {
+		p.Present[f.Selector] = true
+	}
+	p.Value = typedOf[T](ptr)
+	return nil
+}
+
+// IsSet returns whether the request contains the key of the field specified by the selector, such as 'A.B'.
+func (p *PartialStruct[T]) IsSet(selector string) bool {
+	return p.Present[selector]
+}
+
+// MustBindTyped is similar to BindTyped, but panics if error.
+func MustBindTyped[T any](b *Binding, req *http.Request) T {
+	t, err := BindTyped[T](b, req)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// BindTypedOr is similar to BindTyped, but returns defaultVal if error,
+// for the optional binding with a reasonable default.
+func BindTypedOr[T any](b *Binding, req *http.Request, defaultVal T) T {
+	t, err := BindTyped[T](b, req)
+	if err != nil {
+		return defaultVal
+	}
+	return t
+}
@@ -0,0 +1,93 @@
+//go:build go1.18
+// +build go1.18
+
+package binding_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bytedance/go-tagexpr/binding"
+	"github.com/stretchr/testify/assert"
+)
+
+type typedRecv struct {
+	ID   int    `query:"id,required"`
+	Name string `query:"name"`
+}
+
+func TestBindTyped(t *testing.T) {
+	req := newRequest("http://localhost/?id=1&name=a", nil, nil, nil)
+	recv, err := binding.BindTyped[typedRecv](binding.New(nil), req)
+	assert.NoError(t, err)
+	assert.Equal(t, typedRecv{ID: 1, Name: "a"}, recv)
+
+	ptr, err := binding.BindTyped[*typedRecv](nil, req)
+	assert.NoError(t, err)
+	assert.Equal(t, &typedRecv{ID: 1, Name: "a"}, ptr)
+
+	req = newRequest("http://localhost/?name=a", nil, nil, nil)
+	recv, err = binding.BindTyped[typedRecv](nil, req)
+	assert.EqualError(t, err, "binding ID: missing required parameter")
+	assert.Equal(t, typedRecv{}, recv)
+	ptr, err = binding.BindTyped[*typedRecv](nil, req)
+	assert.Error(t, err)
+	assert.Nil(t, ptr)
+
+	_, err = binding.BindTyped[int](nil, newRequest("", nil, nil, nil))
+	assert.EqualError(t, err, "binding : structPointer must be a non-nil struct pointer")
+}
+
+func TestMustBindTyped(t *testing.T) {
+	req := newRequest("http://localhost/?id=1&name=a", nil, nil, nil)
+	assert.Equal(t, typedRecv{ID: 1, Name: "a"}, binding.MustBindTyped[typedRecv](nil, req))
+	assert.Equal(t, typedRecv{ID: 1, Name: "a"}, binding.BindTypedOr(nil, req, typedRecv{ID: 2}))
+
+	req = newRequest("http://localhost/?name=a", nil, nil, nil)
+	func() {
+		defer func() {
+			err, _ := recover().(error)
+			assert.EqualError(t, err, "binding ID: missing required parameter")
+		}()
+		binding.MustBindTyped[typedRecv](nil, req)
+	}()
+	assert.Equal(t, typedRecv{ID: 2}, binding.BindTypedOr(nil, req, typedRecv{ID: 2}))
+	assert.Equal(t, &typedRecv{ID: 3}, binding.BindTypedOr(nil, req, &typedRecv{ID: 3}))
+}
+
+func TestPartialStruct(t *testing.T) {
+	type Profile struct {
+		Bio string `json:"bio"`
+	}
+	type Patch struct {
+		Name    *string `json:"name"`
+		Age     int     `json:"age"`
+		Email   string  `json:"email"`
+		Profile Profile `json:"profile"`
+		Trace   string  `header:"X-Trace"`
+	}
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	req := newRequest("http://localhost/", header, nil, strings.NewReader(`{"age":0,"email":"a@b.com","profile":{"bio":""}}`))
+	p, err := binding.BindTyped[binding.PartialStruct[Patch]](nil, req)
+	assert.NoError(t, err)
+	assert.Equal(t, Patch{Email: "a@b.com"}, p.Value)
+	assert.Equal(t, map[string]bool{
+		"Name":        false,
+		"Age":         true,
+		"Email":       true,
+		"Profile":     true,
+		"Profile.Bio": true,
+		"Trace":       false,
+	}, p.Present)
+	assert.True(t, p.IsSet("Age"))
+	assert.False(t, p.IsSet("Name"))
+
+	req = newRequest("http://localhost/", header, nil, strings.NewReader(`{"name":"a"}`))
+	pp, err := binding.BindTyped[*binding.PartialStruct[*Patch]](nil, req)
+	assert.NoError(t, err)
+	assert.Equal(t, "a", *pp.Value.Name)
+	assert.True(t, pp.IsSet("Name"))
+	assert.False(t, pp.IsSet("Age"))
+}
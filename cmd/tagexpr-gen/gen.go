@@ -0,0 +1,390 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const genrtPkg = "github.com/bytedance/go-tagexpr/binding/genrt"
+
+// sourceOrder the order in which to try to bind, the same as the binding package
+var sourceOrder = []string{"path", "form", "query", "cookie", "header", "protobuf", "json", "raw_body"}
+
+// accessors the methods of genrt.Request which return the string values of the sources
+var accessors = map[string]string{
+	"form":   "Form",
+	"query":  "Query",
+	"cookie": "Cookie",
+	"header": "Header",
+}
+
+// basicTypes the supported basic types and their parsing functions
+var basicTypes = map[string]struct {
+	parse, jsonParse string // the format of the parsing call with the source
+	result           string // the result type of the parsing call
+}{
+	"string":  {"", "genrt.JSONString(%s)", "string"},
+	"bool":    {"strconv.ParseBool(%s)", "genrt.JSONBool(%s)", "bool"},
+	"int":     {"strconv.ParseInt(%s, 10, 0)", "genrt.JSONInt(%s, 0)", "int64"},
+	"int8":    {"strconv.ParseInt(%s, 10, 8)", "genrt.JSONInt(%s, 8)", "int64"},
+	"int16":   {"strconv.ParseInt(%s, 10, 16)", "genrt.JSONInt(%s, 16)", "int64"},
+	"int32":   {"strconv.ParseInt(%s, 10, 32)", "genrt.JSONInt(%s, 32)", "int64"},
+	"rune":    {"strconv.ParseInt(%s, 10, 32)", "genrt.JSONInt(%s, 32)", "int64"},
+	"int64":   {"strconv.ParseInt(%s, 10, 64)", "genrt.JSONInt(%s, 64)", "int64"},
+	"uint":    {"strconv.ParseUint(%s, 10, 0)", "genrt.JSONUint(%s, 0)", "uint64"},
+	"uint8":   {"strconv.ParseUint(%s, 10, 8)", "genrt.JSONUint(%s, 8)", "uint64"},
+	"byte":    {"strconv.ParseUint(%s, 10, 8)", "genrt.JSONUint(%s, 8)", "uint64"},
+	"uint16":  {"strconv.ParseUint(%s, 10, 16)", "genrt.JSONUint(%s, 16)", "uint64"},
+	"uint32":  {"strconv.ParseUint(%s, 10, 32)", "genrt.JSONUint(%s, 32)", "uint64"},
+	"uint64":  {"strconv.ParseUint(%s, 10, 64)", "genrt.JSONUint(%s, 64)", "uint64"},
+	"float32": {"strconv.ParseFloat(%s, 32)", "genrt.JSONFloat(%s, 32)", "float64"},
+	"float64": {"strconv.ParseFloat(%s, 64)", "genrt.JSONFloat(%s, 64)", "float64"},
+}
+
+// fieldType the type of the field to bind
+type fieldType struct {
+	ptr   bool
+	slice bool
+	bytes bool // []byte
+	basic string
+}
+
+// bindSource the source of the field
+type bindSource struct {
+	in   string
+	name string
+}
+
+// bindField the field to bind
+type bindField struct {
+	name     string // the Go field name
+	failName string // the field name of the error
+	typ      fieldType
+	sources  []bindSource
+	required bool
+}
+
+// parsePackage parses the non-test Go files in the directory,
+// and returns the package name and the struct types of the names.
+func parsePackage(dir string, typeNames []string) (string, []*ast.TypeSpec, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(pkgs) != 1 {
+		return "", nil, fmt.Errorf("%d packages found in %s", len(pkgs), dir)
+	}
+	var pkg *ast.Package
+	for _, p := range pkgs {
+		pkg = p
+	}
+	specs := make(map[string]*ast.TypeSpec)
+	for _, file := range pkg.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			if spec, ok := n.(*ast.TypeSpec); ok {
+				specs[spec.Name.Name] = spec
+			}
+			return true
+		})
+	}
+	a := make([]*ast.TypeSpec, 0, len(typeNames))
+	for _, name := range typeNames {
+		spec, ok := specs[name]
+		if !ok {
+			return "", nil, fmt.Errorf("type %s is not found in %s", name, filepath.Clean(dir))
+		}
+		if _, ok := spec.Type.(*ast.StructType); !ok {
+			return "", nil, fmt.Errorf("type %s is not a struct", name)
+		}
+		a = append(a, spec)
+	}
+	return pkg.Name, a, nil
+}
+
+// generate returns the formatted source code of the binding functions of the struct types.
+func generate(pkgName string, specs []*ast.TypeSpec) ([]byte, error) {
+	g := &generator{}
+	for _, spec := range specs {
+		if err := g.genStruct(spec); err != nil {
+			return nil, err
+		}
+	}
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by tagexpr-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\nimport (\n\t\"net/http\"\n", pkgName)
+	if g.strconv {
+		buf.WriteString("\t\"strconv\"\n")
+	}
+	fmt.Fprintf(&buf, "\n\t%q\n)\n", genrtPkg)
+	buf.Write(g.buf.Bytes())
+	return format.Source(buf.Bytes())
+}
+
+type generator struct {
+	buf     bytes.Buffer
+	strconv bool
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) genStruct(spec *ast.TypeSpec) error {
+	typeName := spec.Name.Name
+	var fields []*bindField
+	var readBody bool
+	for _, field := range spec.Type.(*ast.StructType).Fields.List {
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			f, err := parseField(ident.Name, field)
+			if err != nil {
+				return fmt.Errorf("%s.%s: %s", typeName, ident.Name, err.Error())
+			}
+			if f == nil {
+				continue
+			}
+			for _, s := range f.sources {
+				if s.in == "json" || s.in == "raw_body" {
+					readBody = true
+				}
+			}
+			fields = append(fields, f)
+		}
+	}
+
+	g.printf("\n// Bind_%s binds the request parameters to v without reflection.\n", typeName)
+	g.printf("func Bind_%s(req *http.Request, v *%s) error {\n", typeName, typeName)
+	g.printf("r := genrt.NewRequest(req)\n")
+	if readBody {
+		g.printf("if err := r.ReadBody(); err != nil {\nreturn err\n}\n")
+	}
+	for _, f := range fields {
+		for i, s := range f.sources {
+			if i > 0 {
+				g.printf("} else ")
+			}
+			switch s.in {
+			case "json":
+				g.printf("if res, ok := r.JSON(%q); ok {\n", s.name)
+				g.genJSON(f)
+			case "raw_body":
+				g.printf("if b := r.RawBody(); len(b) > 0 {\n")
+				if f.typ.bytes {
+					g.printf("v.%s = b\n", f.name)
+				} else {
+					g.printf("v.%s = string(b)\n", f.name)
+				}
+			default:
+				g.printf("if a, ok := r.%s(%q); ok {\n", accessors[s.in], s.name)
+				g.genStrings(f)
+			}
+		}
+		if f.required {
+			g.printf("} else {\nreturn genrt.MissingError(%q)\n", f.failName)
+		}
+		g.printf("}\n")
+	}
+	g.printf("return nil\n}\n")
+	return nil
+}
+
+// genStrings generates the code which assigns the string values 'a' to the field.
+func (g *generator) genStrings(f *bindField) {
+	t := f.typ
+	if t.basic == "string" {
+		switch {
+		case t.slice:
+			g.printf("v.%s = a\n", f.name)
+		case t.ptr:
+			g.printf("x := a[0]\nv.%s = &x\n", f.name)
+		default:
+			g.printf("v.%s = a[0]\n", f.name)
+		}
+		return
+	}
+	g.strconv = true
+	parse := basicTypes[t.basic].parse
+	if t.slice {
+		g.printf("s := make([]%s, len(a))\nfor i, e := range a {\n", t.basic)
+		g.genParse(f, fmt.Sprintf(parse, "e"), "s[i]")
+		g.printf("}\nv.%s = s\n", f.name)
+		return
+	}
+	g.genParse(f, fmt.Sprintf(parse, "a[0]"), "")
+}
+
+// genJSON generates the code which assigns the JSON value 'res' to the field.
+func (g *generator) genJSON(f *bindField) {
+	t := f.typ
+	parse := basicTypes[t.basic].jsonParse
+	if t.slice {
+		g.printf("elems, err := genrt.JSONArray(res)\nif err != nil {\nreturn genrt.TypeError(%q)\n}\n", f.failName)
+		g.printf("s := make([]%s, len(elems))\nfor i, e := range elems {\n", t.basic)
+		g.genParse(f, fmt.Sprintf(parse, "e"), "s[i]")
+		g.printf("}\nv.%s = s\n", f.name)
+		return
+	}
+	g.genParse(f, fmt.Sprintf(parse, "res"), "")
+}
+
+// genParse generates the code which assigns the result of the parsing call to @dst,
+// or to the field if @dst is empty.
+func (g *generator) genParse(f *bindField, call, dst string) {
+	t := f.typ
+	g.printf("x, err := %s\nif err != nil {\nreturn genrt.TypeError(%q)\n}\n", call, f.failName)
+	val := "x"
+	if basicTypes[t.basic].result != t.basic {
+		val = t.basic + "(x)"
+	}
+	switch {
+	case dst != "":
+		g.printf("%s = %s\n", dst, val)
+	case t.ptr:
+		if val != "x" {
+			g.printf("y := %s\nv.%s = &y\n", val, f.name)
+		} else {
+			g.printf("v.%s = &x\n", f.name)
+		}
+	default:
+		g.printf("v.%s = %s\n", f.name, val)
+	}
+}
+
+// parseField parses the binding tags of the field, returns nil if the field is not tagged.
+func parseField(name string, field *ast.Field) (*bindField, error) {
+	if field.Tag == nil {
+		return nil, nil
+	}
+	tagValue, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return nil, err
+	}
+	tag := reflect.StructTag(tagValue)
+	f := &bindField{name: name, failName: name}
+	for _, in := range sourceOrder {
+		value, ok := tag.Lookup(in)
+		if !ok {
+			continue
+		}
+		a := strings.Split(value, ",")
+		paramName := strings.TrimSpace(a[0])
+		options := a[1:]
+		if in == "raw_body" {
+			paramName, options = "", a
+		}
+		if in == "json" && paramName != "" && paramName != "-" {
+			f.failName = paramName
+		}
+		if paramName == "-" {
+			continue
+		}
+		switch in {
+		case "path", "protobuf":
+			return nil, fmt.Errorf("the %s tag is not supported", in)
+		case "header":
+			if paramName == "" {
+				paramName = name
+			}
+			paramName = textproto.CanonicalMIMEHeaderKey(paramName)
+		case "json":
+			if paramName == "" {
+				paramName = name
+			}
+			paramName = escapeJSONPath(paramName)
+		case "raw_body":
+		default:
+			if paramName == "" {
+				paramName = name
+			}
+		}
+		for _, option := range options {
+			switch strings.TrimSpace(option) {
+			case "required", "req":
+				f.required = true
+			}
+		}
+		f.sources = append(f.sources, bindSource{in: in, name: paramName})
+	}
+	if len(f.sources) == 0 {
+		return nil, nil
+	}
+	f.typ, err = parseFieldType(field.Type)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range f.sources {
+		isRawBody := s.in == "raw_body"
+		if f.typ.bytes != isRawBody && !(isRawBody && f.typ == fieldType{basic: "string"}) {
+			return nil, fmt.Errorf("the %s tag is not supported for the type %s", s.in, typeString(field.Type))
+		}
+	}
+	return f, nil
+}
+
+// parseFieldType parses the supported field type: T, *T, []T or []byte.
+func parseFieldType(expr ast.Expr) (fieldType, error) {
+	var t fieldType
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		t.ptr = true
+		expr = e.X
+	case *ast.ArrayType:
+		if e.Len == nil {
+			t.slice = true
+			expr = e.Elt
+		}
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		if _, ok := basicTypes[ident.Name]; ok {
+			t.basic = ident.Name
+			t.bytes = t.slice && (t.basic == "byte" || t.basic == "uint8")
+			return t, nil
+		}
+	}
+	return t, fmt.Errorf("the type %s is not supported", typeString(expr))
+}
+
+func typeString(expr ast.Expr) string {
+	var buf bytes.Buffer
+	format.Node(&buf, token.NewFileSet(), expr)
+	return buf.String()
+}
+
+// escapeJSONPath escapes the special characters of the gjson path.
+func escapeJSONPath(name string) string {
+	var buf strings.Builder
+	for _, r := range name {
+		switch r {
+		case '.', '*', '?', '|', '#', '@', '\\':
+			buf.WriteByte('\\')
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+// splitTypeNames splits the comma-separated type names.
+func splitTypeNames(s string) []string {
+	var a []string
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			a = append(a, name)
+		}
+	}
+	return a
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	pkgName, specs, err := parsePackage("internal/example", []string{"InfoRequest"})
+	assert.NoError(t, err)
+	src, err := generate(pkgName, specs)
+	assert.NoError(t, err)
+	expected, err := ioutil.ReadFile("internal/example/inforequest_bind.go")
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), string(src), "the generated example is out of date, run go generate")
+
+	_, _, err = parsePackage("internal/example", []string{"Unknown"})
+	assert.EqualError(t, err, "type Unknown is not found in internal/example")
+}
+
+func TestGenerateUnsupported(t *testing.T) {
+	var cases = []struct {
+		field  string
+		errStr string
+	}{
+		{"A string `path:\"a\"`", "T.A: the path tag is not supported"},
+		{"A map[string]int `query:\"a\"`", "T.A: the type map[string]int is not supported"},
+		{"A []byte `query:\"a\"`", "T.A: the query tag is not supported for the type []byte"},
+		{"A int `raw_body:\"\"`", "T.A: the raw_body tag is not supported for the type int"},
+	}
+	for _, c := range cases {
+		expr, err := parser.ParseExpr("struct{" + c.field + "}")
+		assert.NoError(t, err)
+		spec := &ast.TypeSpec{Name: ast.NewIdent("T"), Type: expr}
+		_, err = generate("p", []*ast.TypeSpec{spec})
+		assert.EqualError(t, err, c.errStr)
+	}
+}
@@ -0,0 +1,18 @@
+// Package example is the example of the code generated by tagexpr-gen.
+package example
+
+//go:generate go run github.com/bytedance/go-tagexpr/cmd/tagexpr-gen -type=InfoRequest
+
+// InfoRequest the request parameters
+type InfoRequest struct {
+	Year     []int    `query:"year"`
+	Page     *uint16  `query:"page" form:"page"`
+	Ratio    float32  `header:"X-Ratio"`
+	Session  string   `cookie:"sessionid,required"`
+	Email    *string  `json:"email" vd:"email($)"`
+	Friendly bool     `json:"friendly"`
+	Pie      float64  `json:"pie,required"`
+	Hobby    []string `json:"hobby"`
+	Raw      []byte   `raw_body:""`
+	Ignored  string
+}
@@ -0,0 +1,58 @@
+package example
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bytedance/go-tagexpr/binding"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRequest(body string) *http.Request {
+	req, _ := http.NewRequest("POST", "http://localhost/info?year=2018&year=2019&page=2", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Ratio", "0.5")
+	req.AddCookie(&http.Cookie{Name: "sessionid", Value: "987654"})
+	return req
+}
+
+func TestBindInfoRequest(t *testing.T) {
+	body := `{"email":"henrylee2cn@gmail.com","friendly":true,"pie":3.14,"hobby":["Coding","Mountain climbing"]}`
+	generated := new(InfoRequest)
+	err := Bind_InfoRequest(newRequest(body), generated)
+	assert.NoError(t, err)
+	reflected := new(InfoRequest)
+	err = binding.Bind(reflected, newRequest(body), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, reflected, generated)
+	assert.Equal(t, []int{2018, 2019}, generated.Year)
+	assert.Equal(t, uint16(2), *generated.Page)
+	assert.Equal(t, "987654", generated.Session)
+	assert.Equal(t, body, string(generated.Raw))
+
+	err = Bind_InfoRequest(newRequest(`{"pie":"3.14"}`), new(InfoRequest))
+	assert.EqualError(t, err, "binding pie: parameter type does not match binding data")
+	err = Bind_InfoRequest(newRequest(`{}`), new(InfoRequest))
+	assert.EqualError(t, err, "binding pie: missing required parameter")
+}
+
+func BenchmarkBindInfoRequest(b *testing.B) {
+	body := `{"email":"henrylee2cn@gmail.com","friendly":true,"pie":3.14,"hobby":["Coding","Mountain climbing"]}`
+	b.Run("Generated", func(b *testing.B) {
+		req := newRequest(body)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			Bind_InfoRequest(req, new(InfoRequest))
+		}
+	})
+	b.Run("Reflect", func(b *testing.B) {
+		req := newRequest(body)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			binding.Bind(new(InfoRequest), req, nil)
+		}
+	})
+}
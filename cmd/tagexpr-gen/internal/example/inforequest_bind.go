@@ -0,0 +1,98 @@
+// Code generated by tagexpr-gen. DO NOT EDIT.
+
+package example
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/bytedance/go-tagexpr/binding/genrt"
+)
+
+// Bind_InfoRequest binds the request parameters to v without reflection.
+func Bind_InfoRequest(req *http.Request, v *InfoRequest) error {
+	r := genrt.NewRequest(req)
+	if err := r.ReadBody(); err != nil {
+		return err
+	}
+	if a, ok := r.Query("year"); ok {
+		s := make([]int, len(a))
+		for i, e := range a {
+			x, err := strconv.ParseInt(e, 10, 0)
+			if err != nil {
+				return genrt.TypeError("Year")
+			}
+			s[i] = int(x)
+		}
+		v.Year = s
+	}
+	if a, ok := r.Form("page"); ok {
+		x, err := strconv.ParseUint(a[0], 10, 16)
+		if err != nil {
+			return genrt.TypeError("Page")
+		}
+		y := uint16(x)
+		v.Page = &y
+	} else if a, ok := r.Query("page"); ok {
+		x, err := strconv.ParseUint(a[0], 10, 16)
+		if err != nil {
+			return genrt.TypeError("Page")
+		}
+		y := uint16(x)
+		v.Page = &y
+	}
+	if a, ok := r.Header("X-Ratio"); ok {
+		x, err := strconv.ParseFloat(a[0], 32)
+		if err != nil {
+			return genrt.TypeError("Ratio")
+		}
+		v.Ratio = float32(x)
+	}
+	if a, ok := r.Cookie("sessionid"); ok {
+		v.Session = a[0]
+	} else {
+		return genrt.MissingError("Session")
+	}
+	if res, ok := r.JSON("email"); ok {
+		x, err := genrt.JSONString(res)
+		if err != nil {
+			return genrt.TypeError("email")
+		}
+		v.Email = &x
+	}
+	if res, ok := r.JSON("friendly"); ok {
+		x, err := genrt.JSONBool(res)
+		if err != nil {
+			return genrt.TypeError("friendly")
+		}
+		v.Friendly = x
+	}
+	if res, ok := r.JSON("pie"); ok {
+		x, err := genrt.JSONFloat(res, 64)
+		if err != nil {
+			return genrt.TypeError("pie")
+		}
+		v.Pie = x
+	} else {
+		return genrt.MissingError("pie")
+	}
+	if res, ok := r.JSON("hobby"); ok {
+		elems, err := genrt.JSONArray(res)
+		if err != nil {
+			return genrt.TypeError("hobby")
+		}
+		s := make([]string, len(elems))
+		for i, e := range elems {
+			x, err := genrt.JSONString(e)
+			if err != nil {
+				return genrt.TypeError("hobby")
+			}
+			s[i] = x
+		}
+		v.Hobby = s
+	}
+	if b := r.RawBody(); len(b) > 0 {
+		v.Raw = b
+	}
+	return nil
+}
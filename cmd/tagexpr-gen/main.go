@@ -0,0 +1,65 @@
+// Command tagexpr-gen generates the reflection-free binding functions of the struct types,
+// for the performance-critical endpoints.
+//
+// Usage:
+//
+//	tagexpr-gen -type=InfoRequest[,OtherRequest] [-output=file] [dir]
+//
+// For each type T, the function 'Bind_T(req *http.Request, v *T) error' is generated,
+// which binds the fields tagged by query, form, header, cookie, json and raw_body.
+// The default output file is '<lowercase first type>_bind.go' in the directory.
+//
+// It can be invoked by go:generate, e.g.:
+//
+//	//go:generate tagexpr-gen -type=InfoRequest
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	typeNames = flag.String("type", "", "comma-separated list of the struct type names; must be set")
+	output    = flag.String("output", "", "output file name; default is <dir>/<lowercase first type>_bind.go")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: tagexpr-gen -type=T[,T...] [-output=file] [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	names := splitTypeNames(*typeNames)
+	if len(names) == 0 || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+	if err := run(dir, names, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "tagexpr-gen: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+func run(dir string, names []string, outputName string) error {
+	pkgName, specs, err := parsePackage(dir, names)
+	if err != nil {
+		return err
+	}
+	src, err := generate(pkgName, specs)
+	if err != nil {
+		return err
+	}
+	if outputName == "" {
+		outputName = filepath.Join(dir, strings.ToLower(names[0])+"_bind.go")
+	}
+	return ioutil.WriteFile(outputName, src, 0644)
+}
@@ -16,19 +16,45 @@ package tagexpr
 
 import (
 	"fmt"
+	"strings"
 )
 
 // Expr expression
 type Expr struct {
 	expr ExprNode
+	// src the source of the expression
+	src string
+	// fieldRefs the field selectors that must exist in the struct
+	fieldRefs []string
+	// funcRefs the names of the functions called by the expression
+	funcRefs []string
+	// selectorRefs the field selectors of the selector operands, such as (A.B)$
+	selectorRefs []string
+	// ancestorRefs the fields of the ancestor structs, such as (../A.B)$
+	ancestorRefs []ancestorRef
+	// rootRefs the fields of the root struct, such as (/A.B)$
+	rootRefs []string
+	// omitEmpty whether the expression is prefixed with '?'
+	omitEmpty bool
+	// elemScope whether the expression is the sub-expression of the quantifier function,
+	// in which the element operands '#v' and '#k' are valid
+	elemScope bool
 }
 
 // parseExpr parses the expression.
 func parseExpr(expr string) (*Expr, error) {
+	return (&Expr{src: expr}).parse()
+}
+
+// parseElemExpr parses the sub-expression of the quantifier function, such as 'len(#v)>0'.
+func parseElemExpr(expr string) (*Expr, error) {
+	return (&Expr{src: expr, elemScope: true}).parse()
+}
+
+func (p *Expr) parse() (*Expr, error) {
 	e := newGroupExprNode()
-	p := &Expr{
-		expr: e,
-	}
+	p.expr = e
+	expr := p.src
 	s := expr
 	_, err := p.parseExprNode(&s, e)
 	if err != nil {
@@ -43,11 +69,68 @@ func parseExpr(expr string) (*Expr, error) {
 }
 
 // run calculates the value of expression.
-func (p *Expr) run(field string, tagExpr *TagExpr) interface{} {
-	return p.expr.Run(field, tagExpr)
+// NOTE:
+//  The panic in evaluation is recovered as *EvalFault;
+//  The error returned by the function registered by RegErrFunc aborts the evaluation as *FuncError.
+func (p *Expr) run(field string, tagExpr *TagExpr) (r interface{}) {
+	defer func() {
+		if e := recover(); e != nil {
+			fe, ok := e.(*FuncError)
+			if !ok {
+				panic(e)
+			}
+			fe.Expr = p.src
+			r = fe
+		}
+	}()
+	r = runOperand(p.expr, field, tagExpr)
+	if fault, ok := r.(*EvalFault); ok && fault.Expr == "" {
+		fault.Expr = p.src
+	}
+	return r
+}
+
+// EvalFault the internal fault of evaluating the expression, such as a panic in the function.
+// NOTE:
+//  It is false in the boolean context.
+type EvalFault struct {
+	// Expr the source of the expression
+	Expr string
+	// Cause the recovered panic value
+	Cause interface{}
+}
+
+// Error implements error interface.
+func (e *EvalFault) Error() string {
+	return fmt.Sprintf("evaluation fault in %q: %v", e.Expr, e.Cause)
+}
+
+// Unwrap returns the cause if it is an error, such as *ConvError.
+func (e *EvalFault) Unwrap() error {
+	err, _ := e.Cause.(error)
+	return err
+}
+
+// runOperand runs the expression node and recovers the panic as *EvalFault,
+// except *FuncError which aborts the whole expression.
+func runOperand(e ExprNode, currField string, tagExpr *TagExpr) (r interface{}) {
+	defer func() {
+		if p := recover(); p != nil {
+			if fe, ok := p.(*FuncError); ok {
+				panic(fe)
+			}
+			r = &EvalFault{Cause: p}
+		}
+	}()
+	return e.Run(currField, tagExpr)
 }
 
 func (p *Expr) parseOperand(expr *string) (e ExprNode) {
+	if p.elemScope {
+		if e = readElemExprNode(p, expr); e != nil {
+			return e
+		}
+	}
 	for _, fn := range funcList {
 		if e = fn(p, expr); e != nil {
 			return e
@@ -80,9 +163,8 @@ func (*Expr) parseOperator(expr *string) (e ExprNode) {
 	}()
 	a := s[:2]
 	switch a {
-	// case "<<":
-	// case ">>":
-	// case "&^":
+	case "<<", ">>", "&^":
+		return newBitwiseExprNode(a)
 	case "||":
 		return newOrExprNode()
 	case "&&":
@@ -95,6 +177,8 @@ func (*Expr) parseOperator(expr *string) (e ExprNode) {
 		return newLessEqualExprNode()
 	case "!=":
 		return newNotEqualExprNode()
+	case "??":
+		return newNullCoalescingExprNode()
 	}
 	defer func() {
 		if e != nil {
@@ -102,9 +186,8 @@ func (*Expr) parseOperator(expr *string) (e ExprNode) {
 		}
 	}()
 	switch a[0] {
-	// case '&':
-	// case '|':
-	// case '^':
+	case '&', '|', '^':
+		return newBitwiseExprNode(a[:1])
 	case '+':
 		return newAdditionExprNode()
 	case '-':
@@ -128,6 +211,12 @@ func (p *Expr) parseExprNode(expr *string, e ExprNode) (ExprNode, error) {
 	if *expr == "" {
 		return nil, nil
 	}
+	if g, ok := e.(*groupExprNode); ok && g.rightOperand == nil {
+		// the ternary operator has the lowest priority, so it is parsed at the beginning of the group
+		if i := indexTernary(*expr, '?'); i >= 0 {
+			return p.parseTernaryExprNode(expr, i, e)
+		}
+	}
 	operand := p.readSelectorExprNode(expr)
 	if operand == nil {
 		var subExprNode *string
@@ -168,6 +257,102 @@ func (p *Expr) parseExprNode(expr *string, e ExprNode) (ExprNode, error) {
 	return p.parseExprNode(expr, operator)
 }
 
+// parseTernaryExprNode parses the ternary operator 'cond ? a : b', whose '?' is at @i,
+// the nested one in the else branch is parsed recursively, so it is right associative.
+func (p *Expr) parseTernaryExprNode(expr *string, i int, e ExprNode) (ExprNode, error) {
+	tail := (*expr)[i:]
+	j := indexTernary(tail[1:], ':')
+	if j < 0 {
+		if pos := strings.Index(p.src, tail); pos >= 0 {
+			return nil, fmt.Errorf("missing ':' of the ternary operator at pos %d: %q", pos, tail)
+		}
+		return nil, fmt.Errorf("missing ':' of the ternary operator: %q", tail)
+	}
+	te := &ternaryExprNode{}
+	for _, branch := range []struct {
+		node *ExprNode
+		expr string
+	}{
+		{&te.cond, (*expr)[:i]},
+		{&te.then, tail[1 : j+1]},
+	} {
+		grp := newGroupExprNode()
+		s := branch.expr
+		if _, err := p.parseExprNode(&s, grp); err != nil {
+			return nil, err
+		}
+		if trimLeftSpace(&s); s != "" || grp.RightOperand() == nil {
+			return nil, fmt.Errorf("parsing pos: %q", branch.expr)
+		}
+		sortPriority(grp.RightOperand())
+		*branch.node = grp
+	}
+	*expr = tail[j+2:]
+	grp := newGroupExprNode()
+	if _, err := p.parseExprNode(expr, grp); err != nil {
+		return nil, err
+	}
+	if grp.RightOperand() == nil {
+		return nil, fmt.Errorf("parsing pos: %q", tail)
+	}
+	sortPriority(grp.RightOperand())
+	te.els = grp
+	e.SetRightOperand(te)
+	te.SetParent(e)
+	return te, nil
+}
+
+// indexTernary returns the index of the first '?' or the matched ':' of the ternary operator,
+// which is not in the string literal or the brackets, or -1 if not found before the end of the operand.
+// NOTE:
+//  The operand ends at the comma or the closing bracket, such as the argument of the function;
+//  The nested '?' before the ':' is matched by the next one.
+func indexTernary(s string, find byte) int {
+	var depth, nested int
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			i++
+		case '\'':
+			for i++; i < len(s) && s[i] != '\''; i++ {
+				if s[i] == '\\' {
+					i++
+				}
+			}
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth--; depth < 0 {
+				return -1
+			}
+		case ',':
+			if depth == 0 {
+				return -1
+			}
+		case '?', ':':
+			if depth > 0 {
+				continue
+			}
+			if c == '?' && i+1 < len(s) && s[i+1] == '?' {
+				// the null-coalescing operator
+				i++
+				continue
+			}
+			switch {
+			case c == '?' && find == '?':
+				return i
+			case c == '?':
+				nested++
+			case find == ':' && nested == 0:
+				return i
+			case find == ':':
+				nested--
+			}
+		}
+	}
+	return -1
+}
+
 func (p *Expr) checkSyntax() error {
 
 	return nil
@@ -176,12 +361,14 @@ func (p *Expr) checkSyntax() error {
 /**
  * Priority:
  * () ! bool float64 string nil
- * * / %
- * + -
+ * * / % & &^ << >>
+ * + - | ^
+ * ??
  * < <= > >=
  * == !=
  * &&
  * ||
+ * ?:
 **/
 
 func sortPriority(e ExprNode) {
@@ -206,12 +393,19 @@ func getPriority(e ExprNode) (i int) {
 	// defer func() {
 	// 	fmt.Printf("expr:%T %d\n", e, i)
 	// }()
-	switch e.(type) {
+	switch x := e.(type) {
 	default: // () ! bool float64 string nil
-		return 7
+		return 8
 	case *multiplicationExprNode, *divisionExprNode, *remainderExprNode: // * / %
-		return 6
+		return 7
+	case *bitwiseExprNode:
+		if x.op == "|" || x.op == "^" { // | ^
+			return 6
+		}
+		return 7 // & &^ << >>
 	case *additionExprNode, *subtractionExprNode: // + -
+		return 6
+	case *nullCoalescingExprNode: // ??
 		return 5
 	case *lessExprNode, *lessEqualExprNode, *greaterExprNode, *greaterEqualExprNode: // < <= > >=
 		return 4
@@ -229,7 +423,11 @@ func leftOperandToParent(e ExprNode) {
 	if le == nil {
 		return
 	}
-	e.SetLeftOperand(le.RightOperand())
+	lr := le.RightOperand()
+	e.SetLeftOperand(lr)
+	if lr != nil {
+		lr.SetParent(e)
+	}
 	le.SetRightOperand(e)
 	p := e.Parent()
 	// if p == nil {
@@ -18,6 +18,9 @@ import (
 	"math"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestExpr(t *testing.T) {
@@ -53,7 +56,6 @@ func TestExpr(t *testing.T) {
 		{expr: "-1.1+4", val: 2.9},
 		{expr: "10-7-2", val: 1.0},
 		{expr: "20/2", val: 10.0},
-		{expr: "1/0", val: math.NaN()},
 		{expr: "20%2", val: 0.0},
 		{expr: "6 % 5", val: 1.0},
 		{expr: "20%7 %5", val: 1.0},
@@ -167,11 +169,19 @@ func TestBuiltInFunc(t *testing.T) {
 		{expr: "len('abc')", val: 3.0},
 		{expr: "len('abc')+2*2/len('cd')", val: 5.0},
 		{expr: "len(0)", val: nil},
+		{expr: "len('张三')", val: 6.0},
+		{expr: "mblen('张三')", val: 2.0},
+		{expr: "runelen('张三')<=2", val: true},
+		{expr: "mblen(0)", val: nil},
+		{expr: "graphemelen('e\u0301🇨🇳👍🏽👨\u200d👩\u200d👧')", val: 4.0},
 
 		{expr: "regexp('a\\d','a0')", val: true},
 		{expr: "regexp('^a\\d$','a0')", val: true},
 		{expr: "regexp('a\\d','a')", val: false},
 		{expr: "regexp('^a\\d$','a')", val: false},
+		{expr: "regexp('^'+'a','a')", val: true},
+		{expr: "regexp('^'+'a'+'$','ab')", val: false},
+		{expr: "regexp('['+'a','a')", val: false},
 
 		{expr: "sprintf('test string: %s','a')", val: "test string: a"},
 		{expr: "sprintf('test string: %s','a'+'b')", val: "test string: ab"},
@@ -195,6 +205,270 @@ func TestBuiltInFunc(t *testing.T) {
 	}
 }
 
+func TestTernary(t *testing.T) {
+	var cases = []struct {
+		expr string
+		val  interface{}
+	}{
+		{expr: "true ? 100 : 10", val: 100.0},
+		{expr: "false?100:10", val: 10.0},
+		{expr: "1>2 ? 'a' : 'b'", val: "b"},
+		{expr: "'' ? 1 : nil", val: nil},
+		// lower than ||
+		{expr: "false || true ? 1 : 2", val: 1.0},
+		{expr: "true && false ? 1 : 2", val: 2.0},
+		{expr: "true ? 1 : 2 + 10", val: 1.0},
+		{expr: "(true ? 1 : 2) + 10", val: 11.0},
+		// right associative
+		{expr: "false ? 1 : true ? 2 : 3", val: 2.0},
+		{expr: "false ? 1 : false ? 2 : 3", val: 3.0},
+		{expr: "true ? false ? 1 : 2 : 3", val: 2.0},
+		// not in the strings, brackets and arguments
+		{expr: "true ? 'a?b:c' : 'd'", val: "a?b:c"},
+		{expr: "sprintf('%v', true ? 'x' : 'y')+'!'", val: "x!"},
+		{expr: "len(false ? 'ab' : 'abc') == 3 ? 'yes' : 'no'", val: "yes"},
+	}
+	for _, c := range cases {
+		t.Log(c.expr)
+		vm, err := parseExpr(c.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		val := vm.run("", nil)
+		if !reflect.DeepEqual(val, c.val) {
+			t.Fatalf("expr: %q, got: %v, expect: %v", c.expr, val, c.val)
+		}
+	}
+
+	// the untaken branch is not evaluated
+	var calls int
+	err := RegFunc("ternaryProbe", func(...interface{}) interface{} {
+		calls++
+		return true
+	})
+	assert.NoError(t, err)
+	type User struct{ Admin bool }
+	type T struct {
+		User  *User
+		Limit int `te:"(User)$!=nil && (User.Admin)$ ? $<=100 : $<=10 && ternaryProbe()"`
+	}
+	vm := New("te")
+	assert.True(t, vm.MustRun(&T{User: &User{Admin: true}, Limit: 50}).EvalBool("Limit"))
+	assert.Equal(t, 0, calls)
+	assert.False(t, vm.MustRun(&T{Limit: 50}).EvalBool("Limit"))
+	assert.True(t, vm.MustRun(&T{Limit: 5}).EvalBool("Limit"))
+	assert.Equal(t, 1, calls)
+
+	for _, expr := range []string{"true ? 1", "true ? 1 :", "? 1 : 2", "true ? : 2", "(true ? 1) : 2"} {
+		_, err := parseExpr(expr)
+		assert.Error(t, err, expr)
+	}
+	_, err = parseExpr("1 + (true ? 1)")
+	assert.EqualError(t, err, "\"1 + (true ? 1)\" (syntax error): missing ':' of the ternary operator at pos 10: \"? 1\"")
+}
+
+func TestNullCoalescing(t *testing.T) {
+	var cases = []struct {
+		expr string
+		val  interface{}
+	}{
+		{expr: "nil ?? 1", val: 1.0},
+		{expr: "nil??nil??'x'", val: "x"},
+		{expr: "'' ?? 'x'", val: ""},
+		{expr: "0 ?? 1", val: 0.0},
+		{expr: "false ?? true", val: false},
+		// lower than + -, higher than the comparison and ||
+		{expr: "nil ?? 1 + 2", val: 3.0},
+		{expr: "nil ?? 5 > 3", val: true},
+		{expr: "nil ?? 1 == 1", val: true},
+		{expr: "false || nil ?? true", val: true},
+		// with the ternary operator
+		{expr: "nil ?? false ? 'a' : 'b'", val: "b"},
+		{expr: "true ? nil ?? 1 : 2", val: 1.0},
+		{expr: "false ? 1 : nil ?? 2", val: 2.0},
+	}
+	for _, c := range cases {
+		t.Log(c.expr)
+		vm, err := parseExpr(c.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		val := vm.run("", nil)
+		if !reflect.DeepEqual(val, c.val) {
+			t.Fatalf("expr: %q, got: %v, expect: %v", c.expr, val, c.val)
+		}
+	}
+
+	type Profile struct{ Age int }
+	type T struct {
+		Nickname *string
+		Name     string
+		Profile  *Profile
+		M        map[string]string
+		S        []int
+		Display  string `te:"(Nickname)$ ?? (Name)$"`
+		Age      int    `te:"(Profile.Age)$??18"`
+		Lang     string `te:"(M)$['lang'] ?? 'en'"`
+		First    int    `te:"(S)$[5] ?? -1"`
+		Adult    bool   `te:"((Profile.Age)$ ?? 18) >= 18 && (Nickname)$ ?? (Name)$ != ''"`
+	}
+	nickname := "bob"
+	for _, vm := range []*VM{New("te"), New("te").SetStrictMode(true)} {
+		te := vm.MustRun(&T{Name: "robert"})
+		assert.Equal(t, "robert", te.Eval("Display"))
+		assert.Equal(t, 18.0, te.Eval("Age"))
+		assert.Equal(t, "en", te.Eval("Lang"))
+		assert.Equal(t, -1.0, te.Eval("First"))
+		assert.Equal(t, true, te.Eval("Adult"))
+		te = vm.MustRun(&T{Nickname: &nickname, Profile: &Profile{Age: 16}, M: map[string]string{"lang": "fr"}, S: []int{1, 2, 3, 4, 5, 6}})
+		assert.Equal(t, "bob", te.Eval("Display"))
+		assert.Equal(t, 16.0, te.Eval("Age"))
+		assert.Equal(t, "fr", te.Eval("Lang"))
+		assert.Equal(t, 6.0, te.Eval("First"))
+		assert.Equal(t, false, te.Eval("Adult"))
+	}
+
+	for _, expr := range []string{"?? 1", "nil ?? ?? 1"} {
+		_, err := parseExpr(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestBitwise(t *testing.T) {
+	var cases = []struct {
+		expr string
+		val  interface{}
+	}{
+		{expr: "6 & 3", val: 2.0},
+		{expr: "6 | 3", val: 7.0},
+		{expr: "6 ^ 3", val: 5.0},
+		{expr: "6 &^ 3", val: 4.0},
+		{expr: "1 << 4", val: 16.0},
+		{expr: "-16 >> 2", val: -4.0},
+		{expr: "-1 & 255", val: 255.0},
+		// the Go precedence: & &^ << >> as * /, and | ^ as + -
+		{expr: "1 | 2 & 3", val: 3.0},
+		{expr: "1 + 1 << 2", val: 5.0},
+		{expr: "2 ^ 3 + 1", val: 2.0},
+		{expr: "1 + 6 & 3", val: 3.0},
+		{expr: "6 & 4 == 4", val: true},
+		{expr: "(1 << 63) >> 63 == 1", val: true},
+		{expr: "1 << 63 == 9223372036854775808", val: true},
+		{expr: "1 << 63 | 1 == 9223372036854775809", val: true},
+		{expr: "1 << 63 | 1 == 9223372036854775808", val: false},
+		{expr: "len('abc') & 1", val: 1.0},
+		{expr: "true && 6 & 7 == 1 << 2 | 2", val: true},
+		{expr: "true && 6 == 2 * 2 + 2", val: true},
+	}
+	for _, c := range cases {
+		t.Log(c.expr)
+		vm, err := parseExpr(c.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		val := vm.run("", nil)
+		if !reflect.DeepEqual(val, c.val) {
+			t.Fatalf("expr: %q, got: %v, expect: %v", c.expr, val, c.val)
+		}
+	}
+
+	type T struct {
+		Flags  uint64  `te:"$&(1<<63) != 0 && ($>>60)&1 == 1 && $&^(1<<63) == 1<<60|5"`
+		High   uint64  `te:"$|1 == 18446744073709551615"`
+		Masked int64   `te:"$&255"`
+		Ratio  float64 `te:"$&1"`
+		Mixed  uint64  `te:"$&1.5"`
+		Shift  int     `te:"1<<$"`
+	}
+	obj := &T{Flags: 1<<63 | 1<<60 | 5, High: 1<<64 - 2, Masked: -1, Ratio: 3, Mixed: 3, Shift: -1}
+	te := New("te").MustRun(obj)
+	assert.Equal(t, true, te.Eval("Flags"))
+	assert.Equal(t, true, te.Eval("High"))
+	assert.Equal(t, 255.0, te.Eval("Masked"))
+	assert.IsType(t, &EvalFault{}, te.Eval("Ratio"))
+	assert.IsType(t, &EvalFault{}, te.Eval("Mixed"))
+	assert.IsType(t, &EvalFault{}, te.Eval("Shift"))
+	obj.Flags &^= 1 << 60
+	assert.Equal(t, false, te.Eval("Flags"))
+}
+
+func TestIntegerArithmetic(t *testing.T) {
+	var cases = []struct {
+		expr string
+		val  interface{}
+	}{
+		{expr: "7/2", val: 3.0},
+		{expr: "-7/2", val: -3.0},
+		{expr: "7.0/2", val: 3.5},
+		{expr: "7/2.0", val: 3.5},
+		{expr: "-7%3", val: -1.0},
+		{expr: "7%-3", val: 1.0},
+		{expr: "-7.5%2", val: -1.5},
+		{expr: "7.5%2", val: 1.5},
+		{expr: "1+2.5", val: 3.5},
+		{expr: "7/2*2 == 6", val: true},
+		{expr: "(1+2)&1", val: 1.0},
+		// 2^60-scale values are exact
+		{expr: "1152921504606846977%2 == 1", val: true},
+		{expr: "1152921504606846976+1 == 1152921504606846977", val: true},
+		{expr: "1152921504606846976*8 == 9223372036854775808", val: true},
+		{expr: "9223372036854775807+1 == 9223372036854775808", val: true},
+		{expr: "18446744073709551615-18446744073709551614 == 1", val: true},
+		{expr: "-9223372036854775808/-1 == 9223372036854775808", val: true},
+		{expr: "0-9223372036854775808 == -9223372036854775808", val: true},
+	}
+	for _, c := range cases {
+		t.Log(c.expr)
+		vm, err := parseExpr(c.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		val := vm.run("", nil)
+		if !reflect.DeepEqual(val, c.val) {
+			t.Fatalf("expr: %q, got: %v, expect: %v", c.expr, val, c.val)
+		}
+	}
+	for expr, cause := range map[string]error{
+		"1/0":                        errDivisionByZero,
+		"1%0":                        errDivisionByZero,
+		"1.5/0":                      errDivisionByZero,
+		"1%0.0":                      errDivisionByZero,
+		"1/0 > 1 || true":            nil,
+		"18446744073709551615+1":     errIntegerOverflow,
+		"0-18446744073709551615":     errIntegerOverflow,
+		"9223372036854775807*4 == 0": errIntegerOverflow,
+	} {
+		vm, err := parseExpr(expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		val := vm.run("", nil)
+		if cause == nil {
+			assert.Equal(t, true, val, expr)
+			continue
+		}
+		if fault, ok := val.(*EvalFault); assert.True(t, ok, expr) {
+			assert.Equal(t, cause, fault.Cause, expr)
+		}
+	}
+
+	type T struct {
+		Big   int64   `te:"$%2 == 1 && $/2 == 576460752303423488"`
+		Neg   int64   `te:"$%4"`
+		Mixed int     `te:"$/(F)$"`
+		Half  uint64  `te:"$/2*2 == $"`
+		Zero  int     `te:"(Big)$/$ > 0"`
+		F     float64 `te:"$%1"`
+	}
+	te := New("te").MustRun(&T{Big: 1<<60 + 1, Neg: -7, Mixed: 7, Half: 1<<63 + 1, F: 2.5})
+	assert.Equal(t, true, te.Eval("Big"))
+	assert.Equal(t, -3.0, te.Eval("Neg"))
+	assert.Equal(t, 2.8, te.Eval("Mixed"))
+	assert.Equal(t, false, te.Eval("Half"))
+	assert.IsType(t, &EvalFault{}, te.Eval("Zero"))
+	assert.Equal(t, 0.5, te.Eval("F"))
+}
+
 func TestSyntaxIncorrect(t *testing.T) {
 	var cases = []struct {
 		incorrectExpr string
@@ -203,7 +477,8 @@ func TestSyntaxIncorrect(t *testing.T) {
 		{incorrectExpr: "len"},
 		{incorrectExpr: "regexp"},
 		{incorrectExpr: "regexp()"},
-		{incorrectExpr: "regexp('^'+'a','a')"},
+		{incorrectExpr: "regexp('(','a')"},
+		{incorrectExpr: "regexp('^'+'a')"},
 		{incorrectExpr: "regexp('^a','a','b')"},
 		{incorrectExpr: "sprintf()"},
 		{incorrectExpr: "sprintf(0)"},
@@ -218,3 +493,32 @@ func TestSyntaxIncorrect(t *testing.T) {
 		}
 	}
 }
+
+func TestTimeFunc(t *testing.T) {
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	type T struct {
+		Past     time.Time  `te:"before($, now()) && $<now() && !($>now())"`
+		Future   *time.Time `te:"after($, now()) && $>now() && $>=(Past)$"`
+		Nil      *time.Time `te:"before($, now()) || after($, now()) || $<now() || $>now()"`
+		Birthday time.Time  `te:"age($)"`
+		Zone     time.Time  `te:"$<=now() && $>=now()"`
+	}
+	future := now.Add(time.Hour)
+	obj := &T{
+		Past:     now.Add(-time.Hour),
+		Future:   &future,
+		Birthday: time.Date(2002, 3, 2, 0, 0, 0, 0, time.UTC),
+		Zone:     now.In(time.FixedZone("UTC+8", 8*3600)),
+	}
+	te := New("te").MustRun(obj)
+	assert.True(t, te.EvalBool("Past"))
+	assert.True(t, te.EvalBool("Future"))
+	assert.False(t, te.EvalBool("Nil"))
+	assert.Equal(t, 17.0, te.EvalFloat("Birthday"))
+	assert.True(t, te.EvalBool("Zone"))
+	obj.Birthday = time.Date(2002, 3, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 18.0, te.EvalFloat("Birthday"))
+}
@@ -1,6 +1,9 @@
 package tagexpr
 
-import "reflect"
+import (
+	"context"
+	"reflect"
+)
 
 // FieldHandler field handler
 type FieldHandler struct {
@@ -62,15 +65,6 @@ type ExprHandler struct {
 	targetExpr *TagExpr
 }
 
-func newExprHandler(te, tte *TagExpr, base, es string) *ExprHandler {
-	return &ExprHandler{
-		base:       base,
-		selector:   es,
-		expr:       te,
-		targetExpr: tte,
-	}
-}
-
 // TagExpr returns the *TagExpr.
 func (e *ExprHandler) TagExpr() *TagExpr {
 	return e.expr
@@ -98,6 +92,12 @@ func (e *ExprHandler) Path() string {
 	return e.path
 }
 
+// OmitEmpty returns whether the expression is prefixed with '?', such as `vd:"?email($)"`,
+// which means it should be skipped when the field holds the zero value.
+func (e *ExprHandler) OmitEmpty() bool {
+	return e.expr.s.exprs[e.selector].omitEmpty
+}
+
 // Eval evaluate the value of the struct tag expression.
 // NOTE:
 //  result types: float64, string, bool, nil
@@ -105,6 +105,15 @@ func (e *ExprHandler) Eval() interface{} {
 	return e.expr.s.exprs[e.selector].run(e.base, e.targetExpr)
 }
 
+// EvalContext evaluates the value of the struct tag expression with the context,
+// which is passed to the functions registered by RegCtxFunc.
+// NOTE:
+//  result types: float64, string, bool, nil
+func (e *ExprHandler) EvalContext(ctx context.Context) interface{} {
+	e.targetExpr.ctx = ctx
+	return e.Eval()
+}
+
 // EvalFloat evaluates the value of the struct tag expression.
 // NOTE:
 //  If the expression value type is not float64, return 0.
@@ -0,0 +1,70 @@
+// Copyright 2019 Bytedance Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tagexpr
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lruCache the concurrency-safe LRU cache with the size limit,
+// such as the dynamic patterns of regexp function.
+type lruCache struct {
+	mu    sync.Mutex
+	size  int
+	list  *list.List
+	items map[string]*list.Element
+}
+
+type lruEntry struct {
+	key   string
+	value interface{}
+}
+
+func newLRUCache(size int) *lruCache {
+	return &lruCache{
+		size:  size,
+		list:  list.New(),
+		items: make(map[string]*list.Element, size),
+	}
+}
+
+// get returns the cached value of the key.
+func (c *lruCache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		c.list.MoveToFront(elem)
+		return elem.Value.(*lruEntry).value, true
+	}
+	return nil, false
+}
+
+// add caches the value of the key, and evicts the least recently used one if the cache is full.
+func (c *lruCache) add(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		elem.Value.(*lruEntry).value = value
+		c.list.MoveToFront(elem)
+		return
+	}
+	c.items[key] = c.list.PushFront(&lruEntry{key: key, value: value})
+	if c.list.Len() > c.size {
+		oldest := c.list.Back()
+		c.list.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+}
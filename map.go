@@ -0,0 +1,81 @@
+// Copyright 2019 Bytedance Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tagexpr
+
+import (
+	"strings"
+)
+
+// MapExpr the expression evaluated with a map instead of a struct,
+// such as the schemaless JSON object.
+type MapExpr struct {
+	expr *Expr
+}
+
+// MapExistsFuncName the name of the function which returns whether the map entry exists and is not nil,
+// such as `mapexists($)`.
+const MapExistsFuncName = "mapexists"
+
+// mapExprCache the LRU cache of the parsed expressions by the source
+var mapExprCache = newLRUCache(1024)
+
+// ParseMapExpr parses the expression evaluated with a map.
+// NOTE:
+//  The parsed expression is cached by the source, up to 1024 expressions.
+func ParseMapExpr(expr string) (*MapExpr, error) {
+	if m, ok := mapExprCache.get(expr); ok {
+		return m.(*MapExpr), nil
+	}
+	e, err := parseExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	m := &MapExpr{expr: e}
+	mapExprCache.add(expr, m)
+	return m, nil
+}
+
+// Eval evaluates the expression with the map @data.
+// NOTE:
+//  `$` is the value of @path, `(X.Y)$` is the value of the path X.Y from the root of the map;
+//  result types: float64, string, bool, nil
+func (m *MapExpr) Eval(data map[string]interface{}, path string) interface{} {
+	return m.expr.run(path, &TagExpr{data: data, path: path})
+}
+
+// CallsFunc returns whether the expression calls the function.
+func (m *MapExpr) CallsFunc(funcName string) bool {
+	for _, name := range m.expr.funcRefs {
+		if name == funcName {
+			return true
+		}
+	}
+	return false
+}
+
+// MapValue returns the value of the dotted @path in the map, and whether it exists.
+func MapValue(data map[string]interface{}, path string) (interface{}, bool) {
+	var v interface{} = data
+	for _, key := range strings.Split(path, FieldSeparator) {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if v, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
@@ -0,0 +1,47 @@
+package tagexpr
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapExpr(t *testing.T) {
+	data := map[string]interface{}{
+		"a": 1,
+		"b": map[string]interface{}{"c": "xyz", "d": []interface{}{1.0, 2.0}},
+	}
+	cases := []struct {
+		expr, path string
+		val        interface{}
+	}{
+		{expr: "$+1", path: "a", val: 2.0},
+		{expr: "len($)", path: "b.c", val: 3.0},
+		{expr: "$[1]", path: "b.d", val: 2.0},
+		{expr: "(a)$==1 && (b.c)$=='xyz'", path: "b", val: true},
+		{expr: "mapexists($)", path: "b.e", val: false},
+		{expr: "mapexists($)", path: "b.c", val: true},
+	}
+	for _, c := range cases {
+		m, err := ParseMapExpr(c.expr)
+		if assert.NoError(t, err) {
+			assert.Equal(t, c.val, m.Eval(data, c.path), c.expr)
+		}
+	}
+	m, _ := ParseMapExpr("mapexists($)")
+	assert.True(t, m.CallsFunc(MapExistsFuncName))
+	m, _ = ParseMapExpr("len($)>0")
+	assert.False(t, m.CallsFunc(MapExistsFuncName))
+	_, ok := MapValue(data, "b.x")
+	assert.False(t, ok)
+}
+
+func TestMapExprCache(t *testing.T) {
+	for i := 0; i < 2000; i++ {
+		_, err := ParseMapExpr("$>" + strconv.Itoa(i))
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 1024, mapExprCache.list.Len())
+	assert.Equal(t, 1024, len(mapExprCache.items))
+}
@@ -0,0 +1,666 @@
+// Copyright 2019 Bytedance Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tagexpr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// number the exact number of an operand,
+// which keeps the integer in the integer domain.
+type number struct {
+	kind reflect.Kind // reflect.Int64, reflect.Uint64 or reflect.Float64
+	i    int64
+	u    uint64
+	f    float64
+}
+
+// parseNumber parses the number literal.
+func parseNumber(s string) number {
+	if !strings.Contains(s, ".") {
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return number{kind: reflect.Int64, i: i}
+		}
+		if u, err := strconv.ParseUint(strings.TrimPrefix(s, "+"), 10, 64); err == nil {
+			return number{kind: reflect.Uint64, u: u}
+		}
+	}
+	f, _ := strconv.ParseFloat(s, 64)
+	return number{kind: reflect.Float64, f: f}
+}
+
+// numberOf returns the exact number of the value of number kind.
+func numberOf(v reflect.Value) (number, bool) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return number{}, false
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return number{kind: reflect.Int64, i: v.Int()}, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return number{kind: reflect.Uint64, u: v.Uint()}, true
+	case reflect.Float32, reflect.Float64:
+		return number{kind: reflect.Float64, f: v.Float()}, true
+	}
+	return number{}, false
+}
+
+// float returns the float64 of the number, which may lose the precision of the large integer.
+func (n number) float() float64 {
+	switch n.kind {
+	case reflect.Int64:
+		return float64(n.i)
+	case reflect.Uint64:
+		return float64(n.u)
+	}
+	return n.f
+}
+
+// negate returns the opposite number, and whether it is exact.
+func (n number) negate() (number, bool) {
+	switch n.kind {
+	case reflect.Int64:
+		if n.i == math.MinInt64 {
+			return number{kind: reflect.Uint64, u: 1 << 63}, true
+		}
+		return number{kind: reflect.Int64, i: -n.i}, true
+	case reflect.Uint64:
+		if n.u <= 1<<63 {
+			return number{kind: reflect.Int64, i: -int64(n.u)}, true
+		}
+		return number{}, false
+	}
+	return number{kind: reflect.Float64, f: -n.f}, true
+}
+
+// compareNumbers compares two numbers by their values rather than types.
+// NOTE:
+//  The result will be 0 if a == b, -1 if a < b, and +1 if a > b;
+//  If one of them is NaN, ok is false.
+func compareNumbers(a, b number) (r int, ok bool) {
+	switch a.kind {
+	case reflect.Int64:
+		switch b.kind {
+		case reflect.Int64:
+			return compareInt(a.i, b.i), true
+		case reflect.Uint64:
+			if a.i < 0 {
+				return -1, true
+			}
+			return compareUint(uint64(a.i), b.u), true
+		}
+		r, ok = compareFloatInt(b.f, a.i)
+		return -r, ok
+	case reflect.Uint64:
+		switch b.kind {
+		case reflect.Int64:
+			r, ok = compareNumbers(b, a)
+			return -r, ok
+		case reflect.Uint64:
+			return compareUint(a.u, b.u), true
+		}
+		r, ok = compareFloatUint(b.f, a.u)
+		return -r, ok
+	}
+	switch b.kind {
+	case reflect.Int64:
+		return compareFloatInt(a.f, b.i)
+	case reflect.Uint64:
+		return compareFloatUint(a.f, b.u)
+	}
+	if math.IsNaN(a.f) || math.IsNaN(b.f) {
+		return 0, false
+	}
+	return compareFloat(a.f, b.f), true
+}
+
+func compareInt(x, y int64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func compareUint(x, y uint64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// compareFloatInt compares the float with the integer without losing the precision of the integer.
+func compareFloatInt(f float64, i int64) (int, bool) {
+	switch {
+	case math.IsNaN(f):
+		return 0, false
+	case f < -(1 << 63):
+		return -1, true
+	case f >= 1<<63:
+		return 1, true
+	}
+	t := math.Trunc(f)
+	if r := compareInt(int64(t), i); r != 0 {
+		return r, true
+	}
+	return compareFloat(f, t), true
+}
+
+// compareFloatUint compares the float with the unsigned integer without losing the precision of the integer.
+func compareFloatUint(f float64, u uint64) (int, bool) {
+	switch {
+	case math.IsNaN(f):
+		return 0, false
+	case f < 0:
+		return -1, true
+	case f >= 1<<64:
+		return 1, true
+	}
+	t := math.Trunc(f)
+	if r := compareUint(uint64(t), u); r != 0 {
+		return r, true
+	}
+	return compareFloat(f, t), true
+}
+
+// exactNumberOf returns the exact number of the operand,
+// if it is a number literal or a field selector of number kind.
+func exactNumberOf(e ExprNode, currField string, tagExpr *TagExpr) (number, bool) {
+	switch x := e.(type) {
+	case *groupExprNode:
+		if x.boolOpposite != nil || x.rightOperand == nil {
+			return number{}, false
+		}
+		return exactNumberOf(x.rightOperand, currField, tagExpr)
+	case *digitalExprNode:
+		return x.num, x.exact
+	case *bitwiseExprNode:
+		return x.number(currField, tagExpr), true
+	case *mathFuncExprNode:
+		if x.boolOpposite != nil {
+			return number{}, false
+		}
+		return x.number(currField, tagExpr)
+	case *additionExprNode:
+		return exactArithmetic('+', x.leftOperand, x.rightOperand, currField, tagExpr)
+	case *subtractionExprNode:
+		return exactArithmetic('-', x.leftOperand, x.rightOperand, currField, tagExpr)
+	case *multiplicationExprNode:
+		return exactArithmetic('*', x.leftOperand, x.rightOperand, currField, tagExpr)
+	case *divisionExprNode:
+		return exactArithmetic('/', x.leftOperand, x.rightOperand, currField, tagExpr)
+	case *remainderExprNode:
+		return exactArithmetic('%', x.leftOperand, x.rightOperand, currField, tagExpr)
+	case *selectorExprNode:
+		if x.boolOpposite != nil || len(x.subExprs) > 0 || tagExpr == nil {
+			return number{}, false
+		}
+		field := x.field
+		if field == "" {
+			field = currField
+		}
+		n, ok := tagExpr.getNumber(field)
+		if ok && x.floatOpposite {
+			n, ok = n.negate()
+		}
+		return n, ok
+	case *aggregateFuncExprNode:
+		if x.boolOpposite != nil {
+			return number{}, false
+		}
+		return x.number(currField, tagExpr)
+	case *convFuncExprNode:
+		if x.boolOpposite != nil || x.funcName != "int" {
+			return number{}, false
+		}
+		return x.number(currField, tagExpr)
+	case *elemExprNode:
+		if x.boolOpposite != nil {
+			return number{}, false
+		}
+		v, ok := x.value(currField, tagExpr)
+		if !ok {
+			return number{}, false
+		}
+		return numberOf(v)
+	}
+	return number{}, false
+}
+
+// compareOperands evaluates the operands once, and compares them as the exact numbers or the strings,
+// exact is false if one of them is neither an exact number nor a string read by readString,
+// then @v0 and @v1 are the evaluated values.
+func compareOperands(left, right ExprNode, currField string, tagExpr *TagExpr) (r int, ok, exact bool, v0, v1 interface{}) {
+	s0, str0 := readString(left, currField, tagExpr)
+	s1, str1 := readString(right, currField, tagExpr)
+	switch {
+	case str0 && str1:
+		return strings.Compare(s0, s1), true, true, nil, nil
+	case str0:
+		v1 = right.Run(currField, tagExpr)
+		if s1, ok := v1.(string); ok {
+			return strings.Compare(s0, s1), true, true, nil, nil
+		}
+		return 0, false, false, s0, v1
+	case str1:
+		v0 = left.Run(currField, tagExpr)
+		if s0, ok := v0.(string); ok {
+			return strings.Compare(s0, s1), true, true, nil, nil
+		}
+		return 0, false, false, v0, s1
+	}
+	a, b, v0, v1, exact := evalOperands(left, right, currField, tagExpr)
+	if !exact {
+		return 0, false, false, v0, v1
+	}
+	r, ok = compareNumbers(a, b)
+	return r, ok, true, nil, nil
+}
+
+// smallFloats the boxed float64 of the small non-negative integers,
+// such as the common lengths.
+var smallFloats = func() (a [256]interface{}) {
+	for i := range a {
+		a[i] = float64(i)
+	}
+	return
+}()
+
+// boxFloat converts the float64 to interface{},
+// which does not allocate for the small non-negative integers.
+func boxFloat(f float64) interface{} {
+	if f >= 0 && f < float64(len(smallFloats)) && f == math.Trunc(f) && !math.Signbit(f) {
+		return smallFloats[int(f)]
+	}
+	return f
+}
+
+// bitwiseNumbers returns the result of the integer bitwise operator, such as '&' and '<<'.
+// NOTE:
+//  The int64 and uint64 operands are converted to the same kind, see unifyIntegers;
+//  The result of the shift has the kind of the left operand,
+//  except that the non-negative int64 shifted out of the int64 range is uint64, as the untyped constant of Go.
+func bitwiseNumbers(op string, a, b number) (number, error) {
+	if op == "<<" || op == ">>" {
+		return shiftNumber(op, a, b)
+	}
+	if a.kind != b.kind {
+		var err error
+		if a, b, err = unifyIntegers(a, b); err != nil {
+			return number{}, err
+		}
+	}
+	if a.kind == reflect.Int64 {
+		switch op {
+		case "&":
+			a.i &= b.i
+		case "|":
+			a.i |= b.i
+		case "^":
+			a.i ^= b.i
+		case "&^":
+			a.i &^= b.i
+		}
+		return a, nil
+	}
+	switch op {
+	case "&":
+		a.u &= b.u
+	case "|":
+		a.u |= b.u
+	case "^":
+		a.u ^= b.u
+	case "&^":
+		a.u &^= b.u
+	}
+	return a, nil
+}
+
+// unifyIntegers converts the int64 and the uint64 to the same kind,
+// the non-negative int64 is converted to uint64, and the uint64 is converted to the negative int64's kind if it fits.
+func unifyIntegers(a, b number) (number, number, error) {
+	if a.kind == reflect.Uint64 {
+		b, a, err := unifyIntegers(b, a)
+		return a, b, err
+	}
+	if a.i >= 0 {
+		return number{kind: reflect.Uint64, u: uint64(a.i)}, b, nil
+	}
+	if b.u <= math.MaxInt64 {
+		return a, number{kind: reflect.Int64, i: int64(b.u)}, nil
+	}
+	return a, b, fmt.Errorf("mismatched integers %d and %d", a.i, b.u)
+}
+
+func shiftNumber(op string, a, b number) (number, error) {
+	var n uint64
+	if b.kind == reflect.Int64 {
+		if b.i < 0 {
+			return number{}, fmt.Errorf("negative shift count %d", b.i)
+		}
+		n = uint64(b.i)
+	} else {
+		n = b.u
+	}
+	if a.kind == reflect.Uint64 {
+		if op == "<<" {
+			a.u <<= n
+		} else {
+			a.u >>= n
+		}
+		return a, nil
+	}
+	switch {
+	case op == ">>":
+		a.i >>= n
+	case a.i >= 0:
+		if u := uint64(a.i) << n; u > math.MaxInt64 {
+			return number{kind: reflect.Uint64, u: u}, nil
+		}
+		a.i <<= n
+	default:
+		a.i <<= n
+	}
+	return a, nil
+}
+
+var (
+	errDivisionByZero  = errors.New("division by zero")
+	errIntegerOverflow = errors.New("integer overflow")
+)
+
+// evalNumber evaluates the node once, and returns its exact number, see exactNumberOf,
+// or the evaluated value @v if it is not an exact number.
+func evalNumber(e ExprNode, currField string, tagExpr *TagExpr) (n number, v interface{}, exact bool) {
+	switch x := e.(type) {
+	case *groupExprNode:
+		if x.boolOpposite == nil && x.rightOperand != nil {
+			return evalNumber(x.rightOperand, currField, tagExpr)
+		}
+	case *digitalExprNode:
+		if x.exact {
+			return x.num, nil, true
+		}
+	case *bitwiseExprNode:
+		return x.number(currField, tagExpr), nil, true
+	case *additionExprNode:
+		return evalArithmetic('+', x.leftOperand, x.rightOperand, currField, tagExpr)
+	case *subtractionExprNode:
+		return evalArithmetic('-', x.leftOperand, x.rightOperand, currField, tagExpr)
+	case *multiplicationExprNode:
+		return evalArithmetic('*', x.leftOperand, x.rightOperand, currField, tagExpr)
+	case *divisionExprNode:
+		return evalArithmetic('/', x.leftOperand, x.rightOperand, currField, tagExpr)
+	case *remainderExprNode:
+		return evalArithmetic('%', x.leftOperand, x.rightOperand, currField, tagExpr)
+	case *selectorExprNode:
+		// reading the field is not an evaluation
+		if n, ok := exactNumberOf(x, currField, tagExpr); ok {
+			return n, nil, true
+		}
+	case *mathFuncExprNode:
+		if x.boolOpposite == nil {
+			n, ok := x.number(currField, tagExpr)
+			return n, nil, ok
+		}
+	case *aggregateFuncExprNode:
+		if x.boolOpposite == nil {
+			n, ok := x.number(currField, tagExpr)
+			return n, nil, ok
+		}
+	case *convFuncExprNode:
+		if x.boolOpposite == nil && x.funcName == "int" {
+			n, ok := x.number(currField, tagExpr)
+			return n, nil, ok
+		}
+	case *elemExprNode:
+		if x.boolOpposite == nil {
+			rv, ok := x.value(currField, tagExpr)
+			if !ok {
+				return number{}, nil, false
+			}
+			if n, ok := numberOf(rv); ok {
+				return n, nil, true
+			}
+			return number{}, elemValue(rv), false
+		}
+	}
+	return number{}, e.Run(currField, tagExpr), false
+}
+
+// evalArithmetic evaluates the operands once, and returns the exact result of the arithmetic operator
+// if both operands are exact numbers, otherwise the result @v of the evaluated values, see arithmeticValues.
+// NOTE:
+//  panic if the integer overflows or is divided by zero.
+func evalArithmetic(op byte, left, right ExprNode, currField string, tagExpr *TagExpr) (n number, v interface{}, exact bool) {
+	a, b, v0, v1, exact := evalOperands(left, right, currField, tagExpr)
+	if !exact {
+		return number{}, arithmeticValues(op, v0, v1), false
+	}
+	n, err := arithmeticNumbers(op, a, b)
+	if err != nil {
+		panic(err)
+	}
+	return n, nil, true
+}
+
+// evalOperands evaluates the operands once, and returns their exact numbers if both of them are exact numbers,
+// otherwise the evaluated values @v0 and @v1, in which the exact number is float64.
+func evalOperands(left, right ExprNode, currField string, tagExpr *TagExpr) (a, b number, v0, v1 interface{}, exact bool) {
+	a, v0, exact0 := evalNumber(left, currField, tagExpr)
+	b, v1, exact1 := evalNumber(right, currField, tagExpr)
+	if exact0 && exact1 {
+		return a, b, nil, nil, true
+	}
+	if exact0 {
+		v0 = boxFloat(a.float())
+	}
+	if exact1 {
+		v1 = boxFloat(b.float())
+	}
+	return a, b, v0, v1, false
+}
+
+// exactArithmetic returns the exact result of the arithmetic operator,
+// if both operands are exact numbers, see exactNumberOf.
+// NOTE:
+//  panic if the integer overflows or is divided by zero.
+func exactArithmetic(op byte, left, right ExprNode, currField string, tagExpr *TagExpr) (number, bool) {
+	a, ok := exactNumberOf(left, currField, tagExpr)
+	if !ok {
+		return number{}, false
+	}
+	b, ok := exactNumberOf(right, currField, tagExpr)
+	if !ok {
+		return number{}, false
+	}
+	n, err := arithmeticNumbers(op, a, b)
+	if err != nil {
+		panic(err)
+	}
+	return n, true
+}
+
+// arithmeticNumbers returns the result of the arithmetic operator, such as '+' and '%'.
+// NOTE:
+//  The integers are calculated as Go int64 or uint64, e.g. 7/2 is 3 and -7%3 is -1,
+//  and the result out of both ranges is errIntegerOverflow;
+//  If one of the operands is float64, they are calculated as float64, and '%' is math.Mod;
+//  The division by zero is errDivisionByZero.
+func arithmeticNumbers(op byte, a, b number) (number, error) {
+	if a.kind == reflect.Float64 || b.kind == reflect.Float64 {
+		f, err := arithmeticFloats(op, a.float(), b.float())
+		return number{kind: reflect.Float64, f: f}, err
+	}
+	if (op == '/' || op == '%') && b.i == 0 && b.u == 0 {
+		return number{}, errDivisionByZero
+	}
+	if a.kind == reflect.Int64 && b.kind == reflect.Int64 {
+		if i, ok := arithmeticInt64(op, a.i, b.i); ok {
+			return number{kind: reflect.Int64, i: i}, nil
+		}
+	}
+	// the mixed or overflowing integers
+	x, y := a.bigInt(), b.bigInt()
+	switch op {
+	case '+':
+		x.Add(x, y)
+	case '-':
+		x.Sub(x, y)
+	case '*':
+		x.Mul(x, y)
+	case '/':
+		x.Quo(x, y)
+	case '%':
+		x.Rem(x, y)
+	}
+	switch {
+	case x.IsInt64():
+		return number{kind: reflect.Int64, i: x.Int64()}, nil
+	case x.IsUint64():
+		return number{kind: reflect.Uint64, u: x.Uint64()}, nil
+	}
+	return number{}, errIntegerOverflow
+}
+
+// arithmeticInt64 returns the result of the int64 operands, and false if it overflows.
+func arithmeticInt64(op byte, a, b int64) (int64, bool) {
+	switch op {
+	case '+':
+		r := a + b
+		return r, (r > a) == (b > 0)
+	case '-':
+		r := a - b
+		return r, (r < a) == (b > 0)
+	case '*':
+		if a == 0 || b == 0 {
+			return 0, true
+		}
+		r := a * b
+		return r, r/b == a && !(b == -1 && a == math.MinInt64)
+	case '/':
+		return a / b, !(a == math.MinInt64 && b == -1)
+	case '%':
+		if b == -1 {
+			return 0, true
+		}
+		return a % b, true
+	}
+	return 0, false
+}
+
+// arithmeticFloats returns the result of the float64 operands.
+func arithmeticFloats(op byte, a, b float64) (float64, error) {
+	switch op {
+	case '+':
+		return a + b, nil
+	case '-':
+		return a - b, nil
+	case '*':
+		return a * b, nil
+	case '/':
+		if b == 0 {
+			return 0, errDivisionByZero
+		}
+		return a / b, nil
+	case '%':
+		if b == 0 {
+			return 0, errDivisionByZero
+		}
+		return math.Mod(a, b), nil
+	}
+	return 0, nil
+}
+
+// bigInt returns the big.Int of the integer.
+func (n number) bigInt() *big.Int {
+	if n.kind == reflect.Uint64 {
+		return new(big.Int).SetUint64(n.u)
+	}
+	return big.NewInt(n.i)
+}
+
+// absNumber returns the absolute value, e.g. the absolute value of math.MinInt64 is uint64.
+func absNumber(a []number) number {
+	n := a[0]
+	switch {
+	case n.kind == reflect.Float64:
+		n.f = math.Abs(n.f)
+	case n.kind == reflect.Int64 && n.i < 0:
+		if n.i == math.MinInt64 {
+			return number{kind: reflect.Uint64, u: 1 << 63}
+		}
+		n.i = -n.i
+	}
+	return n
+}
+
+// roundNumber returns the float rounded by @round, such as math.Ceil, and the integer unchanged.
+func roundNumber(n number, round func(float64) float64) number {
+	if n.kind == reflect.Float64 {
+		n.f = round(n.f)
+	}
+	return n
+}
+
+// extremeNumber returns the minimum number if @sign is -1, or the maximum one if 1,
+// which keeps its kind, and NaN if one of them is NaN.
+func extremeNumber(a []number, sign int) number {
+	r := a[0]
+	for _, n := range a {
+		if n.kind == reflect.Float64 && math.IsNaN(n.f) {
+			return n
+		}
+	}
+	for _, n := range a[1:] {
+		if c, _ := compareNumbers(n, r); c == sign {
+			r = n
+		}
+	}
+	return r
+}
+
+// powNumbers returns a**b, which is the integer if both are integers, b >= 0 and the result is in the integer ranges,
+// otherwise math.Pow.
+func powNumbers(a, b number) number {
+	if a.kind != reflect.Float64 && b.kind != reflect.Float64 && (b.kind == reflect.Uint64 || b.i >= 0) {
+		x, y := a.bigInt(), b.bigInt()
+		// the base other than 0, 1 and -1 overflows with the exponent greater than 64
+		if y.Cmp(big.NewInt(64)) <= 0 || x.CmpAbs(big.NewInt(1)) <= 0 {
+			x.Exp(x, y, nil)
+			switch {
+			case x.IsInt64():
+				return number{kind: reflect.Int64, i: x.Int64()}
+			case x.IsUint64():
+				return number{kind: reflect.Uint64, u: x.Uint64()}
+			}
+		}
+	}
+	return number{kind: reflect.Float64, f: math.Pow(a.float(), b.float())}
+}
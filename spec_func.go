@@ -15,10 +15,16 @@
 package tagexpr
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/henrylee2cn/goutil/errors"
 )
@@ -34,6 +40,25 @@ var funcList = map[string]func(p *Expr, expr *string) ExprNode{}
 //  The go number types always are float64;
 //  The go string types always are string.
 func RegFunc(funcName string, fn func(...interface{}) interface{}, force ...bool) error {
+	if len(force) == 0 || !force[0] {
+		_, ok := funcList[funcName]
+		if ok {
+			return errors.Errorf("duplicate registration expression function: %s", funcName)
+		}
+	}
+	funcList[funcName] = newFunc(funcName, func(_ context.Context, args ...interface{}) interface{} {
+		return fn(args...)
+	})
+	return nil
+}
+
+// RegCtxFunc registers function expression which receives the context of the evaluation.
+// NOTE:
+//  The context is set by ExprHandler.EvalContext, the default is context.Background();
+//  If @force=true, allow to cover the existed same @funcName;
+//  The go number types always are float64;
+//  The go string types always are string.
+func RegCtxFunc(funcName string, fn func(ctx context.Context, args ...interface{}) interface{}, force ...bool) error {
 	if len(force) == 0 || !force[0] {
 		_, ok := funcList[funcName]
 		if ok {
@@ -44,7 +69,132 @@ func RegFunc(funcName string, fn func(...interface{}) interface{}, force ...bool
 	return nil
 }
 
-func newFunc(funcName string, fn func(...interface{}) interface{}) func(*Expr, *string) ExprNode {
+// RegErrFunc registers function expression which returns the value or an error,
+// e.g. to report a failed lookup, which is an internal error instead of false.
+// NOTE:
+//  The returned error aborts the evaluation, and the value of the expression is *FuncError,
+//  which is false in the boolean context;
+//  If @force=true, allow to cover the existed same @funcName;
+//  The go number types always are float64;
+//  The go string types always are string.
+func RegErrFunc(funcName string, fn func(...interface{}) (interface{}, error), force ...bool) error {
+	if len(force) == 0 || !force[0] {
+		_, ok := funcList[funcName]
+		if ok {
+			return errors.Errorf("duplicate registration expression function: %s", funcName)
+		}
+	}
+	funcList[funcName] = newFunc(funcName, func(_ context.Context, args ...interface{}) interface{} {
+		r, err := fn(args...)
+		if err != nil {
+			panic(&FuncError{Func: funcName, Err: err})
+		}
+		return r
+	})
+	return nil
+}
+
+// RegStringFunc registers function expression of a string argument, e.g. email($),
+// which reads the string field without boxing it.
+// NOTE:
+//  If the argument is not a string or the number of arguments is not 1, the result is nil;
+//  If @force=true, allow to cover the existed same @funcName.
+func RegStringFunc(funcName string, fn func(string) interface{}, force ...bool) error {
+	return regStringFunc(funcName, func(args ...interface{}) interface{} {
+		if len(args) != 1 {
+			return nil
+		}
+		s, ok := args[0].(string)
+		if !ok {
+			return nil
+		}
+		return fn(s)
+	}, fn, force...)
+}
+
+// regStringFunc registers function expression, which calls @strFn instead of @fn for the single string argument,
+// so they should return the same result for it.
+func regStringFunc(funcName string, fn func(...interface{}) interface{}, strFn func(string) interface{}, force ...bool) error {
+	err := RegFunc(funcName, fn, force...)
+	if err != nil {
+		return err
+	}
+	parse := funcList[funcName]
+	funcList[funcName] = func(p *Expr, expr *string) ExprNode {
+		e := parse(p, expr)
+		if f, ok := e.(*funcExprNode); ok {
+			f.strFn = strFn
+		}
+		return e
+	}
+	return nil
+}
+
+// SetFuncArity fixes the number of arguments of the registered function,
+// so that the expression calling it with a different number is a syntax error
+// when the struct type is registered, instead of being evaluated.
+// NOTE:
+//  The registered functions are variadic by default;
+//  It should be called after the function is registered, and is reset by the forced re-registration.
+func SetFuncArity(funcName string, arity int) error {
+	parse, ok := funcList[funcName]
+	if !ok {
+		return errors.Errorf("unregistered expression function: %s", funcName)
+	}
+	if arity < 0 {
+		return errors.Errorf("invalid arity of expression function %s: %d", funcName, arity)
+	}
+	funcList[funcName] = func(p *Expr, expr *string) ExprNode {
+		if f := readFixedFunc(parse, arity, p, expr); f != nil {
+			return f
+		}
+		return nil
+	}
+	return nil
+}
+
+// readFixedFunc reads the function by @parse, which requires @arity arguments,
+// and restores the expression if the number of arguments is different.
+// NOTE:
+//  If arity<0, at least one argument is required.
+func readFixedFunc(parse func(*Expr, *string) ExprNode, arity int, p *Expr, expr *string) *funcExprNode {
+	last := *expr
+	refs := len(p.funcRefs)
+	e := parse(p, expr)
+	if e == nil {
+		return nil
+	}
+	if f, ok := e.(*funcExprNode); ok {
+		if n := f.numArgs(); arity >= 0 && n == arity || arity < 0 && n > 0 {
+			return f
+		}
+	}
+	*expr = last
+	p.funcRefs = p.funcRefs[:refs]
+	return nil
+}
+
+// FuncError the error returned by the function registered by RegErrFunc, which aborts the evaluation.
+type FuncError struct {
+	// Func the name of the function
+	Func string
+	// Expr the source of the expression
+	Expr string
+	// Err the error returned by the function
+	Err error
+}
+
+// Error implements error interface.
+func (e *FuncError) Error() string {
+	return fmt.Sprintf("function %s in %q: %s", e.Func, e.Expr, e.Err.Error())
+}
+
+// Unwrap returns the error returned by the function.
+func (e *FuncError) Unwrap() error {
+	return e.Err
+}
+
+func newFunc(funcName string, fn func(context.Context, ...interface{}) interface{}) func(*Expr, *string) ExprNode {
 	prefix := funcName + "("
 	length := len(funcName)
 	return func(p *Expr, expr *string) ExprNode {
@@ -59,6 +209,7 @@ func newFunc(funcName string, fn func(...interface{}) interface{}) func(*Expr, *
 			return nil
 		}
 		f := &funcExprNode{
+			name:         funcName,
 			fn:           fn,
 			boolOpposite: boolOpposite,
 		}
@@ -80,6 +231,7 @@ func newFunc(funcName string, fn func(...interface{}) interface{}) func(*Expr, *
 			}
 			trimLeftSpace(subExprNode)
 			if len(*subExprNode) == 0 {
+				p.funcRefs = append(p.funcRefs, funcName)
 				return f
 			}
 		}
@@ -88,12 +240,29 @@ func newFunc(funcName string, fn func(...interface{}) interface{}) func(*Expr, *
 
 type funcExprNode struct {
 	exprBackground
+	name         string
 	args         []ExprNode
-	fn           func(...interface{}) interface{}
+	fn           func(context.Context, ...interface{}) interface{}
 	boolOpposite *bool
+	// strFn the fast path of fn for the single string argument, which is read by readString without boxing it,
+	// see RegStringFunc
+	strFn func(string) interface{}
+}
+
+// numArgs returns the number of the arguments, e.g. 0 for f().
+func (f *funcExprNode) numArgs() int {
+	if len(f.args) == 1 && f.args[0].RightOperand() == nil {
+		return 0
+	}
+	return len(f.args)
 }
 
 func (f *funcExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
+	if f.strFn != nil && len(f.args) == 1 {
+		if s, ok := readString(f.args[0], currField, tagExpr); ok {
+			return realValue(f.strFn(s), f.boolOpposite)
+		}
+	}
 	var args []interface{}
 	if n := len(f.args); n > 0 {
 		args = make([]interface{}, n)
@@ -101,35 +270,955 @@ func (f *funcExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
 			args[k] = v.Run(currField, tagExpr)
 		}
 	}
-	return realValue(f.fn(args...), f.boolOpposite)
+	return realValue(f.fn(tagExpr.Context(), args...), f.boolOpposite)
 }
 
 // --------------------------- Built-in function ---------------------------
 func init() {
 	funcList["regexp"] = readRegexpFuncExprNode
 	funcList["sprintf"] = readSprintfFuncExprNode
-	err := RegFunc("len", func(args ...interface{}) interface{} {
+	funcList["datetime"] = readDatetimeFuncExprNode
+	funcList["all"] = newQuantifierFunc("all", true)
+	funcList["any"] = newQuantifierFunc("any", false)
+	for _, funcName := range []string{"sum", "count", "avg"} {
+		funcList[funcName] = newAggregateFunc(funcName)
+	}
+	for _, funcName := range []string{"int", "float", "string", "bool"} {
+		funcList[funcName] = newConvFunc(funcName)
+	}
+	for funcName, cmp := range map[string]func(int, bool) bool{
+		"eqfield":  func(r int, ok bool) bool { return ok && r == 0 },
+		"nefield":  func(r int, ok bool) bool { return !ok || r != 0 },
+		"gtfield":  func(r int, ok bool) bool { return ok && r > 0 },
+		"gtefield": func(r int, ok bool) bool { return ok && r >= 0 },
+		"ltfield":  func(r int, ok bool) bool { return ok && r < 0 },
+		"ltefield": func(r int, ok bool) bool { return ok && r <= 0 },
+	} {
+		funcList[funcName] = newFieldCmpFunc(funcName, cmp)
+	}
+	for funcName, strLen := range map[string]func(string) int{
+		"len":         func(s string) int { return len(s) },
+		"mblen":       utf8.RuneCountInString,
+		"runelen":     utf8.RuneCountInString,
+		"graphemelen": graphemeCount,
+	} {
+		fn, strFn := newLenFunc(strLen)
+		err := regStringFunc(funcName, fn, strFn, true)
+		if err != nil {
+			panic(err)
+		}
+	}
+	for funcName, transform := range map[string]func(s string) string{
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+		"trim":  strings.TrimSpace,
+	} {
+		transform := transform
+		funcList[funcName] = newStrTransformFunc(funcName, 1, func(s, _ string) string { return transform(s) })
+	}
+	for funcName, transform := range map[string]func(s, affix string) string{
+		"trimPrefix": strings.TrimPrefix,
+		"trimSuffix": strings.TrimSuffix,
+	} {
+		funcList[funcName] = newStrTransformFunc(funcName, 2, transform)
+	}
+	for funcName, match := range map[string]func(s, substr string) bool{
+		"contains":  strings.Contains,
+		"hasPrefix": strings.HasPrefix,
+		"hasSuffix": strings.HasSuffix,
+	} {
+		funcList[funcName] = newStrMatchFunc(funcName, match)
+	}
+	for funcName, fn := range map[string]func(...interface{}) interface{}{
+		MapExistsFuncName: func(args ...interface{}) interface{} {
+			return len(args) == 1 && args[0] != nil
+		},
+		"now": func(...interface{}) interface{} {
+			return timeNow()
+		},
+		"before": func(args ...interface{}) interface{} {
+			r, ok := compareTime(args)
+			return ok && r < 0
+		},
+		"after": func(args ...interface{}) interface{} {
+			r, ok := compareTime(args)
+			return ok && r > 0
+		},
+		"age": func(args ...interface{}) interface{} {
+			if len(args) != 1 {
+				return nil
+			}
+			birthday, ok := toTime(args[0])
+			if !ok {
+				return nil
+			}
+			now := timeNow().In(birthday.Location())
+			age := now.Year() - birthday.Year()
+			if now.Month() < birthday.Month() ||
+				(now.Month() == birthday.Month() && now.Day() < birthday.Day()) {
+				age--
+			}
+			return float64(age)
+		},
+	} {
+		err := RegFunc(funcName, fn, true)
+		if err != nil {
+			panic(err)
+		}
+	}
+	for funcName, fn := range map[string]func([]number) number{
+		"abs":   absNumber,
+		"ceil":  func(a []number) number { return roundNumber(a[0], math.Ceil) },
+		"floor": func(a []number) number { return roundNumber(a[0], math.Floor) },
+		"round": func(a []number) number { return roundNumber(a[0], math.Round) },
+	} {
+		funcList[funcName] = newMathFunc(funcName, 1, fn)
+	}
+	funcList["min"] = newMathFunc("min", -1, func(a []number) number { return extremeNumber(a, -1) })
+	funcList["max"] = newMathFunc("max", -1, func(a []number) number { return extremeNumber(a, 1) })
+	funcList["pow"] = newMathFunc("pow", 2, func(a []number) number { return powNumbers(a[0], a[1]) })
+	for funcName, fn := range map[string]func(...interface{}) interface{}{
+		"split": splitFunc,
+		"join":  joinFunc,
+		"index": indexFunc,
+	} {
+		err := RegFunc(funcName, fn, true)
+		if err == nil {
+			err = SetFuncArity(funcName, 2)
+		}
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// splitFunc returns the []string of the string split by the separator, such as split('a,b', ',').
+// NOTE:
+//  The empty string is split into the empty slice, instead of [""] as strings.Split;
+//  The empty segments are kept, e.g. 'a,b,' is split into ["a", "b", ""];
+//  The non-string arguments have no value, so the result is nil.
+func splitFunc(args ...interface{}) interface{} {
+	s, ok1 := args[0].(string)
+	sep, ok2 := args[1].(string)
+	if !ok1 || !ok2 {
+		return nil
+	}
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, sep)
+}
+
+// joinFunc returns the string of the elements of the slice or array joined by the separator,
+// such as join((X)$, ','), the number and bool elements are formatted, e.g. '1,2.5,true'.
+// NOTE:
+//  The nil element is the empty string;
+//  The argument which is not a slice or array, or an element of the other types has no value,
+//  so the result is nil.
+func joinFunc(args ...interface{}) interface{} {
+	sep, ok := args[1].(string)
+	if !ok {
+		return nil
+	}
+	if a, ok := args[0].([]string); ok {
+		return strings.Join(a, sep)
+	}
+	v := reflect.ValueOf(args[0])
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil
+	}
+	var b strings.Builder
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			b.WriteString(sep)
+		}
+		switch e := elemValue(v.Index(i)).(type) {
+		case nil:
+		case string:
+			b.WriteString(e)
+		case float64:
+			b.WriteString(strconv.FormatFloat(e, 'f', -1, 64))
+		case bool:
+			b.WriteString(strconv.FormatBool(e))
+		default:
+			return nil
+		}
+	}
+	return b.String()
+}
+
+// indexFunc returns the byte index of the first substring in the string, such as index('a,b', ','),
+// or -1 if not present or the arguments are not strings.
+func indexFunc(args ...interface{}) interface{} {
+	s, ok1 := args[0].(string)
+	substr, ok2 := args[1].(string)
+	if !ok1 || !ok2 {
+		return float64(-1)
+	}
+	return float64(strings.Index(s, substr))
+}
+
+// strMatchFuncExprNode the function which matches the string with the substring, such as contains(s, sub).
+type strMatchFuncExprNode struct {
+	exprBackground
+	s, substr    ExprNode
+	match        func(s, substr string) bool
+	boolOpposite *bool
+}
+
+// newStrMatchFunc returns the parser of the function which matches the string with the substring by @match,
+// such as contains(s, sub), which requires two arguments.
+func newStrMatchFunc(funcName string, match func(s, substr string) bool) func(*Expr, *string) ExprNode {
+	parse := newFunc(funcName, nil)
+	return func(p *Expr, expr *string) ExprNode {
+		f := readFixedFunc(parse, 2, p, expr)
+		if f == nil {
+			return nil
+		}
+		return &strMatchFuncExprNode{
+			s:            f.args[0],
+			substr:       f.args[1],
+			match:        match,
+			boolOpposite: f.boolOpposite,
+		}
+	}
+}
+
+// Run returns false for the non-string arguments.
+func (f *strMatchFuncExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
+	s, ok1 := f.s.Run(currField, tagExpr).(string)
+	substr, ok2 := f.substr.Run(currField, tagExpr).(string)
+	return realValue(ok1 && ok2 && f.match(s, substr), f.boolOpposite)
+}
+
+// strTransformFuncExprNode the function which transforms the string, such as lower(s) and trimPrefix(s, p).
+type strTransformFuncExprNode struct {
+	exprBackground
+	// arg the second argument, nil for the unary function
+	s, arg       ExprNode
+	transform    func(s, arg string) string
+	boolOpposite *bool
+}
+
+// newStrTransformFunc returns the parser of the function which transforms the string by @transform,
+// which requires @arity (1 or 2) arguments.
+func newStrTransformFunc(funcName string, arity int, transform func(s, arg string) string) func(*Expr, *string) ExprNode {
+	parse := newFunc(funcName, nil)
+	return func(p *Expr, expr *string) ExprNode {
+		f := readFixedFunc(parse, arity, p, expr)
+		if f == nil {
+			return nil
+		}
+		e := &strTransformFuncExprNode{
+			s:            f.args[0],
+			transform:    transform,
+			boolOpposite: f.boolOpposite,
+		}
+		if arity == 2 {
+			e.arg = f.args[1]
+		}
+		return e
+	}
+}
+
+// Run returns the non-string argument unchanged, or nil in strict mode.
+func (f *strTransformFuncExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
+	v := f.s.Run(currField, tagExpr)
+	s, ok := v.(string)
+	var arg string
+	if ok && f.arg != nil {
+		arg, ok = f.arg.Run(currField, tagExpr).(string)
+	}
+	if !ok {
+		if tagExpr != nil && tagExpr.s != nil && tagExpr.s.vm.strict {
+			v = nil
+		}
+		return realValue(v, f.boolOpposite)
+	}
+	return realValue(f.transform(s, arg), f.boolOpposite)
+}
+
+// mathFuncExprNode the math function, such as abs(x) and max(a, b, c),
+// which keeps the integer arguments exact, so the result is compared exactly, see exactNumberOf.
+type mathFuncExprNode struct {
+	exprBackground
+	args         []ExprNode
+	fn           func([]number) number
+	boolOpposite *bool
+}
+
+// newMathFunc returns the parser of the math function calculated by @fn,
+// which requires @arity arguments, or at least one if @arity is -1.
+func newMathFunc(funcName string, arity int, fn func([]number) number) func(*Expr, *string) ExprNode {
+	parse := newFunc(funcName, nil)
+	return func(p *Expr, expr *string) ExprNode {
+		f := readFixedFunc(parse, arity, p, expr)
+		if f == nil {
+			return nil
+		}
+		return &mathFuncExprNode{
+			args:         f.args,
+			fn:           fn,
+			boolOpposite: f.boolOpposite,
+		}
+	}
+}
+
+// Run returns nil if one of the arguments is not a number.
+func (f *mathFuncExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
+	n, ok := f.number(currField, tagExpr)
+	if !ok {
+		return realValue(nil, f.boolOpposite)
+	}
+	return realValue(boxFloat(n.float()), f.boolOpposite)
+}
+
+// number returns the exact result, and false if one of the arguments is not a number.
+// NOTE:
+//  The argument which is not an exact number, such as the result of len(), is float64.
+func (f *mathFuncExprNode) number(currField string, tagExpr *TagExpr) (number, bool) {
+	a := make([]number, len(f.args))
+	for i, arg := range f.args {
+		n, v, ok := evalNumber(arg, currField, tagExpr)
+		if !ok {
+			v, isFloat := v.(float64)
+			if !isFloat {
+				return number{}, false
+			}
+			n = number{kind: reflect.Float64, f: v}
+		}
+		a[i] = n
+	}
+	return f.fn(a), true
+}
+
+// elemFunc the function which evaluates the sub-expression for each element of the slice, array or map,
+// in which '#v' is the element and '#k' is its index or map key,
+// such as all(list, 'pred') and sum(list, 'expr').
+type elemFunc struct {
+	list ExprNode
+	// sub the sub-expression parsed at parse time, if it is a string literal
+	sub *Expr
+	// subSrc the operand of the dynamic sub-expression, if sub==nil
+	subSrc       ExprNode
+	boolOpposite *bool
+}
+
+// readElemFunc parses the function of the list and the sub-expression, such as all(list, 'pred').
+func readElemFunc(parse func(*Expr, *string) ExprNode, p *Expr, expr *string) *elemFunc {
+	last := *expr
+	refs := len(p.funcRefs)
+	f := readFixedFunc(parse, 2, p, expr)
+	if f == nil {
+		return nil
+	}
+	e := &elemFunc{
+		list:         f.args[0],
+		boolOpposite: f.boolOpposite,
+	}
+	lit, ok := literalOf(f.args[1])
+	if !ok {
+		e.subSrc = f.args[1]
+		return e
+	}
+	// The literal sub-expression is parsed once
+	src, ok := lit.(string)
+	var sub *Expr
+	var err error
+	if ok {
+		sub, err = parseElemExpr(src)
+	}
+	if !ok || err != nil {
+		*expr = last
+		p.funcRefs = p.funcRefs[:refs]
+		return nil
+	}
+	e.sub = sub
+	p.fieldRefs = append(p.fieldRefs, sub.fieldRefs...)
+	p.funcRefs = append(p.funcRefs, sub.funcRefs...)
+	p.selectorRefs = append(p.selectorRefs, sub.selectorRefs...)
+	p.ancestorRefs = append(p.ancestorRefs, sub.ancestorRefs...)
+	p.rootRefs = append(p.rootRefs, sub.rootRefs...)
+	return e
+}
+
+// elemExprCache the LRU cache of the dynamic sub-expressions of the quantifier functions,
+// the value is the error if the sub-expression is invalid
+var elemExprCache = newLRUCache(256)
+
+// compileElemExpr returns the parsed sub-expression from the cache, or parses and caches it.
+func compileElemExpr(src string) (*Expr, error) {
+	if v, ok := elemExprCache.get(src); ok {
+		if err, ok := v.(error); ok {
+			return nil, err
+		}
+		return v.(*Expr), nil
+	}
+	sub, err := parseElemExpr(src)
+	if err != nil {
+		elemExprCache.add(src, err)
+		return nil, err
+	}
+	elemExprCache.add(src, sub)
+	return sub, nil
+}
+
+// rangeElems calls fn with the sub-expression for each element until fn returns false,
+// and the element is bound to the tagExpr passed to fn.
+// NOTE:
+//  The nil pointer is the empty list;
+//  If the list is not a slice, array or map, or the dynamic sub-expression is not a string, return false;
+//  It panics if the dynamic sub-expression has a syntax error, so the evaluation faults.
+func (f *elemFunc) rangeElems(currField string, tagExpr *TagExpr, fn func(sub ExprNode, tagExpr *TagExpr) bool) bool {
+	sub := f.sub
+	if sub == nil {
+		src, ok := f.subSrc.Run(currField, tagExpr).(string)
+		if !ok {
+			return false
+		}
+		var err error
+		if sub, err = compileElemExpr(src); err != nil {
+			panic(err)
+		}
+	}
+	list := reflect.ValueOf(f.list.Run(currField, tagExpr))
+	for list.Kind() == reflect.Ptr || list.Kind() == reflect.Interface {
+		if list.IsNil() {
+			return true
+		}
+		list = list.Elem()
+	}
+	if tagExpr == nil {
+		tagExpr = &TagExpr{}
+	}
+	n := len(tagExpr.elems)
+	defer func() {
+		tagExpr.elems = tagExpr.elems[:n]
+	}()
+	bind := func(key, value reflect.Value) bool {
+		tagExpr.elems = append(tagExpr.elems[:n], elemBinding{key: key, value: value})
+		return fn(sub.expr, tagExpr)
+	}
+	switch list.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < list.Len(); i++ {
+			if !bind(reflect.ValueOf(float64(i)), list.Index(i)) {
+				break
+			}
+		}
+	case reflect.Map:
+		iter := list.MapRange()
+		for iter.Next() {
+			if !bind(iter.Key(), iter.Value()) {
+				break
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// quantifierFuncExprNode the quantifier function, i.e. all(list, 'pred') and any(list, 'pred').
+// NOTE:
+//  all() is true for the empty list, and any() is false;
+//  The list which is not a slice, array or map is false.
+type quantifierFuncExprNode struct {
+	exprBackground
+	*elemFunc
+	all bool
+}
+
+// newQuantifierFunc returns the parser of the quantifier function,
+// which is true if all the elements match if @all, otherwise if any element matches.
+func newQuantifierFunc(funcName string, all bool) func(*Expr, *string) ExprNode {
+	parse := newFunc(funcName, nil)
+	return func(p *Expr, expr *string) ExprNode {
+		f := readElemFunc(parse, p, expr)
+		if f == nil {
+			return nil
+		}
+		return &quantifierFuncExprNode{elemFunc: f, all: all}
+	}
+}
+
+func (f *quantifierFuncExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
+	r := f.all
+	ok := f.rangeElems(currField, tagExpr, func(sub ExprNode, tagExpr *TagExpr) bool {
+		if FakeBool(sub.Run(currField, tagExpr)) != f.all {
+			r = !f.all
+			return false
+		}
+		return true
+	})
+	return realValue(ok && r, f.boolOpposite)
+}
+
+// aggregateFuncExprNode the aggregate function, i.e. sum(list, 'expr'), count(list, 'pred') and avg(list, 'expr'),
+// which keeps the integer sum and count exact, so the result is compared exactly, see exactNumberOf.
+// NOTE:
+//  sum() and count() are 0 for the empty list, and avg() is nil;
+//  avg() is float64, e.g. 1.5 for 1 and 2;
+//  If the list is not a slice, array or map, or one of the summed values is not a number, the result is nil;
+//  The integer sum out of the int64 and uint64 ranges is errIntegerOverflow, so the evaluation faults.
+type aggregateFuncExprNode struct {
+	exprBackground
+	*elemFunc
+	funcName string
+}
+
+// newAggregateFunc returns the parser of the aggregate function, i.e. sum, count and avg.
+func newAggregateFunc(funcName string) func(*Expr, *string) ExprNode {
+	parse := newFunc(funcName, nil)
+	return func(p *Expr, expr *string) ExprNode {
+		f := readElemFunc(parse, p, expr)
+		if f == nil {
+			return nil
+		}
+		return &aggregateFuncExprNode{elemFunc: f, funcName: funcName}
+	}
+}
+
+func (f *aggregateFuncExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
+	n, ok := f.number(currField, tagExpr)
+	if !ok {
+		return realValue(nil, f.boolOpposite)
+	}
+	return realValue(boxFloat(n.float()), f.boolOpposite)
+}
+
+// number returns the exact result, and false if the result is nil.
+func (f *aggregateFuncExprNode) number(currField string, tagExpr *TagExpr) (number, bool) {
+	sum := number{kind: reflect.Int64}
+	var count int64
+	valid := true
+	ok := f.rangeElems(currField, tagExpr, func(sub ExprNode, tagExpr *TagExpr) bool {
+		if f.funcName == "count" {
+			if FakeBool(sub.Run(currField, tagExpr)) {
+				count++
+			}
+			return true
+		}
+		n, v, exact := evalNumber(sub, currField, tagExpr)
+		if !exact {
+			f, isFloat := v.(float64)
+			if !isFloat {
+				valid = false
+				return false
+			}
+			n = number{kind: reflect.Float64, f: f}
+		}
+		var err error
+		if sum, err = arithmeticNumbers('+', sum, n); err != nil {
+			panic(err)
+		}
+		count++
+		return true
+	})
+	if !ok || !valid {
+		return number{}, false
+	}
+	switch f.funcName {
+	case "count":
+		return number{kind: reflect.Int64, i: count}, true
+	case "avg":
+		if count == 0 {
+			return number{}, false
+		}
+		return number{kind: reflect.Float64, f: sum.float() / float64(count)}, true
+	}
+	return sum, true
+}
+
+// ConvError the error of the type conversion function, such as int('abc'),
+// which is the Cause of *EvalFault.
+type ConvError struct {
+	// Func the name of the function, i.e. int, float, string or bool
+	Func string
+	// Field the selector of the field whose expression is evaluated, such as 'Sub.Code'
+	Field string
+	// Value the value which cannot be converted
+	Value interface{}
+}
+
+// Error implements error interface.
+func (e *ConvError) Error() string {
+	return fmt.Sprintf("%s(): cannot convert %#v of the field %s", e.Func, e.Value, e.Field)
+}
+
+// convFuncExprNode the type conversion function, i.e. int(x), float(x), string(x) and bool(x),
+// e.g. int($)>0 compares the numeric string field with the number.
+// NOTE:
+//  int(x) parses the integer or float string and truncates the float toward zero,
+//  and keeps the integer exact, so the result is compared exactly, see exactNumberOf;
+//  float(x) parses the float string, string(x) formats the number and bool,
+//  and bool(x) parses the string or number as strconv.ParseBool, e.g. 'true', 'f' and 1;
+//  The leading and trailing spaces of the string are trimmed, and nil is converted to nil;
+//  The value which cannot be converted, such as int('abc'), is *ConvError, so the evaluation faults,
+//  i.e. false in the boolean context, or the result in strict mode.
+type convFuncExprNode struct {
+	exprBackground
+	arg          ExprNode
+	funcName     string
+	boolOpposite *bool
+}
+
+// newConvFunc returns the parser of the type conversion function, i.e. int, float, string and bool.
+func newConvFunc(funcName string) func(*Expr, *string) ExprNode {
+	parse := newFunc(funcName, nil)
+	return func(p *Expr, expr *string) ExprNode {
+		f := readFixedFunc(parse, 1, p, expr)
+		if f == nil {
+			return nil
+		}
+		return &convFuncExprNode{
+			arg:          f.args[0],
+			funcName:     funcName,
+			boolOpposite: f.boolOpposite,
+		}
+	}
+}
+
+func (f *convFuncExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
+	var r interface{}
+	switch f.funcName {
+	case "int":
+		if n, ok := f.number(currField, tagExpr); ok {
+			r = boxFloat(n.float())
+		}
+	case "float":
+		n, v, exact := evalNumber(f.arg, currField, tagExpr)
+		if exact {
+			r = boxFloat(n.float())
+			break
+		}
+		switch v := v.(type) {
+		case nil, float64:
+			r = v
+		case string:
+			x, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				panic(f.convError(v, currField, tagExpr))
+			}
+			r = boxFloat(x)
+		default:
+			panic(f.convError(v, currField, tagExpr))
+		}
+	case "string":
+		n, v, exact := evalNumber(f.arg, currField, tagExpr)
+		if exact {
+			r = formatNumber(n)
+			break
+		}
+		switch v := v.(type) {
+		case nil, string:
+			r = v
+		case float64:
+			r = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			r = strconv.FormatBool(v)
+		default:
+			panic(f.convError(v, currField, tagExpr))
+		}
+	case "bool":
+		s, ok := "", false
+		n, v, exact := evalNumber(f.arg, currField, tagExpr)
+		if exact {
+			v, s, ok = n.float(), formatNumber(n), true
+		} else {
+			switch x := v.(type) {
+			case nil, bool:
+				r = v
+			case string:
+				s, ok = strings.TrimSpace(x), true
+			case float64:
+				s, ok = strconv.FormatFloat(x, 'f', -1, 64), true
+			default:
+				panic(f.convError(v, currField, tagExpr))
+			}
+		}
+		if ok {
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				panic(f.convError(v, currField, tagExpr))
+			}
+			r = b
+		}
+	}
+	return realValue(r, f.boolOpposite)
+}
+
+// number returns the exact integer of int(x), and false if x is nil.
+func (f *convFuncExprNode) number(currField string, tagExpr *TagExpr) (number, bool) {
+	n, v, ok := evalNumber(f.arg, currField, tagExpr)
+	if ok {
+		v = n.float()
+	} else {
+		switch x := v.(type) {
+		case nil:
+			return number{}, false
+		case float64:
+			n, ok = number{kind: reflect.Float64, f: x}, true
+		case string:
+			n, ok = parseInteger(strings.TrimSpace(x))
+		}
+	}
+	if ok && n.kind == reflect.Float64 {
+		n, ok = truncNumber(n.f)
+	}
+	if !ok {
+		panic(f.convError(v, currField, tagExpr))
+	}
+	return n, true
+}
+
+func (f *convFuncExprNode) convError(v interface{}, currField string, tagExpr *TagExpr) *ConvError {
+	return &ConvError{Func: f.funcName, Field: tagExpr.selectorOf(currField), Value: v}
+}
+
+// parseInteger parses the integer string, or the float string, such as '1.5' and '1e3'.
+func parseInteger(s string) (number, bool) {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return number{kind: reflect.Int64, i: i}, true
+	}
+	if u, err := strconv.ParseUint(strings.TrimPrefix(s, "+"), 10, 64); err == nil {
+		return number{kind: reflect.Uint64, u: u}, true
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return number{kind: reflect.Float64, f: f}, true
+	}
+	return number{}, false
+}
+
+// truncNumber truncates the float toward zero, and false if it is out of the int64 and uint64 ranges.
+func truncNumber(f float64) (number, bool) {
+	t := math.Trunc(f)
+	switch {
+	case t >= -(1<<63) && t < 1<<63:
+		return number{kind: reflect.Int64, i: int64(t)}, true
+	case t >= 0 && t < 1<<64:
+		return number{kind: reflect.Uint64, u: uint64(t)}, true
+	}
+	return number{}, false
+}
+
+// formatNumber formats the exact number, e.g. '9007199254740993' and '1.5'.
+func formatNumber(n number) string {
+	switch n.kind {
+	case reflect.Int64:
+		return strconv.FormatInt(n.i, 10)
+	case reflect.Uint64:
+		return strconv.FormatUint(n.u, 10)
+	}
+	return strconv.FormatFloat(n.f, 'f', -1, 64)
+}
+
+// newLenFunc returns a length function which measures the string by @strLen,
+// and the other types as the built-in len, and its fast path @strFn of the string.
+// NOTE:
+//  The array, slice, map and chan are measured as Go, e.g. the nil map is 0;
+//  The nil pointer to them has no value, so the result is nil.
+func newLenFunc(strLen func(string) int) (fn func(...interface{}) interface{}, strFn func(string) interface{}) {
+	strFn = func(s string) interface{} {
+		return boxFloat(float64(strLen(s)))
+	}
+	fn = func(args ...interface{}) interface{} {
 		if len(args) != 1 {
 			return 0
 		}
 		v := args[0]
 		switch e := v.(type) {
 		case string:
-			return float64(len(e))
+			return strFn(e)
 		case float64, bool:
 			return nil
 		}
 		defer func() { recover() }()
-		return float64(reflect.ValueOf(v).Len())
-	}, true)
+		return boxFloat(float64(reflect.ValueOf(v).Len()))
+	}
+	return fn, strFn
+}
+
+// graphemeCount returns the approximate number of user-perceived characters in s.
+// NOTE:
+//  Combining marks, variation selectors and emoji modifiers extend the previous character;
+//  Characters joined by ZERO WIDTH JOINER are counted as one;
+//  A pair of regional indicators (flag) is counted as one.
+func graphemeCount(s string) int {
+	var n int
+	var joining, pendingFlag bool
+	for _, r := range s {
+		switch {
+		case unicode.In(r, unicode.Mn, unicode.Me),
+			r >= 0xFE00 && r <= 0xFE0F,   // variation selectors
+			r >= 0x1F3FB && r <= 0x1F3FF, // emoji modifiers
+			r >= 0xE0020 && r <= 0xE007F: // tags
+			continue
+		case r == 0x200D: // zero width joiner
+			joining = n > 0
+			continue
+		case r >= 0x1F1E6 && r <= 0x1F1FF: // regional indicators
+			if pendingFlag && !joining {
+				pendingFlag = false
+				continue
+			}
+			pendingFlag = true
+		default:
+			pendingFlag = false
+		}
+		if joining {
+			joining = false
+			continue
+		}
+		n++
+	}
+	return n
+}
+
+// timeNow returns the current time, it can be replaced in tests.
+var timeNow = time.Now
+
+// toTime converts time.Time or non-nil *time.Time to time.Time.
+func toTime(v interface{}) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case *time.Time:
+		if t != nil {
+			return *t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// compareTime compares the instants of the two time arguments.
+func compareTime(args []interface{}) (int, bool) {
+	if len(args) != 2 {
+		return 0, false
+	}
+	a, ok := toTime(args[0])
+	if !ok {
+		return 0, false
+	}
+	b, ok := toTime(args[1])
+	if !ok {
+		return 0, false
+	}
+	switch {
+	case a.Before(b):
+		return -1, true
+	case a.After(b):
+		return 1, true
+	}
+	return 0, true
+}
+
+// datetimeLayouts the named layouts of datetime function
+var datetimeLayouts = map[string]string{
+	"rfc3339":  time.RFC3339,
+	"rfc1123":  time.RFC1123,
+	"unixdate": time.UnixDate,
+}
+
+var parseDatetimeFunc = newFunc("datetime", datetimeFunc)
+
+// readDatetimeFuncExprNode reads datetime(s, layout) or datetime(s, layout, strict),
+// and checks the literal layout and strict arguments at parse time.
+func readDatetimeFuncExprNode(p *Expr, expr *string) ExprNode {
+	last := *expr
+	refs := len(p.funcRefs)
+	e := parseDatetimeFunc(p, expr)
+	if e == nil {
+		return nil
+	}
+	f := e.(*funcExprNode)
+	ok := len(f.args) == 2 || len(f.args) == 3
+	if ok {
+		if v, isLiteral := literalOf(f.args[1]); isLiteral {
+			name, _ := v.(string)
+			_, ok = datetimeLayout(name)
+		}
+	}
+	if ok && len(f.args) == 3 {
+		if v, isLiteral := literalOf(f.args[2]); isLiteral {
+			_, ok = v.(bool)
+		}
+	}
+	if !ok {
+		*expr = last
+		p.funcRefs = p.funcRefs[:refs]
+		return nil
+	}
+	return f
+}
+
+// datetimeFunc returns true if the string is parsed by the layout completely,
+// and is the same as the parsed time formatted by the layout in strict mode.
+func datetimeFunc(_ context.Context, args ...interface{}) interface{} {
+	if len(args) != 2 && len(args) != 3 {
+		return false
+	}
+	s, ok := args[0].(string)
+	if !ok || s == "" {
+		return false
+	}
+	name, _ := args[1].(string)
+	layout, ok := datetimeLayout(name)
+	if !ok {
+		return false
+	}
+	var strict bool
+	if len(args) == 3 {
+		if strict, ok = args[2].(bool); !ok {
+			return false
+		}
+	}
+	t, err := time.Parse(layout, s)
 	if err != nil {
-		panic(err)
+		return false
+	}
+	return !strict || t.Format(layout) == s
+}
+
+// datetimeLayout returns the layout of the name (case-insensitive) or the layout itself,
+// and whether it is valid, i.e. it contains the elements of the reference time.
+func datetimeLayout(s string) (string, bool) {
+	if layout, ok := datetimeLayouts[strings.ToLower(s)]; ok {
+		return layout, true
+	}
+	sample := time.Date(2019, 11, 28, 21, 37, 48, 0, time.UTC).Format(s)
+	if sample == s {
+		return "", false
+	}
+	if _, err := time.Parse(s, sample); err != nil {
+		return "", false
+	}
+	return s, true
+}
+
+// literalOf returns the value of the literal operand, such as 'abc', 1 or true.
+func literalOf(operand ExprNode) (interface{}, bool) {
+	g, ok := operand.(*groupExprNode)
+	if !ok || g.boolOpposite != nil {
+		return nil, false
+	}
+	switch e := g.rightOperand.(type) {
+	case *stringExprNode:
+		return e.val, true
+	case *digitalExprNode:
+		return e.val, true
+	case *boolExprNode:
+		return e.val, true
 	}
+	return nil, false
 }
 
 type regexpFuncExprNode struct {
 	exprBackground
-	re           *regexp.Regexp
+	// re the pattern compiled at parse time, if the pattern is a string literal
+	re *regexp.Regexp
+	// pattern the operand of the dynamic pattern, if re==nil
+	pattern      ExprNode
 	boolOpposite bool
 }
 
@@ -144,21 +1233,37 @@ func readRegexpFuncExprNode(p *Expr, expr *string) ExprNode {
 	if subExprNode == nil {
 		return nil
 	}
-	s := readPairedSymbol(trimLeftSpace(subExprNode), '\'', '\'')
-	if s == nil {
-		*expr = lastStr
-		return nil
+	e := &regexpFuncExprNode{}
+	if boolOpposite != nil {
+		e.boolOpposite = *boolOpposite
 	}
-	rege, err := regexp.Compile(*s)
-	if err != nil {
-		*expr = lastStr
-		return nil
+	trimLeftSpace(subExprNode)
+	rest := *subExprNode
+	s := readPairedSymbol(&rest, '\'', '\'')
+	if s != nil && (strings.HasPrefix(*trimLeftSpace(&rest), ",") || rest == "") {
+		// The literal pattern is compiled once
+		rege, err := regexp.Compile(*s)
+		if err != nil {
+			*expr = lastStr
+			return nil
+		}
+		e.re = rege
+		*subExprNode = rest
+	} else {
+		pattern := newGroupExprNode()
+		_, err := p.parseExprNode(subExprNode, pattern)
+		if err != nil || !strings.HasPrefix(*trimLeftSpace(subExprNode), ",") {
+			*expr = lastStr
+			return nil
+		}
+		sortPriority(pattern.RightOperand())
+		e.pattern = pattern
 	}
 	operand := newGroupExprNode()
 	trimLeftSpace(subExprNode)
 	if strings.HasPrefix(*subExprNode, ",") {
 		*subExprNode = (*subExprNode)[1:]
-		_, err = p.parseExprNode(trimLeftSpace(subExprNode), operand)
+		_, err := p.parseExprNode(trimLeftSpace(subExprNode), operand)
 		if err != nil {
 			*expr = lastStr
 			return nil
@@ -172,21 +1277,36 @@ func readRegexpFuncExprNode(p *Expr, expr *string) ExprNode {
 		*expr = lastStr
 		return nil
 	}
-	e := &regexpFuncExprNode{
-		re: rege,
-	}
-	if boolOpposite != nil {
-		e.boolOpposite = *boolOpposite
-	}
 	e.SetRightOperand(operand)
 	return e
 }
 
 func (re *regexpFuncExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
+	rege := re.re
+	if rege == nil {
+		pattern, ok := readString(re.pattern, currField, tagExpr)
+		if !ok {
+			pattern, ok = re.pattern.Run(currField, tagExpr).(string)
+			if !ok {
+				return false
+			}
+		}
+		rege = compileRegexp(pattern)
+		if rege == nil {
+			return false
+		}
+	}
+	if s, ok := readString(re.rightOperand, currField, tagExpr); ok {
+		bol := rege.MatchString(s)
+		if re.boolOpposite {
+			return !bol
+		}
+		return bol
+	}
 	param := re.rightOperand.Run(currField, tagExpr)
 	switch v := param.(type) {
 	case string:
-		bol := re.re.MatchString(v)
+		bol := rege.MatchString(v)
 		if re.boolOpposite {
 			return !bol
 		}
@@ -196,7 +1316,7 @@ func (re *regexpFuncExprNode) Run(currField string, tagExpr *TagExpr) interface{
 	}
 	v := reflect.ValueOf(param)
 	if v.Kind() == reflect.String {
-		bol := re.re.MatchString(v.String())
+		bol := rege.MatchString(v.String())
 		if re.boolOpposite {
 			return !bol
 		}
@@ -205,6 +1325,22 @@ func (re *regexpFuncExprNode) Run(currField string, tagExpr *TagExpr) interface{
 	return false
 }
 
+// regexpCache the LRU cache of the dynamic patterns of regexp function,
+// the value is nil if the pattern is invalid
+var regexpCache = newLRUCache(256)
+
+// compileRegexp returns the compiled pattern from the cache, or compiles and caches it.
+// NOTE:
+//  If the pattern is invalid, return nil.
+func compileRegexp(pattern string) *regexp.Regexp {
+	if v, ok := regexpCache.get(pattern); ok {
+		return v.(*regexp.Regexp)
+	}
+	re, _ := regexp.Compile(pattern)
+	regexpCache.add(pattern, re)
+	return re
+}
+
 type sprintfFuncExprNode struct {
 	exprBackground
 	format string
@@ -261,3 +1397,99 @@ func (se *sprintfFuncExprNode) Run(currField string, tagExpr *TagExpr) interface
 	}
 	return fmt.Sprintf(se.format, args...)
 }
+
+type fieldCmpFuncExprNode struct {
+	exprBackground
+	field        ExprNode
+	cmp          func(int, bool) bool
+	boolOpposite *bool
+}
+
+// newFieldCmpFunc creates the function that compares the value with the specified field,
+// such as eqfield($, 'Password').
+func newFieldCmpFunc(funcName string, cmp func(int, bool) bool) func(*Expr, *string) ExprNode {
+	prefix := funcName + "("
+	length := len(funcName)
+	return func(p *Expr, expr *string) ExprNode {
+		last, boolOpposite := getBoolOpposite(expr)
+		if !strings.HasPrefix(last, prefix) {
+			return nil
+		}
+		*expr = last[length:]
+		lastStr := *expr
+		subExprNode := readPairedSymbol(expr, '(', ')')
+		if subExprNode == nil {
+			return nil
+		}
+		idx := strings.LastIndex(*subExprNode, ",")
+		if idx == -1 {
+			*expr = lastStr
+			return nil
+		}
+		fieldStr := strings.TrimSpace((*subExprNode)[idx+1:])
+		field := readPairedSymbol(&fieldStr, '\'', '\'')
+		if field == nil || fieldStr != "" || *field == "" {
+			*expr = lastStr
+			return nil
+		}
+		operandStr := (*subExprNode)[:idx]
+		operand := newGroupExprNode()
+		_, err := p.parseExprNode(trimLeftSpace(&operandStr), operand)
+		if err != nil {
+			*expr = lastStr
+			return nil
+		}
+		sortPriority(operand.RightOperand())
+		p.fieldRefs = append(p.fieldRefs, *field)
+		e := &fieldCmpFuncExprNode{
+			field:        &selectorExprNode{field: *field, name: "$"},
+			cmp:          cmp,
+			boolOpposite: boolOpposite,
+		}
+		e.SetRightOperand(operand)
+		return e
+	}
+}
+
+func (fe *fieldCmpFuncExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
+	r, ok, exact, v0, v1 := compareOperands(fe.rightOperand, fe.field, currField, tagExpr)
+	if !exact {
+		r, ok = compareValues(v0, v1)
+	}
+	return realValue(fe.cmp(r, ok), fe.boolOpposite)
+}
+
+// compareValues compares two values of the same type,
+// supported types: float64, string, bool(equality only), time.Time, *time.Time.
+// NOTE:
+//  The result will be 0 if a == b, -1 if a < b, and +1 if a > b;
+//  If the values are not comparable or they are unequal booleans, ok is false.
+func compareValues(a, b interface{}) (r int, ok bool) {
+	switch x := a.(type) {
+	case float64:
+		if y, ok := b.(float64); ok {
+			return compareFloat(x, y), true
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), true
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			return 0, x == y
+		}
+	case time.Time, *time.Time:
+		return compareTime([]interface{}{x, b})
+	}
+	return 0, false
+}
+
+func compareFloat(x, y float64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
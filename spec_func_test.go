@@ -15,8 +15,12 @@
 package tagexpr_test
 
 import (
+	"errors"
+	"math"
+	"reflect"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/bytedance/go-tagexpr"
 )
@@ -60,3 +64,865 @@ func TestFunc(t *testing.T) {
 		}
 	}
 }
+
+func TestRegStringFunc(t *testing.T) {
+	err := tagexpr.RegStringFunc("strfn", func(s string) interface{} {
+		return "<" + s + ">"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	type Name string
+	type T struct {
+		A string  `te:"strfn($)"`
+		B Name    `te:"strfn($)"`
+		C *string `te:"strfn($)"`
+		D int     `te:"strfn($)"`
+		E string  `te:"strfn($,$)"`
+		F string  `te:"!strfn($)"`
+	}
+	te := tagexpr.New("te").MustRun(&T{A: "a", B: "b", E: "e", F: "f"})
+	cases := []struct {
+		selector string
+		want     interface{}
+	}{
+		{"A", "<a>"},
+		{"B", "<b>"},
+		{"C", nil},
+		{"D", nil},
+		{"E", nil},
+		{"F", false},
+	}
+	for _, c := range cases {
+		if got := te.Eval(c.selector); got != c.want {
+			t.Fatalf("%s: expect %v, but got %v", c.selector, c.want, got)
+		}
+	}
+	if err := tagexpr.RegStringFunc("strfn", nil); err == nil {
+		t.Fatal("expect the duplicate registration error")
+	}
+}
+
+func TestFuncRetainArgs(t *testing.T) {
+	var retained [][]interface{}
+	err := tagexpr.RegFunc("retainargs", func(args ...interface{}) interface{} {
+		retained = append(retained, args)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	type T struct {
+		A string `te:"retainargs($)"`
+		B string `te:"retainargs($)"`
+	}
+	te := tagexpr.New("te").MustRun(&T{A: "a", B: "b"})
+	te.Eval("A")
+	te.Eval("B")
+	// the args are not reused by the later calls
+	if !reflect.DeepEqual(retained, [][]interface{}{{"a"}, {"b"}}) {
+		t.Fatalf("expect the retained args [[a] [b]], but got %v", retained)
+	}
+}
+
+func TestFieldCmpFunc(t *testing.T) {
+	var vm = tagexpr.New("te")
+	type T struct {
+		Password        string
+		ConfirmPassword string    `te:"eqfield($, 'Password')"`
+		Min             int       `te:"ltefield($, 'Max')"`
+		Max             float32   `te:"gtfield($, 'Min')"`
+		Start           time.Time `te:"ltfield($, 'Sub.End')"`
+		Sub             struct {
+			Begin time.Time
+			End   time.Time `te:"!ltefield($, 'Begin')"`
+		}
+		Flag bool `te:"nefield($, 'Password')"`
+	}
+	now := time.Now()
+	obj := &T{Password: "123", ConfirmPassword: "123", Min: 1, Max: 2, Start: now}
+	obj.Sub.Begin = now
+	obj.Sub.End = now.Add(time.Second)
+	te := vm.MustRun(obj)
+	for _, s := range []string{"ConfirmPassword", "Min", "Max", "Start", "Sub.End", "Flag"} {
+		if !te.EvalBool(s) {
+			t.Fatalf("%s: expect true, but got false", s)
+		}
+	}
+	obj.ConfirmPassword = "1234"
+	obj.Min = 3
+	obj.Start = now.Add(time.Hour)
+	obj.Sub.Begin = obj.Sub.End
+	for _, s := range []string{"ConfirmPassword", "Min", "Max", "Start", "Sub.End"} {
+		if te.EvalBool(s) {
+			t.Fatalf("%s: expect false, but got true", s)
+		}
+	}
+
+	type U struct {
+		A string `te:"eqfield($, 'B')"`
+	}
+	_, err := vm.Run(new(U))
+	if err == nil || err.Error() != `tagexpr_test.U.A: field selector "B" does not exist` {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRegexpFunc(t *testing.T) {
+	type T struct {
+		Pattern string
+		A       string `te:"regexp('^\\w+$')"`
+		B       string `te:"regexp((Pattern)$, $)"`
+	}
+	vm := tagexpr.New("te")
+	obj := &T{Pattern: "^b\\d$", A: "a_1", B: "b1"}
+	te := vm.MustRun(obj)
+	if !te.EvalBool("A") || !te.EvalBool("B") {
+		t.Fatal("expect true")
+	}
+	obj.Pattern = "("
+	if te.EvalBool("B") {
+		t.Fatal("expect false for the invalid dynamic pattern")
+	}
+
+	type Invalid struct {
+		A string `te:"regexp('(')"`
+	}
+	if _, err := vm.Run(&Invalid{}); err == nil {
+		t.Fatal("expect error for the invalid literal pattern at registration")
+	}
+}
+
+func TestDatetimeFunc(t *testing.T) {
+	type T struct {
+		Layout string
+		A      string `te:"datetime($, '2006-01-02')"`
+		B      string `te:"datetime($, 'RFC3339')"`
+		C      string `te:"datetime($, '2006-1-2 15:04:05', true)"`
+		D      string `te:"datetime($, (Layout)$)"`
+	}
+	vm := tagexpr.New("te")
+	obj := &T{Layout: "unixdate", A: "2024-02-29", B: "2019-11-28T21:37:48+08:00", C: "2024-2-9 08:00:00", D: "Thu Nov 28 21:37:48 UTC 2019"}
+	te := vm.MustRun(obj)
+	for _, field := range []string{"A", "B", "C", "D"} {
+		if !te.EvalBool(field) {
+			t.Fatalf("expect true: %s", field)
+		}
+	}
+	for _, c := range []struct {
+		set   func(*T)
+		field string
+	}{
+		{func(o *T) { o.A = "" }, "A"},
+		{func(o *T) { o.A = "2023-02-29" }, "A"},
+		{func(o *T) { o.A = "2024-02-29T00:00:00Z" }, "A"},
+		{func(o *T) { o.B = "2019-11-28 21:37:48" }, "B"},
+		// accepted by time.Parse, but not the same when formatted
+		{func(o *T) { o.C = "2024-02-09 08:00:00" }, "C"},
+		{func(o *T) { o.C = "2024-2-9 08:00:00.5" }, "C"},
+		{func(o *T) { o.Layout = "YYYY-MM-DD" }, "D"},
+	} {
+		o := *obj
+		c.set(&o)
+		if vm.MustRun(&o).EvalBool(c.field) {
+			t.Fatalf("expect false: %+v", o)
+		}
+	}
+
+	type InvalidLayout struct {
+		A string `te:"datetime($, 'YYYY-MM-DD')"`
+	}
+	if _, err := vm.Run(&InvalidLayout{}); err == nil {
+		t.Fatal("expect error for the invalid literal layout")
+	}
+	type InvalidStrict struct {
+		A string `te:"datetime($, '2006-01-02', 'yes')"`
+	}
+	if _, err := vm.Run(&InvalidStrict{}); err == nil {
+		t.Fatal("expect error for the invalid literal strict flag")
+	}
+}
+
+func TestErrFunc(t *testing.T) {
+	errNotFound := errors.New("not found")
+	var calls int
+	tagexpr.RegErrFunc("lookupTest", func(args ...interface{}) (interface{}, error) {
+		calls++
+		if s, _ := args[0].(string); s != "" {
+			return s == "ok", nil
+		}
+		return nil, errNotFound
+	})
+	if err := tagexpr.SetFuncArity("lookupTest", 1); err != nil {
+		t.Fatal(err)
+	}
+	vm := tagexpr.New("te")
+	type T struct {
+		A string `te:"lookupTest($) || lookupTest('ok')"`
+		B string `te:"!lookupTest($) && true"`
+	}
+	te := vm.MustRun(&T{A: "ok", B: "no"})
+	if !te.EvalBool("A") || !te.EvalBool("B") {
+		t.Fatal("expect true")
+	}
+	calls = 0
+	te = vm.MustRun(&T{})
+	for _, field := range []string{"A", "B"} {
+		r := te.Eval(field)
+		fe, ok := r.(*tagexpr.FuncError)
+		if !ok {
+			t.Fatalf("expect *FuncError, but got: %v", r)
+		}
+		if !errors.Is(fe, errNotFound) || fe.Func != "lookupTest" {
+			t.Fatalf("unexpected error: %v", fe)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("expect the evaluation aborted, but got %d calls", calls)
+	}
+	if got := te.Eval("A").(error).Error(); got != `function lookupTest in "lookupTest($) || lookupTest('ok')": not found` {
+		t.Fatal(got)
+	}
+
+	type WrongArity struct {
+		A string `te:"lookupTest($, 'x')"`
+	}
+	if _, err := vm.Run(&WrongArity{}); err == nil {
+		t.Fatal("expect error for the wrong number of arguments")
+	}
+	type NoArgs struct {
+		A string `te:"lookupTest()"`
+	}
+	if _, err := vm.Run(&NoArgs{}); err == nil {
+		t.Fatal("expect error for the wrong number of arguments")
+	}
+	if err := tagexpr.SetFuncArity("lookupTestUnknown", 1); err == nil {
+		t.Fatal("expect error for the unregistered function")
+	}
+}
+
+func TestStrMatchFunc(t *testing.T) {
+	type Name string
+	type T struct {
+		Prefix string
+		A      string `te:"hasPrefix($, 'img_')"`
+		B      string `te:"hasSuffix($, '.png') && !contains($, '..')"`
+		C      Name   `te:"hasPrefix($, (Prefix)$)"`
+		D      int    `te:"contains($, '1')"`
+		E      string `te:"contains($, 1)"`
+	}
+	vm := tagexpr.New("te")
+	te := vm.MustRun(&T{Prefix: "v1.", A: "img_1", B: "a.png", C: "v1.2", D: 1, E: "1"})
+	for field, expect := range map[string]bool{"A": true, "B": true, "C": true, "D": false, "E": false} {
+		if got := te.EvalBool(field); got != expect {
+			t.Fatalf("%s: expect %v, but got %v", field, expect, got)
+		}
+	}
+	te = vm.MustRun(&T{Prefix: "v2.", A: "im_1", B: "a..png", C: "v1.2"})
+	for _, field := range []string{"A", "B", "C"} {
+		if te.EvalBool(field) {
+			t.Fatalf("%s: expect false", field)
+		}
+	}
+}
+
+func TestStrTransformFunc(t *testing.T) {
+	type T struct {
+		Role   string `te:"lower($)=='admin' || lower($)=='root'"`
+		Name   string `te:"len(trim($))"`
+		Code   string `te:"upper(trimPrefix(trim($), 'id-'))"`
+		File   string `te:"trimSuffix(lower(sprintf('%s.%s', $, 'PNG')), '.png')"`
+		Count  int    `te:"lower($)"`
+		Suffix string `te:"trimSuffix($, 1)"`
+		Not    string `te:"!trim($)"`
+	}
+	obj := &T{Role: "ROOT", Name: "\u3000 ab \t", Code: " id-x1 ", File: "A", Count: 3, Suffix: "a1", Not: "  "}
+	te := tagexpr.New("te").MustRun(obj)
+	for field, expect := range map[string]interface{}{
+		"Role":   true,
+		"Name":   float64(2),
+		"Code":   "X1",
+		"File":   "a",
+		"Count":  float64(3),
+		"Suffix": "a1",
+		"Not":    true,
+	} {
+		if got := te.Eval(field); got != expect {
+			t.Fatalf("%s: expect %v, but got %v", field, expect, got)
+		}
+	}
+	// the non-string argument is nil in strict mode
+	te = tagexpr.New("te").SetStrictMode(true).MustRun(obj)
+	for _, field := range []string{"Count", "Suffix"} {
+		if got := te.Eval(field); got != nil {
+			t.Fatalf("%s: expect nil, but got %v", field, got)
+		}
+	}
+	type WrongArity struct {
+		A string `te:"lower($, 'x')"`
+	}
+	if _, err := tagexpr.New("te").Run(&WrongArity{}); err == nil {
+		t.Fatal("expect error for the wrong number of arguments")
+	}
+}
+
+func TestSplitJoinFunc(t *testing.T) {
+	type T struct {
+		Tags     string   `te:"len(split($, ','))<=5"`
+		Empty    string   `te:"len(split($, ','))"`
+		Trailing string   `te:"len(split($, ','))"`
+		Multi    string   `te:"split($, '→')"`
+		Join     []int    `te:"join($, '; ')"`
+		Rejoin   string   `te:"join(split($, '::'), ',')"`
+		Same     []string `te:"split((Rejoin)$, '::')==$"`
+		Diff     []string `te:"split((Tags)$, ',')!=$"`
+		Right    []string `te:"$==split((Tags)$, ',')"`
+		Fields   []string `te:"$==(Same)$"`
+		Index    string   `te:"index($, 'é')"`
+		Missing  string   `te:"index($, 'x')"`
+		NotStr   int      `te:"split($, ',')==nil && index($, ',')==-1"`
+	}
+	obj := &T{
+		Tags:     "a,b,c",
+		Trailing: "a,b,",
+		Multi:    "x→y→",
+		Join:     []int{1, 2, 3},
+		Rejoin:   "a::b::c",
+		Same:     []string{"a", "b", "c"},
+		Diff:     []string{"a", "b"},
+		Right:    []string{"a", "b", "c"},
+		Fields:   []string{"a", "b", "c"},
+		Index:    "café",
+		Missing:  "abc",
+		NotStr:   1,
+	}
+	te := tagexpr.New("te").MustRun(obj)
+	for field, expect := range map[string]interface{}{
+		"Tags":     true,
+		"Empty":    float64(0),
+		"Trailing": float64(3),
+		"Multi":    []string{"x", "y", ""},
+		"Join":     "1; 2; 3",
+		"Rejoin":   "a,b,c",
+		"Same":     true,
+		"Diff":     true,
+		"Right":    true,
+		"Fields":   false,
+		"Index":    float64(3),
+		"Missing":  float64(-1),
+		"NotStr":   true,
+	} {
+		if got := te.Eval(field); !reflect.DeepEqual(got, expect) {
+			t.Fatalf("%s: expect %#v, but got %#v", field, expect, got)
+		}
+	}
+	type WrongArity struct {
+		A string `te:"split($)"`
+	}
+	if _, err := tagexpr.New("te").Run(&WrongArity{}); err == nil {
+		t.Fatal("expect error for the wrong number of arguments")
+	}
+}
+
+func BenchmarkHasPrefix(b *testing.B) {
+	type T struct {
+		A string `te:"hasPrefix($, 'img_')"`
+	}
+	vm := tagexpr.New("te")
+	te := vm.MustRun(&T{A: "img_123.png"})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		te.EvalBool("A")
+	}
+}
+
+func BenchmarkRegexpPrefix(b *testing.B) {
+	type T struct {
+		A string `te:"regexp('^img_')"`
+	}
+	vm := tagexpr.New("te")
+	te := vm.MustRun(&T{A: "img_123.png"})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		te.EvalBool("A")
+	}
+}
+
+func BenchmarkRegexpLiteral(b *testing.B) {
+	type T struct {
+		A string `te:"regexp('^[a-z]+\\d*$')"`
+	}
+	vm := tagexpr.New("te")
+	obj := &T{A: "abc123"}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		vm.MustRun(obj).EvalBool("A")
+	}
+}
+
+func BenchmarkRegexpDynamic(b *testing.B) {
+	type T struct {
+		Pattern string
+		A       string `te:"regexp((Pattern)$, $)"`
+	}
+	vm := tagexpr.New("te")
+	obj := &T{Pattern: "^[a-z]+\\d*$", A: "abc123"}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		vm.MustRun(obj).EvalBool("A")
+	}
+}
+
+func BenchmarkRegexpCompile(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		regexp.MustCompile("^[a-z]+\\d*$").MatchString("abc123")
+	}
+}
+
+func TestMathFunc(t *testing.T) {
+	type T struct {
+		A     int     `te:"abs($-(B)$)<5"`
+		B     int     `te:"abs($)"`
+		MinI  int64   `te:"abs($)==9223372036854775808"`
+		MaxU  uint64  `te:"max($, 1, -1)==$ && min($, -1)==-1"`
+		Big   int64   `te:"max($, 1.5)==1152921504606846977"`
+		F     float64 `te:"ceil($)==-1 && floor($)==-2 && round($)==-2 && round(-$)==2"`
+		NaN   float64 `te:"max(1, $, 3)"`
+		NaN2  float64 `te:"abs($)"`
+		Pow   int     `te:"pow($, 62)==4611686018427387904 && pow(2, 64)==18446744073709551616"`
+		Pow2  int     `te:"pow($, -1)"`
+		Nest  int     `te:"max(abs($), len('abc'), min(1, 2))"`
+		Str   string  `te:"abs($)"`
+		Ptr   *int    `te:"max($, 1)"`
+		Round float64 `te:"round($)==3 && ceil(-0.5)==0"`
+	}
+	obj := &T{
+		A:     3,
+		B:     -1,
+		MinI:  math.MinInt64,
+		MaxU:  math.MaxUint64,
+		Big:   1<<60 + 1,
+		F:     -1.5,
+		NaN:   math.NaN(),
+		NaN2:  math.NaN(),
+		Pow:   2,
+		Pow2:  2,
+		Nest:  -5,
+		Str:   "-1",
+		Round: 2.5,
+	}
+	vm := tagexpr.New("te")
+	te := vm.MustRun(obj)
+	for field, expect := range map[string]interface{}{
+		"A":     true,
+		"B":     float64(1),
+		"MinI":  true,
+		"MaxU":  true,
+		"Big":   true,
+		"F":     true,
+		"Pow":   true,
+		"Pow2":  0.5,
+		"Nest":  float64(5),
+		"Str":   nil,
+		"Ptr":   nil,
+		"Round": true,
+	} {
+		if got := te.Eval(field); !reflect.DeepEqual(got, expect) {
+			t.Fatalf("%s: expect %#v, but got %#v", field, expect, got)
+		}
+	}
+	for _, field := range []string{"NaN", "NaN2"} {
+		if got, ok := te.Eval(field).(float64); !ok || !math.IsNaN(got) {
+			t.Fatalf("%s: expect NaN, but got %#v", field, te.Eval(field))
+		}
+	}
+
+	for _, v := range []interface{}{
+		&struct {
+			A int `te:"abs()"`
+		}{},
+		&struct {
+			A int `te:"abs($, 1)"`
+		}{},
+		&struct {
+			A int `te:"min()"`
+		}{},
+		&struct {
+			A int `te:"pow($)"`
+		}{},
+	} {
+		if _, err := tagexpr.New("te").Run(v); err == nil {
+			t.Fatalf("expect error for the wrong number of arguments: %T", v)
+		}
+	}
+}
+
+func TestQuantifierFunc(t *testing.T) {
+	type item struct {
+		Name string
+		Qty  int
+	}
+	type T struct {
+		Tags     []string       `te:"all($, 'len(#v)>0 && len(#v)<64')"`
+		Empty    []string       `te:"all($, 'len(#v)>0')"`
+		EmptyAny []string       `te:"any($, 'len(#v)>0')"`
+		Nil      []string       `te:"all($, 'len(#v)>0')"`
+		Items    []item         `te:"any($, '#v.Qty>0')"`
+		AllItems []item         `te:"all($, '#v.Qty>0')"`
+		Ptrs     []*item        `te:"all($, '#v.Name!=\\'\\'')"`
+		Index    []int          `te:"all($, '#v==#k*10')"`
+		Map      map[string]int `te:"all($, 'len(#k)==#v')"`
+		Nested   [][]int        `te:"all($, 'any(#v, \\'#v>2\\')') && all($, '#v[0]<10')"`
+		Limit    []int          `te:"all($, '#v<(Max)$')"`
+		Neg      []int          `te:"!any($, '#v<0')"`
+		Big      []int64        `te:"any($, '#v==9007199254740993')"`
+		Str      string         `te:"any($, '#v')"`
+		Dyn      []int          `te:"any($, (Pred)$)"`
+		BadDyn   []int          `te:"any($, (BadPred)$)"`
+		Nums     *[]float64     `te:"all($, '#v>0')"`
+		Any      []interface{}  `te:"any($, '#v==\\'a\\'')"`
+		Max      int
+		Pred     string
+		BadPred  string
+	}
+	obj := &T{
+		Tags:     []string{"a", "bc"},
+		Empty:    []string{},
+		EmptyAny: []string{},
+		Items:    []item{{Name: "a"}, {Name: "b", Qty: 2}},
+		AllItems: []item{{Name: "a"}, {Name: "b", Qty: 2}},
+		Ptrs:     []*item{{Name: "a"}, {Name: "b"}},
+		Index:    []int{0, 10, 20},
+		Map:      map[string]int{"a": 1, "bb": 2},
+		Nested:   [][]int{{1, 3}, {5}},
+		Limit:    []int{1, 99},
+		Neg:      []int{0, 1},
+		Big:      []int64{9007199254740992},
+		Str:      "abc",
+		Dyn:      []int{1, 3},
+		BadDyn:   []int{1},
+		Nums:     &[]float64{0.5},
+		Any:      []interface{}{1, "a"},
+		Max:      100,
+		Pred:     "#v>2",
+		BadPred:  "#v>@",
+	}
+	vm := tagexpr.New("te")
+	te := vm.MustRun(obj)
+	for field, expect := range map[string]interface{}{
+		"Tags":     true,
+		"Empty":    true,
+		"EmptyAny": false,
+		"Nil":      true,
+		"Items":    true,
+		"AllItems": false,
+		"Ptrs":     true,
+		"Index":    true,
+		"Map":      true,
+		"Nested":   true,
+		"Limit":    true,
+		"Neg":      true,
+		"Big":      false,
+		"Str":      false,
+		"Dyn":      true,
+		"Nums":     true,
+		"Any":      true,
+	} {
+		if got := te.Eval(field); !reflect.DeepEqual(got, expect) {
+			t.Fatalf("%s: expect %#v, but got %#v", field, expect, got)
+		}
+	}
+	if _, ok := te.Eval("BadDyn").(*tagexpr.EvalFault); !ok {
+		t.Fatalf("BadDyn: expect *tagexpr.EvalFault, but got %#v", te.Eval("BadDyn"))
+	}
+
+	for _, v := range []interface{}{
+		&struct {
+			A []int `te:"all($, '#v>@')"`
+		}{},
+		&struct {
+			A []int `te:"all($, 1)"`
+		}{},
+		&struct {
+			A []int `te:"all($)"`
+		}{},
+		&struct {
+			A int `te:"#v>0"`
+		}{},
+		&struct {
+			A []int `te:"any($, '#x>0')"`
+		}{},
+	} {
+		if _, err := tagexpr.New("te").Run(v); err == nil {
+			t.Fatalf("expect syntax error: %T", v)
+		}
+	}
+}
+
+func TestAggregateFunc(t *testing.T) {
+	type item struct {
+		Amount int64
+		Price  float64
+		Paid   bool
+	}
+	type T struct {
+		Items    []item         `te:"sum($, '#v.Amount')==(Total)$ && count($, '#v.Paid')==1 && avg($, '#v.Amount')==1.5"`
+		Prices   []item         `te:"sum($, '#v.Price')"`
+		Mixed    []item         `te:"sum($, '#v.Amount + #v.Price')"`
+		Big      []int64        `te:"sum($, '#v')==9007199254740993"`
+		Overflow []int64        `te:"sum($, '#v')"`
+		Map      map[string]int `te:"sum($, '#v * len(#k)')"`
+		Nil      []int          `te:"sum($, '#v')==0 && count($, '#v')==0"`
+		NilAvg   []int          `te:"avg($, '#v')"`
+		Str      []string       `te:"sum($, '#v')"`
+		NotList  int            `te:"count($, '#v')"`
+		Dyn      []int          `te:"count($, (Pred)$)"`
+		Total    int64
+		Pred     string
+	}
+	obj := &T{
+		Items:    []item{{Amount: 1, Paid: true}, {Amount: 2}},
+		Prices:   []item{{Price: 0.5}, {Price: 1}},
+		Mixed:    []item{{Amount: 1, Price: 0.5}},
+		Big:      []int64{9007199254740992, 1},
+		Overflow: []int64{math.MaxInt64, math.MaxInt64, math.MaxInt64},
+		Map:      map[string]int{"a": 1, "bb": 2},
+		Str:      []string{"a"},
+		NotList:  1,
+		Dyn:      []int{1, 3, 5},
+		Total:    3,
+		Pred:     "#v>2",
+	}
+	vm := tagexpr.New("te")
+	te := vm.MustRun(obj)
+	for field, expect := range map[string]interface{}{
+		"Items":   true,
+		"Prices":  1.5,
+		"Mixed":   1.5,
+		"Big":     true,
+		"Map":     float64(5),
+		"Nil":     true,
+		"NilAvg":  nil,
+		"Str":     nil,
+		"NotList": nil,
+		"Dyn":     float64(2),
+	} {
+		if got := te.Eval(field); !reflect.DeepEqual(got, expect) {
+			t.Fatalf("%s: expect %#v, but got %#v", field, expect, got)
+		}
+	}
+	if _, ok := te.Eval("Overflow").(*tagexpr.EvalFault); !ok {
+		t.Fatalf("Overflow: expect *tagexpr.EvalFault, but got %#v", te.Eval("Overflow"))
+	}
+
+	for _, v := range []interface{}{
+		&struct {
+			A []int `te:"sum($)"`
+		}{},
+		&struct {
+			A []int `te:"avg($, '#v+@')"`
+		}{},
+	} {
+		if _, err := tagexpr.New("te").Run(v); err == nil {
+			t.Fatalf("expect syntax error: %T", v)
+		}
+	}
+}
+
+func TestAggregateFuncEvalOnce(t *testing.T) {
+	var calls int
+	err := tagexpr.RegFunc("countcalls", func(args ...interface{}) interface{} {
+		calls++
+		return args[0]
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	type T struct {
+		A []int `te:"sum($, 'max(countcalls(#v), 0) * countcalls(2)')"`
+	}
+	te := tagexpr.New("te").MustRun(&T{A: []int{1, 2, 3}})
+	if got := te.Eval("A"); got != 12.0 {
+		t.Fatalf("expect 12, but got %#v", got)
+	}
+	if calls != 6 {
+		t.Fatalf("expect each element evaluated once, but got %d calls", calls)
+	}
+}
+
+func TestConvFuncEvalOnce(t *testing.T) {
+	var calls int
+	err := tagexpr.RegFunc("countconv", func(args ...interface{}) interface{} {
+		calls++
+		return args[0]
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	type T struct {
+		A []int `te:"sum($, 'int(max(countconv(#v), 0) * countconv(1))')"`
+		B int   `te:"float(max(countconv($), 0) * countconv(1))==2"`
+		C int   `te:"string(max(countconv($), 0) * countconv(1))=='2'"`
+		D int   `te:"bool(max(countconv($), 0) * countconv(1))"`
+	}
+	te := tagexpr.New("te").MustRun(&T{A: []int{1, 2, 3}, B: 2, C: 2, D: 1})
+	if got := te.Eval("A"); got != 6.0 {
+		t.Fatalf("expect 6, but got %#v", got)
+	}
+	for _, field := range []string{"B", "C", "D"} {
+		if got := te.Eval(field); got != true {
+			t.Fatalf("%s: expect true, but got %#v", field, got)
+		}
+	}
+	if calls != 12 {
+		t.Fatalf("expect each argument evaluated once, but got %d calls", calls)
+	}
+}
+
+func TestCompareEvalOnce(t *testing.T) {
+	var calls int
+	err := tagexpr.RegFunc("countcmp", func(args ...interface{}) interface{} {
+		calls++
+		return args[0]
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	type T struct {
+		A int   `te:"abs(countcmp($))==len('abc')"`
+		B int   `te:"int(countcmp($))>=len('abc')"`
+		C []int `te:"sum($, 'countcmp(#v)')<len('abc')"`
+	}
+	te := tagexpr.New("te").MustRun(&T{A: -3, B: 3, C: []int{1, 1}})
+	for _, field := range []string{"A", "B", "C"} {
+		if got := te.Eval(field); got != true {
+			t.Fatalf("%s: expect true, but got %#v", field, got)
+		}
+	}
+	if calls != 4 {
+		t.Fatalf("expect each operand evaluated once, but got %d calls", calls)
+	}
+
+	// the field of the comparison function is not an exact number
+	type F struct {
+		A int `te:"eqfield(abs(countcmp($)), 'S')"`
+		S string
+	}
+	calls = 0
+	te = tagexpr.New("te").MustRun(&F{A: -3, S: "3"})
+	if got := te.Eval("A"); got != false {
+		t.Fatalf("expect false, but got %#v", got)
+	}
+	if calls != 1 {
+		t.Fatalf("expect the operand evaluated once, but got %d calls", calls)
+	}
+
+	// the operand of the bitwise operator is not an exact number
+	type B struct {
+		A int `te:"(abs(countcmp($)) + len('a')) & 7"`
+	}
+	calls = 0
+	te = tagexpr.New("te").MustRun(&B{A: -3})
+	if got := te.Eval("A"); got != 4.0 {
+		t.Fatalf("expect 4, but got %#v", got)
+	}
+	if calls != 1 {
+		t.Fatalf("expect the operand evaluated once, but got %d calls", calls)
+	}
+}
+
+func TestConvFunc(t *testing.T) {
+	type Sub struct {
+		Code string `te:"int($)>0"`
+	}
+	type T struct {
+		Str    string  `te:"int($)==42 && float($)==42 && $!=42"`
+		Spaced string  `te:"int($)"`
+		Frac   string  `te:"int($)"`
+		Neg    float64 `te:"int($)"`
+		Big    string  `te:"int($)==9007199254740993"`
+		Num    int64   `te:"string($)"`
+		Float  float64 `te:"string($)"`
+		Flag   bool    `te:"string($)"`
+		Yes    string  `te:"bool($) && !bool((No)$)"`
+		One    int     `te:"bool($)"`
+		No     string
+		Nil    *string `te:"int($)"`
+		Bad    string  `te:"int($)>0"`
+		BadF   string  `te:"float($)"`
+		BadB   int     `te:"bool($)"`
+		Sub    Sub
+	}
+	obj := &T{
+		Str:    "42",
+		Spaced: " 7 ",
+		Frac:   "-3.9",
+		Neg:    -2.5,
+		Big:    "9007199254740993",
+		Num:    9007199254740993,
+		Float:  1.5,
+		Flag:   true,
+		Yes:    "true",
+		One:    1,
+		No:     "F",
+		Bad:    "abc",
+		BadF:   "1,5",
+		BadB:   2,
+		Sub:    Sub{Code: "x"},
+	}
+	vm := tagexpr.New("te")
+	te := vm.MustRun(obj)
+	for field, expect := range map[string]interface{}{
+		"Str":    true,
+		"Spaced": float64(7),
+		"Frac":   float64(-3),
+		"Neg":    float64(-2),
+		"Big":    true,
+		"Num":    "9007199254740993",
+		"Float":  "1.5",
+		"Flag":   "true",
+		"Yes":    true,
+		"One":    true,
+		"Nil":    nil,
+	} {
+		if got := te.Eval(field); !reflect.DeepEqual(got, expect) {
+			t.Fatalf("%s: expect %#v, but got %#v", field, expect, got)
+		}
+	}
+	for field, selector := range map[string]string{
+		"Bad":      "Bad",
+		"BadF":     "BadF",
+		"BadB":     "BadB",
+		"Sub.Code": "Sub.Code",
+	} {
+		fault, ok := te.Eval(field).(*tagexpr.EvalFault)
+		if !ok {
+			t.Fatalf("%s: expect *tagexpr.EvalFault, but got %#v", field, te.Eval(field))
+		}
+		var convErr *tagexpr.ConvError
+		if !errors.As(fault, &convErr) || convErr.Field != selector {
+			t.Fatalf("%s: expect *tagexpr.ConvError of %s, but got %v", field, selector, fault)
+		}
+	}
+	if got := te.Eval("Bad"); tagexpr.FakeBool(got) {
+		t.Fatalf("Bad: expect false in the boolean context, but got %#v", got)
+	}
+
+	for _, v := range []interface{}{
+		&struct {
+			A string `te:"int()"`
+		}{},
+		&struct {
+			A string `te:"int($, 10)"`
+		}{},
+	} {
+		if _, err := tagexpr.New("te").Run(v); err == nil {
+			t.Fatalf("expect syntax error: %T", v)
+		}
+	}
+}
@@ -52,7 +52,7 @@ type boolExprNode struct {
 	val bool
 }
 
-var boolRegexp = regexp.MustCompile(`^!*(true|false)([\)\],\|&!= \t]{1}|$)`)
+var boolRegexp = regexp.MustCompile(`^!*(true|false)([\)\],\|&!=\? \t]{1}|$)`)
 
 func readBoolExprNode(expr *string) ExprNode {
 	s := boolRegexp.FindString(*expr)
@@ -96,9 +96,12 @@ func (se *stringExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
 type digitalExprNode struct {
 	exprBackground
 	val interface{}
+	// num the exact number of the literal, valid if exact is true
+	num   number
+	exact bool
 }
 
-var digitalRegexp = regexp.MustCompile(`^[\+\-]?\d+(\.\d+)?([\)\],\+\-\*\/%><\|&!=\^ \t\\]|$)`)
+var digitalRegexp = regexp.MustCompile(`^[\+\-]?\d+(\.\d+)?([\)\],\+\-\*\/%><\|&!=\^\? \t\\]|$)`)
 
 func readDigitalExprNode(expr *string) ExprNode {
 	last, boolOpposite := getBoolOpposite(expr)
@@ -111,7 +114,11 @@ func readDigitalExprNode(expr *string) ExprNode {
 	}
 	*expr = last[len(s):]
 	f64, _ := strconv.ParseFloat(s, 64)
-	return &digitalExprNode{val: realValue(f64, boolOpposite)}
+	return &digitalExprNode{
+		val:   realValue(f64, boolOpposite),
+		num:   parseNumber(s),
+		exact: boolOpposite == nil,
+	}
 }
 
 func (de *digitalExprNode) Run(currField string, tagExpr *TagExpr) interface{} { return de.val }
@@ -121,7 +128,7 @@ type nilExprNode struct {
 	val interface{}
 }
 
-var nilRegexp = regexp.MustCompile(`^nil([\)\],\|&!= \t]{1}|$)`)
+var nilRegexp = regexp.MustCompile(`^nil([\)\],\|&!=\? \t]{1}|$)`)
 
 func readNilExprNode(expr *string) ExprNode {
 	last, boolOpposite := getBoolOpposite(expr)
@@ -155,3 +162,30 @@ func realValue(v interface{}, boolOpposite *bool) interface{} {
 	}
 	return bol
 }
+
+// readString returns the string of the operand without boxing it,
+// if it is a string literal or a field selector of string kind.
+// NOTE:
+//  Reading the field is not an evaluation, so the operand is still evaluated once by Run if not found.
+func readString(e ExprNode, currField string, tagExpr *TagExpr) (string, bool) {
+	switch x := e.(type) {
+	case *groupExprNode:
+		if x.boolOpposite != nil || x.rightOperand == nil {
+			return "", false
+		}
+		return readString(x.rightOperand, currField, tagExpr)
+	case *stringExprNode:
+		s, ok := x.val.(string)
+		return s, ok
+	case *selectorExprNode:
+		if x.boolOpposite != nil || x.floatOpposite || len(x.subExprs) > 0 || x.up > 0 || x.root || tagExpr == nil {
+			return "", false
+		}
+		field := x.field
+		if field == "" {
+			field = currField
+		}
+		return tagExpr.getString(field)
+	}
+	return "", false
+}
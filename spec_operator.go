@@ -15,7 +15,10 @@
 package tagexpr
 
 import (
+	"fmt"
 	"math"
+	"reflect"
+	"time"
 )
 
 // --------------------------- Operator ---------------------------
@@ -26,22 +29,7 @@ func newAdditionExprNode() ExprNode { return &additionExprNode{} }
 
 func (ae *additionExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
 	// positive number or Addition
-	v0 := ae.leftOperand.Run(currField, tagExpr)
-	v1 := ae.rightOperand.Run(currField, tagExpr)
-	switch r := v0.(type) {
-	case float64:
-		var v float64
-		v, _ = v1.(float64)
-		r += v
-		return r
-	case string:
-		var v string
-		v, _ = v1.(string)
-		r += v
-		return r
-	default:
-		return v1
-	}
+	return runArithmetic('+', ae.leftOperand, ae.rightOperand, currField, tagExpr)
 }
 
 type multiplicationExprNode struct{ exprBackground }
@@ -49,22 +37,18 @@ type multiplicationExprNode struct{ exprBackground }
 func newMultiplicationExprNode() ExprNode { return &multiplicationExprNode{} }
 
 func (ae *multiplicationExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
-	v0, _ := ae.leftOperand.Run(currField, tagExpr).(float64)
-	v1, _ := ae.rightOperand.Run(currField, tagExpr).(float64)
-	return v0 * v1
+	return runArithmetic('*', ae.leftOperand, ae.rightOperand, currField, tagExpr)
 }
 
 type divisionExprNode struct{ exprBackground }
 
 func newDivisionExprNode() ExprNode { return &divisionExprNode{} }
 
+// Run returns the quotient, which is truncated if both operands are integers, see arithmeticNumbers.
+// NOTE:
+//  panic if divided by zero.
 func (de *divisionExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
-	v1, _ := de.rightOperand.Run(currField, tagExpr).(float64)
-	if v1 == 0 {
-		return math.NaN()
-	}
-	v0, _ := de.leftOperand.Run(currField, tagExpr).(float64)
-	return v0 / v1
+	return runArithmetic('/', de.leftOperand, de.rightOperand, currField, tagExpr)
 }
 
 type subtractionExprNode struct{ exprBackground }
@@ -72,22 +56,60 @@ type subtractionExprNode struct{ exprBackground }
 func newSubtractionExprNode() ExprNode { return &subtractionExprNode{} }
 
 func (de *subtractionExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
-	v0, _ := de.leftOperand.Run(currField, tagExpr).(float64)
-	v1, _ := de.rightOperand.Run(currField, tagExpr).(float64)
-	return v0 - v1
+	return runArithmetic('-', de.leftOperand, de.rightOperand, currField, tagExpr)
 }
 
 type remainderExprNode struct{ exprBackground }
 
 func newRemainderExprNode() ExprNode { return &remainderExprNode{} }
 
+// Run returns the remainder, which is math.Mod if one of the operands is not an integer, see arithmeticNumbers.
+// NOTE:
+//  panic if divided by zero.
 func (re *remainderExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
-	v1, _ := re.rightOperand.Run(currField, tagExpr).(float64)
-	if v1 == 0 {
-		return math.NaN()
+	return runArithmetic('%', re.leftOperand, re.rightOperand, currField, tagExpr)
+}
+
+// runArithmetic returns the result of the arithmetic operator, whose operands are evaluated once.
+func runArithmetic(op byte, left, right ExprNode, currField string, tagExpr *TagExpr) interface{} {
+	n, v, exact := evalArithmetic(op, left, right, currField, tagExpr)
+	if exact {
+		return boxFloat(n.float())
+	}
+	return v
+}
+
+// arithmeticValues returns the result of the arithmetic operator of the evaluated values,
+// which are not both exact numbers.
+// NOTE:
+//  The strings are concatenated by '+';
+//  panic if divided by zero.
+func arithmeticValues(op byte, v0, v1 interface{}) interface{} {
+	if op == '+' {
+		switch r := v0.(type) {
+		case float64:
+			v, _ := v1.(float64)
+			return r + v
+		case string:
+			v, _ := v1.(string)
+			return r + v
+		default:
+			return v1
+		}
+	}
+	f0, _ := v0.(float64)
+	f1, _ := v1.(float64)
+	switch op {
+	case '-':
+		return f0 - f1
+	case '*':
+		return f0 * f1
 	}
-	v0, _ := re.leftOperand.Run(currField, tagExpr).(float64)
-	return float64(int64(v0) % int64(v1))
+	f, err := arithmeticFloats(op, f0, f1)
+	if err != nil {
+		panic(err)
+	}
+	return f
 }
 
 type equalExprNode struct{ exprBackground }
@@ -95,8 +117,14 @@ type equalExprNode struct{ exprBackground }
 func newEqualExprNode() ExprNode { return &equalExprNode{} }
 
 func (ee *equalExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
-	v0 := ee.leftOperand.Run(currField, tagExpr)
-	v1 := ee.rightOperand.Run(currField, tagExpr)
+	r, ok, exact, v0, v1 := compareOperands(ee.leftOperand, ee.rightOperand, currField, tagExpr)
+	if exact {
+		return ok && r == 0
+	}
+	if v0 == nil || v1 == nil {
+		// the nil map and chan are equal to nil, as in Go
+		return isNilValue(v0) && isNilValue(v1)
+	}
 	switch r := v0.(type) {
 	case float64:
 		r1, ok := v1.(float64)
@@ -113,12 +141,48 @@ func (ee *equalExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
 		if ok {
 			return r == r1
 		}
-	case nil:
-		return v1 == nil
+	default:
+		// the result of split() is compared element-wise,
+		// the other slices and arrays are not equal as before
+		if isSplitNode(ee.leftOperand) || isSplitNode(ee.rightOperand) {
+			return equalElems(reflect.ValueOf(v0), reflect.ValueOf(v1))
+		}
 	}
 	return false
 }
 
+// isSplitNode returns whether the node is the call of split().
+func isSplitNode(node ExprNode) bool {
+	f, ok := node.(*funcExprNode)
+	return ok && f.name == "split"
+}
+
+// equalElems returns whether the slices or arrays have the equal elements in the same order,
+// the elements are compared as the evaluated values, e.g. int(1) equals float64(1).
+func equalElems(v0, v1 reflect.Value) bool {
+	if !isList(v0) || !isList(v1) || v0.Len() != v1.Len() {
+		return false
+	}
+	for i := 0; i < v0.Len(); i++ {
+		e0, e1 := elemValue(v0.Index(i)), elemValue(v1.Index(i))
+		switch e0.(type) {
+		case nil, float64, string, bool:
+			if e0 != e1 {
+				return false
+			}
+		default:
+			if !equalElems(reflect.ValueOf(e0), reflect.ValueOf(e1)) && !reflect.DeepEqual(e0, e1) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isList(v reflect.Value) bool {
+	return v.Kind() == reflect.Slice || v.Kind() == reflect.Array
+}
+
 type notEqualExprNode struct{ equalExprNode }
 
 func newNotEqualExprNode() ExprNode { return &notEqualExprNode{} }
@@ -132,8 +196,10 @@ type greaterExprNode struct{ exprBackground }
 func newGreaterExprNode() ExprNode { return &greaterExprNode{} }
 
 func (ge *greaterExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
-	v0 := ge.leftOperand.Run(currField, tagExpr)
-	v1 := ge.rightOperand.Run(currField, tagExpr)
+	r, ok, exact, v0, v1 := compareOperands(ge.leftOperand, ge.rightOperand, currField, tagExpr)
+	if exact {
+		return ok && r > 0
+	}
 	switch r := v0.(type) {
 	case float64:
 		r1, ok := v1.(float64)
@@ -145,6 +211,9 @@ func (ge *greaterExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
 		if ok {
 			return r > r1
 		}
+	case time.Time, *time.Time:
+		c, ok := compareTime([]interface{}{v0, v1})
+		return ok && c > 0
 	}
 	return false
 }
@@ -154,8 +223,10 @@ type greaterEqualExprNode struct{ exprBackground }
 func newGreaterEqualExprNode() ExprNode { return &greaterEqualExprNode{} }
 
 func (ge *greaterEqualExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
-	v0 := ge.leftOperand.Run(currField, tagExpr)
-	v1 := ge.rightOperand.Run(currField, tagExpr)
+	r, ok, exact, v0, v1 := compareOperands(ge.leftOperand, ge.rightOperand, currField, tagExpr)
+	if exact {
+		return ok && r >= 0
+	}
 	switch r := v0.(type) {
 	case float64:
 		r1, ok := v1.(float64)
@@ -167,6 +238,9 @@ func (ge *greaterEqualExprNode) Run(currField string, tagExpr *TagExpr) interfac
 		if ok {
 			return r >= r1
 		}
+	case time.Time, *time.Time:
+		c, ok := compareTime([]interface{}{v0, v1})
+		return ok && c >= 0
 	}
 	return false
 }
@@ -176,8 +250,10 @@ type lessExprNode struct{ exprBackground }
 func newLessExprNode() ExprNode { return &lessExprNode{} }
 
 func (le *lessExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
-	v0 := le.leftOperand.Run(currField, tagExpr)
-	v1 := le.rightOperand.Run(currField, tagExpr)
+	r, ok, exact, v0, v1 := compareOperands(le.leftOperand, le.rightOperand, currField, tagExpr)
+	if exact {
+		return ok && r < 0
+	}
 	switch r := v0.(type) {
 	case float64:
 		r1, ok := v1.(float64)
@@ -189,6 +265,9 @@ func (le *lessExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
 		if ok {
 			return r < r1
 		}
+	case time.Time, *time.Time:
+		c, ok := compareTime([]interface{}{v0, v1})
+		return ok && c < 0
 	}
 	return false
 }
@@ -198,8 +277,10 @@ type lessEqualExprNode struct{ exprBackground }
 func newLessEqualExprNode() ExprNode { return &lessEqualExprNode{} }
 
 func (le *lessEqualExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
-	v0 := le.leftOperand.Run(currField, tagExpr)
-	v1 := le.rightOperand.Run(currField, tagExpr)
+	r, ok, exact, v0, v1 := compareOperands(le.leftOperand, le.rightOperand, currField, tagExpr)
+	if exact {
+		return ok && r <= 0
+	}
 	switch r := v0.(type) {
 	case float64:
 		r1, ok := v1.(float64)
@@ -211,6 +292,9 @@ func (le *lessEqualExprNode) Run(currField string, tagExpr *TagExpr) interface{}
 		if ok {
 			return r <= r1
 		}
+	case time.Time, *time.Time:
+		c, ok := compareTime([]interface{}{v0, v1})
+		return ok && c <= 0
 	}
 	return false
 }
@@ -219,9 +303,17 @@ type andExprNode struct{ exprBackground }
 
 func newAndExprNode() ExprNode { return &andExprNode{} }
 
+// Run evaluates the operands from left to right,
+// and stops at the first false operand.
+// NOTE:
+//  The evaluation fault of an operand is false, or the result in strict mode.
 func (ae *andExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
 	for _, e := range [2]ExprNode{ae.leftOperand, ae.rightOperand} {
-		if !FakeBool(e.Run(currField, tagExpr)) {
+		r := runOperand(e, currField, tagExpr)
+		if isStrictFault(r, tagExpr) {
+			return r
+		}
+		if !FakeBool(r) {
 			return false
 		}
 	}
@@ -232,11 +324,114 @@ type orExprNode struct{ exprBackground }
 
 func newOrExprNode() ExprNode { return &orExprNode{} }
 
+// Run evaluates the operands from left to right,
+// and stops at the first true operand.
+// NOTE:
+//  The evaluation fault of an operand is false, or the result in strict mode;
+//  If all the operands are false, the result is false rather than the value of the last operand,
+//  so the failure refers to the whole group.
 func (oe *orExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
 	for _, e := range [2]ExprNode{oe.leftOperand, oe.rightOperand} {
-		if FakeBool(e.Run(currField, tagExpr)) {
+		r := runOperand(e, currField, tagExpr)
+		if isStrictFault(r, tagExpr) {
+			return r
+		}
+		if FakeBool(r) {
 			return true
 		}
 	}
 	return false
 }
+
+// bitwiseExprNode the integer bitwise operator, such as '&', '|', '^', '&^', '<<' and '>>',
+// which is evaluated in the integer domain, so the bits above 2^53 are kept.
+type bitwiseExprNode struct {
+	exprBackground
+	op string
+}
+
+func newBitwiseExprNode(op string) ExprNode { return &bitwiseExprNode{op: op} }
+
+// Run returns the float64 of the result, and the comparison of the operator uses the exact result.
+func (be *bitwiseExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
+	return boxFloat(be.number(currField, tagExpr).float())
+}
+
+// number returns the exact result of the operator.
+// NOTE:
+//  panic if the operand is not an integer, such as the float literal or field, instead of truncating it.
+func (be *bitwiseExprNode) number(currField string, tagExpr *TagExpr) number {
+	r, err := bitwiseNumbers(be.op, be.integerOf(be.leftOperand, currField, tagExpr), be.integerOf(be.rightOperand, currField, tagExpr))
+	if err != nil {
+		panic(fmt.Errorf("bitwise operator %s: %s", be.op, err.Error()))
+	}
+	return r
+}
+
+// integerOf returns the exact integer of the operand,
+// the other value, such as the result of len(), is an integer if it is a whole float64.
+func (be *bitwiseExprNode) integerOf(e ExprNode, currField string, tagExpr *TagExpr) number {
+	n, v, ok := evalNumber(e, currField, tagExpr)
+	if ok {
+		if n.kind == reflect.Float64 {
+			panic(fmt.Errorf("bitwise operator %s on the float operand %v", be.op, n.f))
+		}
+		return n
+	}
+	if f, ok := v.(float64); ok && f == math.Trunc(f) {
+		switch {
+		case f >= -(1<<63) && f < 1<<63:
+			return number{kind: reflect.Int64, i: int64(f)}
+		case f >= 0 && f < 1<<64:
+			return number{kind: reflect.Uint64, u: uint64(f)}
+		}
+	}
+	panic(fmt.Errorf("bitwise operator %s on the non-integer operand %v", be.op, v))
+}
+
+type nullCoalescingExprNode struct{ exprBackground }
+
+func newNullCoalescingExprNode() ExprNode { return &nullCoalescingExprNode{} }
+
+// Run returns the left operand, or the right operand if the left one is nil,
+// e.g. the nil pointer, the missing map key, or the evaluation fault of navigating through nil.
+// NOTE:
+//  The nil map, slice and chan are nil, as in Go;
+//  The evaluation fault of the left operand is not returned even in strict mode.
+func (ne *nullCoalescingExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
+	r := runOperand(ne.leftOperand, currField, tagExpr)
+	if _, ok := r.(*EvalFault); ok || isNilValue(r) {
+		return ne.rightOperand.Run(currField, tagExpr)
+	}
+	return r
+}
+
+// ternaryExprNode the ternary operator 'cond ? a : b',
+// which has the lowest priority and is right associative.
+type ternaryExprNode struct {
+	exprBackground
+	cond, then, els ExprNode
+}
+
+// Run evaluates the branch chosen by the truthiness of the condition,
+// and the other branch is not evaluated, e.g. it may refer to the nil pointer.
+// NOTE:
+//  The evaluation fault of the condition is false, or the result in strict mode.
+func (te *ternaryExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
+	r := runOperand(te.cond, currField, tagExpr)
+	if isStrictFault(r, tagExpr) {
+		return r
+	}
+	if FakeBool(r) {
+		return te.then.Run(currField, tagExpr)
+	}
+	return te.els.Run(currField, tagExpr)
+}
+
+// isStrictFault returns whether the value is an evaluation fault in strict mode.
+func isStrictFault(r interface{}, tagExpr *TagExpr) bool {
+	if _, ok := r.(*EvalFault); !ok {
+		return false
+	}
+	return tagExpr != nil && tagExpr.s != nil && tagExpr.s.vm.strict
+}
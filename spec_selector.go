@@ -15,6 +15,7 @@
 package tagexpr
 
 import (
+	"reflect"
 	"regexp"
 	"strings"
 )
@@ -25,6 +26,10 @@ type selectorExprNode struct {
 	subExprs      []ExprNode
 	boolOpposite  *bool
 	floatOpposite bool
+	// up the levels of the parent struct referenced by the '../' prefix
+	up int
+	// root whether the field is of the root struct referenced by the '/' prefix
+	root bool
 }
 
 func (p *Expr) readSelectorExprNode(expr *string) ExprNode {
@@ -32,8 +37,27 @@ func (p *Expr) readSelectorExprNode(expr *string) ExprNode {
 	if !found {
 		return nil
 	}
+	var up int
+	for strings.HasPrefix(field, parentSelectorPrefix) {
+		field = strings.TrimSpace(field[len(parentSelectorPrefix):])
+		up++
+	}
+	root := strings.HasPrefix(field, rootSelectorPrefix)
+	if root {
+		field = strings.TrimSpace(field[len(rootSelectorPrefix):])
+	}
+	switch {
+	case up > 0:
+		p.ancestorRefs = append(p.ancestorRefs, ancestorRef{up: up, field: field})
+	case root:
+		p.rootRefs = append(p.rootRefs, field)
+	case field != "":
+		p.selectorRefs = append(p.selectorRefs, field)
+	}
 	operand := &selectorExprNode{
 		field:         field,
+		up:            up,
+		root:          root,
 		name:          name,
 		boolOpposite:  boolOpposite,
 		floatOpposite: floatOpposite,
@@ -51,7 +75,22 @@ func (p *Expr) readSelectorExprNode(expr *string) ExprNode {
 	return operand
 }
 
-var selectorRegexp = regexp.MustCompile(`^([\!\+\-]*)(\([ \t]*[A-Za-z_]+[A-Za-z0-9_\.]*[ \t]*\))?(\$)([\)\[\],\+\-\*\/%><\|&!=\^ \t\\]|$)`)
+const (
+	// parentSelectorPrefix the prefix of the field selector which refers to the parent struct,
+	// such as (../Country)$
+	parentSelectorPrefix = "../"
+	// rootSelectorPrefix the prefix of the field selector which refers to the root struct,
+	// such as (/Country)$
+	rootSelectorPrefix = "/"
+)
+
+// ancestorRef the field of the ancestor struct referenced by the '../' prefix
+type ancestorRef struct {
+	up    int
+	field string
+}
+
+var selectorRegexp = regexp.MustCompile(`^([\!\+\-]*)(\([ \t]*(?:/[ \t]*|(?:\.\./[ \t]*)*)[A-Za-z_]+[A-Za-z0-9_\.]*[ \t]*\))?(\$)([\)\[\],\+\-\*\/%><\|&!=\^\? \t\\]|$)`)
 
 func findSelector(expr *string) (field string, name string, subSelector []string, boolOpposite *bool, floatOpposite, found bool) {
 	raw := *expr
@@ -117,6 +156,20 @@ func (ve *selectorExprNode) Run(currField string, tagExpr *TagExpr) interface{}
 	if field == "" {
 		field = currField
 	}
+	switch {
+	case ve.up > 0:
+		// the parent is absent when the struct is evaluated standalone
+		tagExpr = tagExpr.ancestor(ve.up)
+		if tagExpr == nil {
+			return nil
+		}
+	case ve.root:
+		// the root is absent when the struct of the field is evaluated standalone
+		if tagExpr.parent == nil && !strings.Contains(currField, FieldSeparator) {
+			return nil
+		}
+		tagExpr = tagExpr.root()
+	}
 	v := tagExpr.getValue(field, subFields)
 	if ve.floatOpposite {
 		if float, ok := v.(float64); ok {
@@ -126,3 +179,80 @@ func (ve *selectorExprNode) Run(currField string, tagExpr *TagExpr) interface{}
 	}
 	return realValue(v, ve.boolOpposite)
 }
+
+// elemExprNode the element operand of the quantifier function,
+// '#v' is the element and '#k' is its index or map key, such as #v.Qty and #v['a'][0].
+type elemExprNode struct {
+	exprBackground
+	key          bool
+	subExprs     []ExprNode
+	boolOpposite *bool
+}
+
+var (
+	elemRegexp     = regexp.MustCompile(`^#([kv])`)
+	elemNameRegexp = regexp.MustCompile(`^\.([A-Za-z_][A-Za-z0-9_]*)`)
+	elemEndRegexp  = regexp.MustCompile(`^([\)\],\+\-\*\/%><\|&!=\^\? \t\\]|$)`)
+)
+
+func readElemExprNode(p *Expr, expr *string) ExprNode {
+	last, boolOpposite := getBoolOpposite(expr)
+	r := elemRegexp.FindStringSubmatch(last)
+	if r == nil {
+		return nil
+	}
+	s := last[len(r[0]):]
+	operand := &elemExprNode{key: r[1] == "k", boolOpposite: boolOpposite}
+	for {
+		if name := elemNameRegexp.FindStringSubmatch(s); name != nil {
+			s = s[len(name[0]):]
+			operand.subExprs = append(operand.subExprs, &stringExprNode{val: name[1]})
+			continue
+		}
+		sub := readPairedSymbol(&s, '[', ']')
+		if sub == nil {
+			break
+		}
+		grp := newGroupExprNode()
+		_, err := p.parseExprNode(sub, grp)
+		if err != nil || grp.RightOperand() == nil {
+			return nil
+		}
+		sortPriority(grp.RightOperand())
+		operand.subExprs = append(operand.subExprs, grp)
+	}
+	if !elemEndRegexp.MatchString(s) {
+		return nil
+	}
+	*expr = s
+	return operand
+}
+
+// value returns the raw value of the element, or false if the quantifier binds no element.
+func (ee *elemExprNode) value(currField string, tagExpr *TagExpr) (reflect.Value, bool) {
+	if tagExpr == nil || len(tagExpr.elems) == 0 {
+		return reflect.Value{}, false
+	}
+	// the innermost quantifier binds the element
+	elem := tagExpr.elems[len(tagExpr.elems)-1]
+	v := elem.value
+	if ee.key {
+		v = elem.key
+	}
+	if len(ee.subExprs) == 0 {
+		return v, v.IsValid()
+	}
+	subFields := make([]interface{}, len(ee.subExprs))
+	for i, e := range ee.subExprs {
+		subFields[i] = e.Run(currField, tagExpr)
+	}
+	return subValueOf(v, subFields)
+}
+
+func (ee *elemExprNode) Run(currField string, tagExpr *TagExpr) interface{} {
+	v, ok := ee.value(currField, tagExpr)
+	if !ok {
+		return realValue(nil, ee.boolOpposite)
+	}
+	return realValue(elemValue(v), ee.boolOpposite)
+}
@@ -124,6 +124,12 @@ func TestFindSelector(t *testing.T) {
 		{expr: "(A0)$", field: "A0", name: "$", found: true},
 		{expr: "!!(A0)$", field: "A0", name: "$", found: true},
 		{expr: "--(A0)$", field: "A0", name: "$", found: true},
+		{expr: "(../A)$", field: "../A", name: "$", found: true},
+		{expr: "(../../A.B)$", field: "../../A.B", name: "$", found: true},
+		{expr: "(..A)$", last: "(..A)$"},
+		{expr: "(/A.B)$", field: "/A.B", name: "$", found: true},
+		{expr: "(/../A)$", last: "(/../A)$"},
+		{expr: "(//A)$", last: "(//A)$"},
 		{expr: "(A0)$(A1)$", last: "(A0)$(A1)$"},
 		{expr: "(A0)$ $(A1)$", field: "A0", name: "$", found: true, last: " $(A1)$"},
 		{expr: "$a", last: "$a"},
@@ -16,9 +16,11 @@
 package tagexpr
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -33,6 +35,16 @@ type VM struct {
 	tagName   string
 	structJar map[int32]*structVM
 	rw        sync.RWMutex
+	// strict indicates that the evaluation faults are not converted to false in && and ||
+	strict bool
+	// exprNameChecker checks the expression names when registering the struct
+	exprNameChecker func(exprName string) error
+	// tagParser translates the tag value of the field to the expressions
+	tagParser func(field reflect.StructField, tagValue string) (map[string]string, error)
+	// noDynamicDispatch indicates that the dynamic values of the interfaces are not ranged
+	noDynamicDispatch bool
+	// denyUnexported indicates that the expressions of the unexported fields are not allowed
+	denyUnexported bool
 }
 
 // structVM tag expression set of struct
@@ -44,7 +56,26 @@ type structVM struct {
 	fieldsWithIndirectStructVM []*fieldVM
 	exprs                      map[string]*Expr
 	exprSelectorList           []string
-	ifaceTagExprGetters        []func(unsafe.Pointer, string, func(*TagExpr, error) error) error
+	// exprFields the field selectors of the expressions keyed by the expression selectors,
+	// since the expression name may contain the separator, such as 'create@msg'
+	exprFields          map[string]string
+	ifaceTagExprGetters []func(unsafe.Pointer, string, func(*TagExpr, error) error) error
+	// ifaceFieldSelectors the field selectors of ifaceTagExprGetters
+	ifaceFieldSelectors []string
+	// promoted the fields promoted from the embedded structs, keyed by the promoted selector, see promote
+	promoted map[string]*promotedField
+	// rootRefsErr the error of the fields referenced by the '/' prefix if the struct is the root, see checkRootRefs
+	rootRefsErr error
+	// pool the released TagExprs of the struct with their handlers, see TagExpr.Release
+	pool sync.Pool
+}
+
+// promotedField the field promoted from the embedded structs, such as 'ID' of 'Base.ID'.
+type promotedField struct {
+	// selectors the full selectors of the candidates at the shallowest depth,
+	// more than one if the promoted selector is ambiguous
+	selectors []string
+	depth     int
 }
 
 // fieldVM tag expression set of struct field
@@ -79,6 +110,58 @@ func New(tagName ...string) *VM {
 	}
 }
 
+// SetStrictMode if set to true, the evaluation fault of an operand of && or ||
+// is returned as the result instead of being converted to false.
+// NOTE:
+//  The default is false;
+//  The evaluation fault is the *EvalFault value, such as a panic in the function.
+func (vm *VM) SetStrictMode(enable bool) *VM {
+	vm.strict = enable
+	return vm
+}
+
+// SetExprNameChecker sets the checker of the expression names,
+// which is called when the struct type is registered.
+// NOTE:
+//  The default expression name '@' is not checked;
+//  It should be set before the struct type is registered.
+func (vm *VM) SetExprNameChecker(fn func(exprName string) error) *VM {
+	vm.exprNameChecker = fn
+	return vm
+}
+
+// SetDynamicDispatch if set to false, Range skips the dynamic values of the interface fields
+// and the interface elements of the map, slice and array fields.
+// NOTE:
+//  The default is true, the expressions of the dynamic struct are ranged with the field path prefixed,
+//  and the struct which is already being ranged by the dispatching chain is skipped.
+func (vm *VM) SetDynamicDispatch(enable bool) *VM {
+	vm.noDynamicDispatch = !enable
+	return vm
+}
+
+// SetAllowUnexported if set to false, the struct type with the expressions on the unexported fields,
+// or the references to the unexported fields, fails to register.
+// NOTE:
+//  The default is true, the unexported fields are evaluated by the unsafe access;
+//  The field of the unexported embedded struct is not regarded as unexported;
+//  It should be set before the struct type is registered.
+func (vm *VM) SetAllowUnexported(allow bool) *VM {
+	vm.denyUnexported = !allow
+	return vm
+}
+
+// SetTagParser sets the parser which translates the tag value of the field
+// to the expressions keyed by the expression names, such as a foreign tag syntax.
+// NOTE:
+//  The default expression is keyed by '@';
+//  The parser is not called for the field without the tag;
+//  It should be set before the struct type is registered.
+func (vm *VM) SetTagParser(fn func(field reflect.StructField, tagValue string) (exprs map[string]string, err error)) *VM {
+	vm.tagParser = fn
+	return vm
+}
+
 // MustRun is similar to Run, but panic when error.
 func (vm *VM) MustRun(structOrStructPtrOrReflectValue interface{}) *TagExpr {
 	te, err := vm.Run(structOrStructPtrOrReflectValue)
@@ -128,6 +211,9 @@ func (vm *VM) Run(structOrStructPtrOrReflectValue interface{}) (*TagExpr, error)
 		}
 		vm.rw.Unlock()
 	}
+	if s.rootRefsErr != nil {
+		return nil, s.rootRefsErr
+	}
 	return s.newTagExpr(ptr, ""), nil
 }
 
@@ -231,6 +317,9 @@ func (vm *VM) subRun(path string, t reflect.Type, tid int32, ptr unsafe.Pointer)
 		}
 		vm.rw.Unlock()
 	}
+	if s.rootRefsErr != nil {
+		return nil, s.rootRefsErr
+	}
 	return s.newTagExpr(ptr, path), nil
 }
 
@@ -254,6 +343,7 @@ func (vm *VM) registerStructLocked(structType reflect.Type) (*structVM, error) {
 		structField = structType.Field(i)
 		field, err := s.newFieldVM(structField)
 		if err != nil {
+			delete(vm.structJar, tid)
 			return nil, err
 		}
 		switch field.elemKind {
@@ -282,11 +372,244 @@ func (vm *VM) registerStructLocked(structType reflect.Type) (*structVM, error) {
 			if err != nil {
 				return nil, err
 			}
+		case reflect.Chan:
+			field.setLengthGetter()
 		}
 	}
+	s.promote(structType)
+	err = s.checkFieldRefs(structType)
+	if err == nil {
+		err = s.checkAncestorRefs()
+	}
+	if err == nil {
+		// the struct may be valid as the nested one, so the error is returned when it is run as the root
+		s.rootRefsErr = s.checkRootRefs(s, "", make(map[*structVM]bool, 4))
+	}
+	if err == nil && vm.denyUnexported {
+		err = s.checkUnexported()
+	}
+	if err != nil {
+		delete(vm.structJar, tid)
+		return nil, err
+	}
 	return s, nil
 }
 
+// checkFieldRefs checks whether the fields referenced by the expressions exist,
+// and whether the promoted selectors are ambiguous.
+func (s *structVM) checkFieldRefs(structType reflect.Type) error {
+	for i := structType.NumField() - 1; i >= 0; i-- {
+		f := s.fields[structType.Field(i).Name]
+		for exprSelector, expr := range f.exprs {
+			for _, fs := range expr.fieldRefs {
+				if err := s.checkFieldRef(exprSelector, fs, fs); err != nil {
+					return err
+				}
+			}
+			for _, fs := range expr.selectorRefs {
+				if _, candidates := s.lookupField(fs); len(candidates) > 0 {
+					return s.ambiguousError(exprSelector, fs, candidates)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkFieldRef checks whether the field referenced as @ref exists and is unambiguous.
+func (s *structVM) checkFieldRef(exprSelector, fieldSelector, ref string) error {
+	f, candidates := s.lookupField(fieldSelector)
+	if len(candidates) > 0 {
+		return s.ambiguousError(exprSelector, ref, candidates)
+	}
+	if f == nil {
+		return fmt.Errorf("%s.%s: field selector %q does not exist", s.name, exprSelector, ref)
+	}
+	return nil
+}
+
+func (s *structVM) ambiguousError(exprSelector, ref string, candidates []string) error {
+	return fmt.Errorf("%s.%s: ambiguous field selector %q: %s", s.name, exprSelector, ref, strings.Join(candidates, ", "))
+}
+
+// promote records the fields promoted from the embedded structs by the Go promotion rules,
+// e.g. 'ID' for 'Base.ID' and 'Address.City' for 'Address.Geo.City' if Base and Geo are embedded.
+// NOTE:
+//  The declared field shadows the promoted one, and the shallower promoted field shadows the deeper one;
+//  The promoted selector with several candidates at the same depth is ambiguous.
+func (s *structVM) promote(structType reflect.Type) {
+	add := func(alias, selector string, depth int) {
+		if _, ok := s.fields[alias]; ok {
+			return
+		}
+		if s.promoted == nil {
+			s.promoted = make(map[string]*promotedField)
+		}
+		p := s.promoted[alias]
+		switch {
+		case p == nil || depth < p.depth:
+			s.promoted[alias] = &promotedField{selectors: []string{selector}, depth: depth}
+		case depth == p.depth:
+			for _, sel := range p.selectors {
+				if sel == selector {
+					return
+				}
+			}
+			p.selectors = append(p.selectors, selector)
+			sort.Strings(p.selectors)
+		}
+	}
+	for i := 0; i < structType.NumField(); i++ {
+		structField := structType.Field(i)
+		f := s.fields[structField.Name]
+		if f == nil || f.elemKind != reflect.Struct || f.origin == s {
+			continue
+		}
+		sub := f.origin
+		prefix := structField.Name + FieldSeparator
+		// the qualified selectors of the fields promoted in the nested struct, such as 'Address.City'
+		for alias, p := range sub.promoted {
+			for _, sel := range p.selectors {
+				add(prefix+alias, prefix+sel, p.depth)
+			}
+		}
+		if !structField.Anonymous {
+			continue
+		}
+		for _, sel := range sub.fieldSelectorList {
+			add(sel, prefix+sel, 1)
+		}
+		for alias, p := range sub.promoted {
+			for _, sel := range p.selectors {
+				add(alias, prefix+sel, p.depth+1)
+			}
+		}
+	}
+}
+
+// lookupField returns the field of the selector, which may be promoted from the embedded structs,
+// and the candidates if the promoted selector is ambiguous.
+func (s *structVM) lookupField(fieldSelector string) (*fieldVM, []string) {
+	if f := s.fields[fieldSelector]; f != nil {
+		return f, nil
+	}
+	p := s.promoted[fieldSelector]
+	switch {
+	case p == nil:
+		return nil, nil
+	case len(p.selectors) > 1:
+		return nil, p.selectors
+	}
+	return s.fields[p.selectors[0]], nil
+}
+
+// checkAncestorRefs checks whether the fields referenced by the '../' prefix exist,
+// if the ancestor struct is nested in the struct.
+// NOTE:
+//  The ancestor above the struct is unknown until it is nested, so the reference is nil if absent.
+func (s *structVM) checkAncestorRefs() error {
+	for _, fieldSelector := range s.fieldSelectorList {
+		f := s.fields[fieldSelector]
+		if len(f.exprs) == 0 {
+			continue
+		}
+		// the path of the struct containing the field
+		dirs := strings.Split(fieldSelector, FieldSeparator)
+		dirs = dirs[:len(dirs)-1]
+		for exprSelector, expr := range f.exprs {
+			for _, ref := range expr.ancestorRefs {
+				if ref.up > len(dirs) {
+					continue
+				}
+				fs := strings.Join(append(dirs[:len(dirs)-ref.up:len(dirs)-ref.up], ref.field), FieldSeparator)
+				err := s.checkFieldRef(exprSelector, fs, strings.Repeat(parentSelectorPrefix, ref.up)+ref.field)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkRootRefs checks whether the fields referenced by the '/' prefix exist in the root struct,
+// for the expressions of the fields nested in s, since the root is absent for the fields of the standalone struct.
+// NOTE:
+//  The structs of the indirect fields, such as the slice elements, are checked recursively.
+func (s *structVM) checkRootRefs(root *structVM, prefix string, visited map[*structVM]bool) error {
+	if visited[s] {
+		return nil
+	}
+	visited[s] = true
+	for _, fieldSelector := range s.fieldSelectorList {
+		if prefix == "" && !strings.Contains(fieldSelector, FieldSeparator) {
+			continue
+		}
+		for exprSelector, expr := range s.fields[fieldSelector].exprs {
+			for _, ref := range expr.rootRefs {
+				err := root.checkFieldRef(prefix+exprSelector, ref, rootSelectorPrefix+ref)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for _, f := range s.fieldsWithIndirectStructVM {
+		for _, sub := range [...]*structVM{f.mapOrSliceElemStructVM, f.mapKeyStructVM} {
+			if sub == nil {
+				continue
+			}
+			if err := sub.checkRootRefs(root, prefix+f.fieldSelector+FieldSeparator, visited); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkUnexported checks whether the expressions are on the unexported fields,
+// or reference the unexported fields.
+func (s *structVM) checkUnexported() error {
+	for _, fieldSelector := range s.fieldSelectorList {
+		f := s.fields[fieldSelector]
+		if len(f.exprs) == 0 {
+			continue
+		}
+		if fs := s.unexportedIn(fieldSelector); fs != "" {
+			return fmt.Errorf("cannot validate unexported field %s.%s", s.name, fs)
+		}
+		for _, expr := range f.exprs {
+			for _, refs := range [2][]string{expr.fieldRefs, expr.selectorRefs} {
+				for _, ref := range refs {
+					if fs := s.unexportedIn(ref); fs != "" {
+						return fmt.Errorf("cannot validate unexported field %s.%s", s.name, fs)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// unexportedIn returns the first unexported field in the path of the field selector,
+// or "" if there is none.
+func (s *structVM) unexportedIn(fieldSelector string) string {
+	for i := 0; ; i++ {
+		j := strings.Index(fieldSelector[i:], FieldSeparator)
+		prefix := fieldSelector
+		if j >= 0 {
+			prefix = fieldSelector[:i+j]
+		}
+		if f, ok := s.fields[prefix]; ok && f.structField.PkgPath != "" && !f.structField.Anonymous {
+			return prefix
+		}
+		if j < 0 {
+			return ""
+		}
+		i += j
+	}
+}
+
 func (vm *VM) registerIndirectStructLocked(field *fieldVM) error {
 	field.setLengthGetter()
 	if field.tagOp == tagOmit {
@@ -332,6 +655,7 @@ func (vm *VM) newStructVM() *structVM {
 		fieldsWithIndirectStructVM: make([]*fieldVM, 0, 32),
 		exprs:                      make(map[string]*Expr, 64),
 		exprSelectorList:           make([]string, 0, 64),
+		exprFields:                 make(map[string]string, 64),
 	}
 }
 
@@ -415,8 +739,9 @@ func (s *structVM) mergeSubStructVM(field *fieldVM, sub *structVM) {
 	// 	s.fieldsWithIndirectStructVM = append(s.fieldsWithIndirectStructVM, f)
 	// }
 
-	for _, _subFn := range sub.ifaceTagExprGetters {
+	for i, _subFn := range sub.ifaceTagExprGetters {
 		subFn := _subFn
+		s.ifaceFieldSelectors = append(s.ifaceFieldSelectors, field.fieldSelector+FieldSeparator+sub.ifaceFieldSelectors[i])
 		s.ifaceTagExprGetters = append(s.ifaceTagExprGetters, func(ptr unsafe.Pointer, pathPrefix string, fn func(*TagExpr, error) error) error {
 			ptr = field.getElemPtr(ptr)
 			if ptr == nil {
@@ -502,6 +827,7 @@ func (s *structVM) newChildField(parent *fieldVM, child *fieldVM, toBind bool) *
 				f.exprs[selector] = v
 				s.exprs[selector] = v
 				s.exprSelectorList = append(s.exprSelectorList, selector)
+				s.exprFields[selector] = f.fieldSelector
 			}
 		}
 	}
@@ -528,6 +854,7 @@ func (s *structVM) setIfaceTagExprGetter(f *fieldVM) {
 	if f.tagOp == tagOmit {
 		return
 	}
+	s.ifaceFieldSelectors = append(s.ifaceFieldSelectors, f.fieldSelector)
 	s.ifaceTagExprGetters = append(s.ifaceTagExprGetters, func(ptr unsafe.Pointer, pathPrefix string, fn func(*TagExpr, error) error) error {
 		v := f.packElemFrom(ptr)
 		if !v.IsValid() || v.IsNil() {
@@ -642,12 +969,20 @@ func (vm *VM) getStructType(t reflect.Type) (reflect.Type, error) {
 }
 
 func (s *structVM) newTagExpr(ptr unsafe.Pointer, path string) *TagExpr {
-	te := &TagExpr{
-		s:    s,
-		ptr:  ptr,
-		sub:  make(map[string]*TagExpr, 8),
-		path: strings.TrimPrefix(path, "."),
+	te, _ := s.pool.Get().(*TagExpr)
+	if te == nil {
+		te = &TagExpr{s: s}
 	}
+	te.ptr = ptr
+	te.path = strings.TrimPrefix(path, ".")
+	return te
+}
+
+// newChildTagExpr creates the TagExpr of the nested struct,
+// whose parent is the TagExpr of the struct containing it.
+func (s *structVM) newChildTagExpr(parent *TagExpr, ptr unsafe.Pointer, path string) *TagExpr {
+	te := s.newTagExpr(ptr, path)
+	te.parent = parent
 	return te
 }
 
@@ -657,6 +992,71 @@ type TagExpr struct {
 	ptr  unsafe.Pointer
 	sub  map[string]*TagExpr
 	path string
+	ctx  context.Context
+	// data the map evaluated by MapExpr instead of the struct
+	data map[string]interface{}
+	// dispatcher the TagExpr which dispatches its interface value to this one
+	dispatcher *TagExpr
+	// parent the TagExpr of the struct containing this nested one,
+	// nil if the struct is evaluated standalone
+	parent *TagExpr
+	// handlers the handlers of the expressions in the order of exprSelectorList,
+	// which are allocated at once
+	handlers []ExprHandler
+	// elems the elements bound by the running quantifier functions, the innermost is the last
+	elems []elemBinding
+}
+
+// elemBinding the element of the list bound by the quantifier function, see elemExprNode
+type elemBinding struct {
+	key, value reflect.Value
+}
+
+// handlersOf returns the handlers of the expressions, which are allocated lazily.
+func (t *TagExpr) handlersOf() []ExprHandler {
+	if t.handlers == nil {
+		t.handlers = make([]ExprHandler, len(t.s.exprSelectorList))
+	}
+	return t.handlers
+}
+
+// Release puts the TagExpr back to the pool of the struct type with the TagExprs of the nested structs checked out by it,
+// so that they and their handlers are reused by the later runs without allocation.
+// NOTE:
+//  It is optional, the TagExpr not released is collected by GC;
+//  The TagExpr and its ExprHandlers must not be used after that.
+func (t *TagExpr) Release() {
+	if t == nil || t.s == nil {
+		return
+	}
+	for fs, sub := range t.sub {
+		sub.Release()
+		delete(t.sub, fs)
+	}
+	for i := range t.handlers {
+		t.handlers[i] = ExprHandler{}
+	}
+	elems := t.elems[:cap(t.elems)]
+	for i := range elems {
+		elems[i] = elemBinding{}
+	}
+	*t = TagExpr{
+		s:        t.s,
+		sub:      t.sub,
+		handlers: t.handlers,
+		elems:    elems[:0],
+	}
+	t.s.pool.Put(t)
+}
+
+// Context returns the context of the evaluation.
+// NOTE:
+//  If t==nil or the context is not set, return context.Background().
+func (t *TagExpr) Context() context.Context {
+	if t == nil || t.ctx == nil {
+		return context.Background()
+	}
+	return t.ctx
 }
 
 // EvalFloat evaluates the value of the struct tag expression by the selector expression.
@@ -683,6 +1083,8 @@ func (t *TagExpr) EvalBool(exprSelector string) bool {
 }
 
 // FakeBool fakes any type as a boolean.
+// NOTE:
+//  The error returned by the custom function is false.
 func FakeBool(v interface{}) bool {
 	switch r := v.(type) {
 	case float64:
@@ -691,7 +1093,7 @@ func FakeBool(v interface{}) bool {
 		return r != ""
 	case bool:
 		return r
-	case nil:
+	case nil, error:
 		return false
 	default:
 		return true
@@ -740,7 +1142,7 @@ func (t *TagExpr) Eval(exprSelector string) interface{} {
 			return nil
 		}
 	}
-	dir, base := splitFieldSelector(exprSelector)
+	dir, base := splitFieldSelector(t.s.exprFields[exprSelector])
 	targetTagExpr, err := t.checkout(dir)
 	if err != nil {
 		return nil
@@ -753,15 +1155,47 @@ func (t *TagExpr) Eval(exprSelector string) interface{} {
 // NOTE:
 //  eval result types: float64, string, bool, nil
 func (t *TagExpr) Range(fn func(*ExprHandler) error) error {
+	return t.rangeSelected(nil, fn)
+}
+
+// RangeSelected is similar to Range, but only loops through the tag expressions
+// of the fields specified by the selectors and their nested fields.
+// NOTE:
+//  The selector is in the dotted form, such as 'A' or 'A.B';
+//  If no selector is specified, loop through nothing.
+func (t *TagExpr) RangeSelected(fieldSelectors []string, fn func(*ExprHandler) error) error {
+	if len(fieldSelectors) == 0 {
+		return nil
+	}
+	return t.rangeSelected(fieldSelectors, fn)
+}
+
+// rangeSelected loops through the tag expressions of the selected fields,
+// if fieldSelectors==nil, loop through all.
+func (t *TagExpr) rangeSelected(fieldSelectors []string, fn func(*ExprHandler) error) error {
 	var err error
 	if list := t.s.exprSelectorList; len(list) > 0 {
-		for _, es := range list {
-			dir, base := splitFieldSelector(es)
-			targetTagExpr, err := t.checkout(dir)
-			if err != nil {
+		handlers := t.handlersOf()
+		for i, es := range list {
+			field := t.s.exprFields[es]
+			if fieldSelectors != nil && !isSelectedField(fieldSelectors, field) {
 				continue
 			}
-			err = fn(newExprHandler(t, targetTagExpr, base, es))
+			eh := &handlers[i]
+			if eh.expr == nil {
+				dir, base := splitFieldSelector(field)
+				targetTagExpr, err := t.checkout(dir)
+				if err != nil {
+					continue
+				}
+				*eh = ExprHandler{
+					base:       base,
+					selector:   es,
+					expr:       t,
+					targetExpr: targetTagExpr,
+				}
+			}
+			err = fn(eh)
 			if err != nil {
 				return err
 			}
@@ -772,10 +1206,14 @@ func (t *TagExpr) Range(fn func(*ExprHandler) error) error {
 
 	if list := t.s.fieldsWithIndirectStructVM; len(list) > 0 {
 		for _, f := range list {
+			if fieldSelectors != nil && !isSelectedField(fieldSelectors, f.fieldSelector) {
+				continue
+			}
 			v := f.packElemFrom(ptr)
 			if !v.IsValid() {
 				continue
 			}
+			owner := t.ownerOf(f.fieldSelector)
 			omitNil := f.tagOp == tagOmitNil
 			mapKeyStructVM := f.mapKeyStructVM
 			mapOrSliceElemStructVM := f.mapOrSliceElemStructVM
@@ -791,12 +1229,12 @@ func (t *TagExpr) Range(fn func(*ExprHandler) error) error {
 						if omitNil && p == nil {
 							continue
 						}
-						err = mapKeyStructVM.newTagExpr(p, keyPath).Range(fn)
+						err = mapKeyStructVM.newChildTagExpr(owner, p, keyPath).Range(fn)
 						if err != nil {
 							return err
 						}
 					} else if keyIface {
-						err = t.subRange(omitNil, keyPath, key, fn)
+						err = t.subRange(owner, omitNil, keyPath, key, fn)
 						if err != nil {
 							return err
 						}
@@ -806,12 +1244,12 @@ func (t *TagExpr) Range(fn func(*ExprHandler) error) error {
 						if omitNil && p == nil {
 							continue
 						}
-						err = mapOrSliceElemStructVM.newTagExpr(p, f.fieldSelector+"{"+key.String()+"}").Range(fn)
+						err = mapOrSliceElemStructVM.newChildTagExpr(owner, p, f.fieldSelector+"{"+key.String()+"}").Range(fn)
 						if err != nil {
 							return err
 						}
 					} else if valueIface {
-						err = t.subRange(omitNil, f.fieldSelector+"{"+key.String()+"}", v.MapIndex(key), fn)
+						err = t.subRange(owner, omitNil, f.fieldSelector+"{"+key.String()+"}", v.MapIndex(key), fn)
 						if err != nil {
 							return err
 						}
@@ -826,12 +1264,12 @@ func (t *TagExpr) Range(fn func(*ExprHandler) error) error {
 						if omitNil && p == nil {
 							continue
 						}
-						err = mapOrSliceElemStructVM.newTagExpr(p, f.fieldSelector+"["+strconv.Itoa(i)+"]").Range(fn)
+						err = mapOrSliceElemStructVM.newChildTagExpr(owner, p, f.fieldSelector+"["+strconv.Itoa(i)+"]").Range(fn)
 						if err != nil {
 							return err
 						}
 					} else if valueIface {
-						err = t.subRange(omitNil, f.fieldSelector+"["+strconv.Itoa(i)+"]", v.Index(i), fn)
+						err = t.subRange(owner, omitNil, f.fieldSelector+"["+strconv.Itoa(i)+"]", v.Index(i), fn)
 						if err != nil {
 							return err
 						}
@@ -841,13 +1279,16 @@ func (t *TagExpr) Range(fn func(*ExprHandler) error) error {
 		}
 	}
 
-	if list := t.s.ifaceTagExprGetters; len(list) > 0 {
-		for _, getter := range list {
+	if list := t.s.ifaceTagExprGetters; len(list) > 0 && !t.s.vm.noDynamicDispatch {
+		for i, getter := range list {
+			if fieldSelectors != nil && !isSelectedField(fieldSelectors, t.s.ifaceFieldSelectors[i]) {
+				continue
+			}
 			err = getter(ptr, "", func(te *TagExpr, err error) error {
 				if err != nil {
 					return err
 				}
-				return te.Range(fn)
+				return t.dispatchRange(t.ownerOf(t.s.ifaceFieldSelectors[i]), te, fn)
 			})
 			if err != nil {
 				return err
@@ -857,15 +1298,41 @@ func (t *TagExpr) Range(fn func(*ExprHandler) error) error {
 	return nil
 }
 
-func (t *TagExpr) subRange(omitNil bool, path string, value reflect.Value, fn func(*ExprHandler) error) error {
+// isSelectedField returns whether the field is one of the selected fields or nested in them.
+func isSelectedField(fieldSelectors []string, fieldSelector string) bool {
+	for _, fs := range fieldSelectors {
+		if fieldSelector == fs || (strings.HasPrefix(fieldSelector, fs) && fieldSelector[len(fs):len(fs)+1] == FieldSeparator) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *TagExpr) subRange(parent *TagExpr, omitNil bool, path string, value reflect.Value, fn func(*ExprHandler) error) error {
+	if t.s.vm.noDynamicDispatch {
+		return nil
+	}
 	return t.s.vm.subRunAll(omitNil, path, value, func(te *TagExpr, err error) error {
 		if err != nil {
 			return err
 		}
-		return te.Range(fn)
+		return t.dispatchRange(parent, te, fn)
 	})
 }
 
+// dispatchRange ranges the TagExpr of the dynamic value of the interface,
+// unless the same struct is already being ranged by the dispatching chain.
+func (t *TagExpr) dispatchRange(parent, te *TagExpr, fn func(*ExprHandler) error) error {
+	for d := t; d != nil; d = d.dispatcher {
+		if d.ptr == te.ptr && d.s == te.s {
+			return nil
+		}
+	}
+	te.dispatcher = t
+	te.parent = parent
+	return te.Range(fn)
+}
+
 var (
 	errFieldSelector = errors.New("field selector does not exist")
 	errOmitNil       = errors.New("omit nil")
@@ -888,30 +1355,108 @@ func (t *TagExpr) checkout(fs string) (*TagExpr, error) {
 	}
 	ptr := f.getElemPtr(t.ptr)
 	if f.tagOp == tagOmitNil && unsafe.Pointer(ptr) == nil {
-		t.sub[fs] = nil
+		t.setSub(fs, nil)
 		return nil, errOmitNil
 	}
-	subTagExpr = f.origin.newTagExpr(ptr, t.path)
-	t.sub[fs] = subTagExpr
+	subTagExpr = f.origin.newChildTagExpr(t.ownerOf(fs), ptr, t.path)
+	t.setSub(fs, subTagExpr)
 	return subTagExpr, nil
 }
 
-func (t *TagExpr) getValue(fieldSelector string, subFields []interface{}) (v interface{}) {
-	f := t.s.fields[fieldSelector]
-	if f == nil {
-		return nil
+// setSub caches the TagExpr of the nested struct, the map is created lazily.
+func (t *TagExpr) setSub(fs string, subTagExpr *TagExpr) {
+	if t.sub == nil {
+		t.sub = make(map[string]*TagExpr, 8)
 	}
-	if f.valueGetter == nil {
+	t.sub[fs] = subTagExpr
+}
+
+// ownerOf returns the TagExpr of the struct containing the field,
+// nil if it can not be checked out.
+func (t *TagExpr) ownerOf(fieldSelector string) *TagExpr {
+	dir, _ := splitFieldSelector(fieldSelector)
+	owner, err := t.checkout(dir)
+	if err != nil {
 		return nil
 	}
-	v = f.valueGetter(t.ptr)
-	if v == nil {
+	return owner
+}
+
+// ancestor returns the TagExpr of the ancestor struct @up levels above,
+// nil if there is no such ancestor.
+func (t *TagExpr) ancestor(up int) *TagExpr {
+	for ; up > 0 && t != nil; up-- {
+		t = t.parent
+	}
+	return t
+}
+
+// root returns the TagExpr of the root struct, itself if the struct is evaluated standalone.
+func (t *TagExpr) root() *TagExpr {
+	for t != nil && t.parent != nil {
+		t = t.parent
+	}
+	return t
+}
+
+// selectorOf returns the selector of the field in the root struct, such as 'Sub.Code' for 'Code' of the nested struct,
+// prefixed with the path of the root struct if it is ranged as the nested one.
+func (t *TagExpr) selectorOf(field string) string {
+	if t == nil {
+		return field
+	}
+	root := t.root()
+	if t != root {
+		for fs, sub := range root.sub {
+			if sub == t {
+				field = fs + FieldSeparator + field
+				break
+			}
+		}
+	}
+	if root.path != "" {
+		field = root.path + FieldSeparator + field
+	}
+	return field
+}
+
+func (t *TagExpr) getValue(fieldSelector string, subFields []interface{}) (v interface{}) {
+	if t.s == nil {
+		// the values of the map are converted as the struct fields below
+		v, _ = MapValue(t.data, fieldSelector)
+		if v == nil {
+			return nil
+		}
+	} else {
+		f, _ := t.s.lookupField(fieldSelector)
+		if f == nil {
+			return nil
+		}
+		if f.valueGetter == nil {
+			return nil
+		}
+		v = f.valueGetter(t.ptr)
+		if v == nil {
+			return nil
+		}
+		if len(subFields) == 0 {
+			return v
+		}
+	}
+	raw, ok := subValueOf(reflect.ValueOf(v), subFields)
+	if !ok {
 		return nil
 	}
-	if len(subFields) == 0 {
-		return v
+	vv := raw
+	for vv.Kind() == reflect.Ptr || vv.Kind() == reflect.Interface {
+		vv = vv.Elem()
 	}
-	vv := reflect.ValueOf(v)
+	return anyValueGetter(raw, vv)
+}
+
+// subValueOf returns the sub-field of the value selected by the subscripts,
+// such as the index of the slice, the key of the map and the name of the struct field.
+func subValueOf(vv reflect.Value, subFields []interface{}) (reflect.Value, bool) {
 	var kind reflect.Kind
 	for i, k := range subFields {
 		kind = vv.Kind()
@@ -924,44 +1469,72 @@ func (t *TagExpr) getValue(fieldSelector string, subFields []interface{}) (v int
 			if float, ok := k.(float64); ok {
 				idx := int(float)
 				if idx >= vv.Len() {
-					return nil
+					return vv, false
 				}
 				vv = vv.Index(idx)
 			} else {
-				return nil
+				return vv, false
 			}
 		case reflect.Map:
 			k := safeConvert(reflect.ValueOf(k), vv.Type().Key())
 			if !k.IsValid() {
-				return nil
+				return vv, false
 			}
 			vv = vv.MapIndex(k)
 		case reflect.Struct:
 			if float, ok := k.(float64); ok {
 				idx := int(float)
 				if idx < 0 || idx >= vv.NumField() {
-					return nil
+					return vv, false
 				}
 				vv = vv.Field(idx)
 			} else if str, ok := k.(string); ok {
 				vv = vv.FieldByName(str)
 			} else {
-				return nil
+				return vv, false
 			}
 		default:
 			if i < len(subFields)-1 {
-				return nil
+				return vv, false
 			}
 		}
 		if !vv.IsValid() {
-			return nil
+			return vv, false
 		}
 	}
-	raw := vv
-	for vv.Kind() == reflect.Ptr || vv.Kind() == reflect.Interface {
-		vv = vv.Elem()
+	return vv, true
+}
+
+// getNumber returns the exact number of the field value, if it is of number kind.
+func (t *TagExpr) getNumber(fieldSelector string) (number, bool) {
+	if t.s == nil {
+		v, _ := MapValue(t.data, fieldSelector)
+		return numberOf(reflect.ValueOf(v))
 	}
-	return anyValueGetter(raw, vv)
+	f, _ := t.s.lookupField(fieldSelector)
+	if f == nil || f.reflectValueGetter == nil {
+		return number{}, false
+	}
+	return numberOf(f.reflectValueGetter(t.ptr, false))
+}
+
+// getString returns the field value if it is of string kind,
+// which is read through the offset of the field without boxing it.
+func (t *TagExpr) getString(fieldSelector string) (string, bool) {
+	if t.s == nil {
+		v, _ := MapValue(t.data, fieldSelector)
+		s, ok := v.(string)
+		return s, ok
+	}
+	f, _ := t.s.lookupField(fieldSelector)
+	if f == nil || f.elemKind != reflect.String || f.valueGetter == nil {
+		return "", false
+	}
+	ptr := f.getElemPtr(t.ptr)
+	if ptr == nil {
+		return "", false
+	}
+	return *(*string)(ptr), true
 }
 
 func safeConvert(v reflect.Value, t reflect.Type) reflect.Value {
@@ -1046,6 +1619,33 @@ func anyValueGetter(raw, elem reflect.Value) interface{} {
 	return nil
 }
 
+// elemValue returns the evaluated value of the element of the slice, array or map,
+// e.g. float64 for the integer element, and nil for the nil pointer.
+func elemValue(v reflect.Value) interface{} {
+	raw := v
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	return anyValueGetter(raw, v)
+}
+
+// isNilValue returns whether the evaluated value is nil, including the nil map and chan.
+// NOTE:
+//  The nil slice is not nil, which keeps the previous result of $==nil for the slice field.
+func isNilValue(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Map, reflect.Chan:
+		return rv.IsNil()
+	}
+	return false
+}
+
 func safeIsNil(v reflect.Value) bool {
 	if !v.IsValid() {
 		return true
@@ -1059,7 +1659,7 @@ func safeIsNil(v reflect.Value) bool {
 }
 
 func ptrElem(ptr unsafe.Pointer) unsafe.Pointer {
-	return unsafe.Pointer(*(*uintptr)(ptr))
+	return *(*unsafe.Pointer)(ptr)
 }
 
 func derefType(t reflect.Type) reflect.Type {
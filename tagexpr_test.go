@@ -15,7 +15,10 @@
 package tagexpr
 
 import (
+	"fmt"
+	"math"
 	"reflect"
+	"sort"
 	"strconv"
 	"testing"
 	"time"
@@ -449,7 +452,7 @@ func TestOperator(t *testing.T) {
 	type Target struct {
 		A int             `tagexpr:"-$+$<=10"`
 		B int             `tagexpr:"+$-$<=10"`
-		C int             `tagexpr:"-$+(M)$*(N)$/$%(D.B)$[2]+$==1"`
+		C int             `tagexpr:"-$+(M)$*(N)$/$%(D.B)$[2]+$==1.5"`
 		D *Tmp1           `tagexpr:"(D.A)$!=nil"`
 		E string          `tagexpr:"((D.A)$=='1'&&len($)>1)||((D.A)$=='2'&&len($)>2)||((D.A)$=='3'&&len($)>3)"`
 		F map[string]int  `tagexpr:"x:len($);y:$['a']>10&&$['b']>1"`
@@ -828,3 +831,451 @@ func TestIssue3(t *testing.T) {
 	})
 	assert.NoError(t, err)
 }
+
+func TestIntegerComparison(t *testing.T) {
+	type T struct {
+		U64  uint64  `te:"$==18446744073709551615 && $>18446744073709551614 && $>-1 && $>=(I64)$"`
+		I64  int64   `te:"$==9223372036854775807 && $<9223372036854775808 && $>9223372036854775806 && $!=9223372036854775806"`
+		I8   int8    `te:"$<(I64)$ && $==(I16)$ && $<0.5 && $>-0.5"`
+		I16  int16   `te:"eqfield($,'I8') && ltfield($,'I64')"`
+		Neg  int32   `te:"$<(U8)$ && $<0 && !($>=(U64)$)"`
+		U8   uint8   `te:"$>(Neg)$ && $==255"`
+		F64  float64 `te:"$>(I64)$ && $<18446744073709551615"`
+		Near uint64  `te:"$!=9007199254740992 && $==9007199254740993 && $>9007199254740992"`
+	}
+	obj := &T{
+		U64:  math.MaxUint64,
+		I64:  math.MaxInt64,
+		Neg:  -1,
+		U8:   255,
+		F64:  1e19,
+		Near: 1<<53 + 1,
+	}
+	te := New("te").MustRun(obj)
+	for _, field := range []string{"U64", "I64", "I8", "I16", "Neg", "U8", "F64", "Near"} {
+		assert.True(t, te.EvalBool(field), field)
+	}
+}
+
+func TestLenAndNil(t *testing.T) {
+	type T struct {
+		M    map[string]int  `te:"len($)"`
+		MP   *map[string]int `te:"len($)"`
+		C    chan int        `te:"len($)"`
+		A    [3]int          `te:"len($)"`
+		MNil map[string]int  `te:"$==nil"`
+		CNil chan int        `te:"$==nil"`
+		SNil []int           `te:"$!=nil"`
+	}
+	vm := New("te")
+	te := vm.MustRun(&T{})
+	assert.Equal(t, float64(0), te.Eval("M"))
+	assert.Equal(t, nil, te.Eval("MP"))
+	assert.Equal(t, float64(0), te.Eval("C"))
+	assert.Equal(t, float64(3), te.Eval("A"))
+	assert.Equal(t, true, te.Eval("MNil"))
+	assert.Equal(t, true, te.Eval("CNil"))
+	// the nil slice is not nil, as before
+	assert.Equal(t, true, te.Eval("SNil"))
+
+	c := make(chan int, 2)
+	c <- 1
+	te = vm.MustRun(&T{
+		M:    map[string]int{"a": 1, "b": 2},
+		MP:   &map[string]int{},
+		C:    c,
+		MNil: map[string]int{},
+		CNil: c,
+		SNil: []int{},
+	})
+	assert.Equal(t, float64(2), te.Eval("M"))
+	assert.Equal(t, float64(0), te.Eval("MP"))
+	assert.Equal(t, float64(1), te.Eval("C"))
+	assert.Equal(t, false, te.Eval("MNil"))
+	assert.Equal(t, false, te.Eval("CNil"))
+	assert.Equal(t, true, te.Eval("SNil"))
+}
+
+func TestParentSelector(t *testing.T) {
+	type (
+		Street struct {
+			Name string `te:"(../../Country)$=='US' && (../City)$"`
+		}
+		Address struct {
+			City       string
+			PostalCode string `te:"(../Country)$!='US' || $!=''"`
+			Street     *Street
+		}
+		Base struct {
+			ID int `te:"(../Country)$"`
+		}
+		Order struct {
+			Base
+			Country string
+			Address Address
+			Items   []*Address
+		}
+	)
+	vm := New("te")
+	te := vm.MustRun(&Order{
+		Base:    Base{ID: 1},
+		Country: "US",
+		Address: Address{City: "NY", Street: &Street{}},
+		Items:   []*Address{{PostalCode: "10001"}, {}},
+	})
+	assert.Equal(t, false, te.Eval("Address.PostalCode"))
+	assert.Equal(t, true, te.Eval("Address.Street.Name"))
+	assert.Equal(t, "US", te.Eval("Base.ID"))
+	results := make(map[string]interface{})
+	te.Range(func(eh *ExprHandler) error {
+		results[eh.Path()] = eh.Eval()
+		return nil
+	})
+	assert.Equal(t, true, results["Items[0].PostalCode"])
+	assert.Equal(t, false, results["Items[1].PostalCode"])
+
+	// standalone
+	te = vm.MustRun(&Address{Street: &Street{}})
+	assert.Equal(t, true, te.Eval("PostalCode"))
+	assert.Equal(t, false, te.Eval("Street.Name"))
+	assert.Equal(t, nil, vm.MustRun(&Base{}).Eval("ID"))
+}
+
+func TestRootSelector(t *testing.T) {
+	type (
+		Street struct {
+			Name    string `te:"(/Country)$=='US' && (/Address.City)$=='NY'"`
+			City    string `te:"(/Address.City)$"`
+			Country string
+		}
+		Address struct {
+			City   string
+			Street *Street
+		}
+		Order struct {
+			Country string
+			Address Address
+			Items   []*Address
+			Self    string `te:"(/Country)$"`
+		}
+	)
+	vm := New("te")
+	te := vm.MustRun(&Order{
+		Country: "US",
+		Address: Address{City: "NY", Street: &Street{Country: "CN"}},
+		Items:   []*Address{{Street: &Street{}}},
+	})
+	assert.Equal(t, true, te.Eval("Address.Street.Name"))
+	assert.Equal(t, "NY", te.Eval("Address.Street.City"))
+	// the root is absent for the fields of the root struct itself
+	assert.Equal(t, nil, te.Eval("Self"))
+	results := make(map[string]interface{})
+	te.Range(func(eh *ExprHandler) error {
+		results[eh.Path()] = eh.Eval()
+		return nil
+	})
+	assert.Equal(t, true, results["Items[0].Street.Name"])
+
+	// standalone, the fields of the struct itself are not read
+	te = vm.MustRun(&Street{City: "NY", Country: "US"})
+	assert.Equal(t, false, te.Eval("Name"))
+	assert.Equal(t, nil, te.Eval("City"))
+	// the nested references are checked against the root
+	_, err := vm.Run(&Address{City: "NY", Street: &Street{}})
+	assert.EqualError(t, err, `tagexpr.Address.Street.Name: field selector "/Country" does not exist`)
+
+	type (
+		Item struct {
+			Name string `te:"(/Countyr)$=='US'"`
+		}
+		Cart struct {
+			Country string
+			Items   []Item
+		}
+	)
+	_, err = vm.Run(&Cart{})
+	assert.EqualError(t, err, `tagexpr.Cart.Items.Name: field selector "/Countyr" does not exist`)
+	// valid as the standalone struct
+	assert.Equal(t, false, vm.MustRun(&Item{}).Eval("Name"))
+}
+
+func TestEmbeddedSelector(t *testing.T) {
+	type (
+		Geo struct {
+			Lat float64
+		}
+		Base struct {
+			ID   int
+			Name string
+			Geo
+		}
+		Audit struct {
+			By string
+		}
+		Value struct {
+			Base
+			Name  string
+			Short bool `te:"(ID)$==1 && (Name)$=='value' && (Lat)$==2"`
+			Full  bool `te:"(Base.ID)$==1 && (Base.Name)$=='base' && (Base.Geo.Lat)$==2 && (Geo.Lat)$==2 && (Base.Lat)$==2"`
+		}
+		Pointer struct {
+			*Base
+			*Audit
+			Short interface{} `te:"(ID)$"`
+			Full  interface{} `te:"(Base.ID)$"`
+			Who   interface{} `te:"(By)$"`
+		}
+		Nested struct {
+			Value Value
+			Ptr   *Pointer
+			Short bool `te:"(Value.ID)$==1 && (Value.Lat)$==2 && (Ptr.ID)$==3"`
+		}
+	)
+	vm := New("te")
+	value := Value{Base: Base{ID: 1, Name: "base", Geo: Geo{Lat: 2}}, Name: "value"}
+	te := vm.MustRun(&value)
+	assert.Equal(t, true, te.Eval("Short"))
+	assert.Equal(t, true, te.Eval("Full"))
+
+	te = vm.MustRun(&Pointer{Base: &Base{ID: 3}, Audit: &Audit{By: "a"}})
+	assert.Equal(t, float64(3), te.Eval("Short"))
+	assert.Equal(t, float64(3), te.Eval("Full"))
+	assert.Equal(t, "a", te.Eval("Who"))
+	// the nil embedded pointer
+	te = vm.MustRun(&Pointer{})
+	assert.Equal(t, nil, te.Eval("Short"))
+	assert.Equal(t, nil, te.Eval("Full"))
+	assert.Equal(t, nil, te.Eval("Who"))
+
+	te = vm.MustRun(&Nested{Value: value, Ptr: &Pointer{Base: &Base{ID: 3}}})
+	assert.Equal(t, true, te.Eval("Short"))
+	assert.Equal(t, true, te.Eval("Value.Short"))
+
+	type (
+		A struct {
+			ID int
+		}
+		B struct {
+			ID int
+		}
+		Deep struct {
+			A
+		}
+		Ambiguous struct {
+			A
+			B
+			ID2 int `te:"(ID)$"`
+		}
+		Shallow struct {
+			Deep
+			B
+			ID2 int `te:"(ID)$"`
+		}
+		Unreferenced struct {
+			A
+			B
+		}
+	)
+	_, err := vm.Run(&Ambiguous{})
+	assert.EqualError(t, err, `tagexpr.Ambiguous.ID2: ambiguous field selector "ID": A.ID, B.ID`)
+	// the shallower field shadows the deeper one
+	te = vm.MustRun(&Shallow{Deep: Deep{A{ID: 1}}, B: B{ID: 2}})
+	assert.Equal(t, float64(2), te.Eval("ID2"))
+	_, err = vm.Run(&Unreferenced{})
+	assert.NoError(t, err)
+}
+
+func TestAncestorRefs(t *testing.T) {
+	type (
+		Street struct {
+			Name string `te:"(../../Country)$"`
+		}
+		Address struct {
+			Street Street
+		}
+		Order struct {
+			Address Address
+		}
+		Base struct {
+			ID int `te:"(../Region)$"`
+		}
+		Account struct {
+			Base
+			Country string
+		}
+	)
+	vm := New("te")
+	// the grandparent is unknown when the address is registered
+	_, err := vm.Run(&Address{})
+	assert.NoError(t, err)
+	_, err = vm.Run(&Order{})
+	assert.EqualError(t, err, `tagexpr.Order.Address.Street.Name: field selector "../../Country" does not exist`)
+	_, err = vm.Run(&Account{})
+	assert.EqualError(t, err, `tagexpr.Account.Base.ID: field selector "../Region" does not exist`)
+}
+
+func TestStringValue(t *testing.T) {
+	type Nested struct {
+		B string `te:"$"`
+	}
+	type T struct {
+		A string  `te:"$"`
+		N Nested  `te:"(N.B)$"`
+		P *string `te:"$"`
+	}
+	vm := New("te")
+	p := "p"
+	obj := &T{A: "a", N: Nested{B: "b"}, P: &p}
+	te := vm.MustRun(obj)
+	a := te.Eval("A")
+	assert.Equal(t, "a", a)
+	assert.Equal(t, "b", te.Eval("N"))
+	assert.Equal(t, "b", te.Eval("N.B"))
+	assert.Equal(t, "p", te.Eval("P"))
+	// the evaluated value is not changed with the field
+	obj.A = "x"
+	assert.Equal(t, "x", te.Eval("A"))
+	assert.Equal(t, "a", a)
+	obj.A = "a"
+	assert.Equal(t, "a", te.Eval("A"))
+	obj.A = ""
+	assert.Equal(t, "", te.Eval("A"))
+	assert.Equal(t, "a", a)
+}
+
+func TestStringOperands(t *testing.T) {
+	type Name string
+	type T struct {
+		A string  `te:"$=='a'; gt:$>'A'; len:len($); re:regexp('^a$'); dyn:regexp((B)$,$)"`
+		B string  `te:"$==(A)$; eq:eqfield($,'A')"`
+		N Name    `te:"$=='n'; len:len($)"`
+		P *string `te:"$=='p'; len:len($); re:regexp('^p$')"`
+		Q *string `te:"$==nil; len:len($); re:regexp('^q$')"`
+		u string  `te:"$=='u'"`
+	}
+	p := "p"
+	obj := &T{A: "a", B: "^a$", N: "n", P: &p, u: "u"}
+	te := New("te").MustRun(obj)
+	assert.Equal(t, true, te.Eval("A"))
+	assert.Equal(t, true, te.Eval("A@gt"))
+	assert.Equal(t, 1.0, te.Eval("A@len"))
+	assert.Equal(t, true, te.Eval("A@re"))
+	assert.Equal(t, true, te.Eval("A@dyn"))
+	assert.Equal(t, false, te.Eval("B"))
+	assert.Equal(t, false, te.Eval("B@eq"))
+	assert.Equal(t, true, te.Eval("N"))
+	assert.Equal(t, 1.0, te.Eval("N@len"))
+	assert.Equal(t, true, te.Eval("P"))
+	assert.Equal(t, 1.0, te.Eval("P@len"))
+	assert.Equal(t, true, te.Eval("P@re"))
+	assert.Equal(t, true, te.Eval("Q"))
+	assert.Equal(t, nil, te.Eval("Q@len"))
+	assert.Equal(t, false, te.Eval("Q@re"))
+	assert.Equal(t, true, te.Eval("u"))
+	// the strings are read again after the fields are changed
+	obj.A, obj.B, p = "b", "b", "q"
+	assert.Equal(t, false, te.Eval("A"))
+	assert.Equal(t, true, te.Eval("B"))
+	assert.Equal(t, true, te.Eval("B@eq"))
+	assert.Equal(t, false, te.Eval("P"))
+}
+
+func TestRelease(t *testing.T) {
+	type Sub struct {
+		C string `te:"$=='c'"`
+	}
+	type T struct {
+		A int `te:"$>0"`
+		S Sub
+	}
+	vm := New("te")
+	eval := func(obj *T) map[string]interface{} {
+		r := make(map[string]interface{})
+		var te *TagExpr
+		err := vm.RunAny(obj, func(x *TagExpr, err error) error {
+			te = x
+			return err
+		})
+		assert.NoError(t, err)
+		err = te.Range(func(eh *ExprHandler) error {
+			r[eh.Path()] = eh.Eval()
+			return nil
+		})
+		assert.NoError(t, err)
+		te.Release()
+		return r
+	}
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, map[string]interface{}{"A": true, "S.C": true}, eval(&T{A: 1, S: Sub{C: "c"}}))
+		assert.Equal(t, map[string]interface{}{"A": false, "S.C": false}, eval(&T{S: Sub{C: "x"}}))
+	}
+	// the released TagExpr is reused with the new path
+	var paths []string
+	for i := 0; i < 2; i++ {
+		err := vm.RunAny([]*T{{A: 1}, {A: 2}}, func(te *TagExpr, err error) error {
+			if err != nil {
+				return err
+			}
+			defer te.Release()
+			return te.Range(func(eh *ExprHandler) error {
+				paths = append(paths, eh.Path())
+				return nil
+			})
+		})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []string{"[1].A", "[1].S.C", "[0].A", "[0].S.C", "[1].A", "[1].S.C", "[0].A", "[0].S.C"}, paths)
+	var nilTagExpr *TagExpr
+	nilTagExpr.Release()
+}
+
+func TestElemExprCache(t *testing.T) {
+	type T struct {
+		Pred string
+		A    []int `te:"all($, (Pred)$)"`
+	}
+	obj := &T{Pred: "#v>0 && #v<100", A: []int{1, 2}}
+	te := New("te").MustRun(obj)
+	assert.Equal(t, true, te.Eval("A"))
+	sub, ok := elemExprCache.get(obj.Pred)
+	assert.True(t, ok)
+	// the dynamic sub-expression is parsed once
+	obj.A = []int{0}
+	assert.Equal(t, false, te.Eval("A"))
+	cached, _ := compileElemExpr(obj.Pred)
+	assert.True(t, sub == cached)
+
+	obj.Pred = "#v>@"
+	_, ok = te.Eval("A").(*EvalFault)
+	assert.True(t, ok)
+	_, err := compileElemExpr(obj.Pred)
+	v, _ := elemExprCache.get(obj.Pred)
+	assert.True(t, err != nil && v == err)
+}
+
+func TestExprNameWithSeparator(t *testing.T) {
+	type T struct {
+		A string `te:"create:len($)>0; create@msg:sprintf('invalid %v',$)"`
+		B struct {
+			C int `te:"@max:$<10; @max@msg:sprintf('%v is too large',$)"`
+		}
+	}
+	obj := &T{A: "a"}
+	obj.B.C = 12
+	te := New("te").MustRun(obj)
+	assert.Equal(t, true, te.Eval("A@create"))
+	assert.Equal(t, "invalid a", te.Eval("A@create@msg"))
+	assert.Equal(t, false, te.Eval("B.C@@max"))
+	assert.Equal(t, "12 is too large", te.Eval("B.C@@max@msg"))
+	var selectors []string
+	te.RangeSelected([]string{"B"}, func(eh *ExprHandler) error {
+		selectors = append(selectors, eh.StringSelector()+"="+fmt.Sprint(eh.Eval()))
+		return nil
+	})
+	sort.Strings(selectors)
+	assert.Equal(t, []string{"B.C@@max=false", "B.C@@max@msg=12 is too large"}, selectors)
+	// the selector is split by the last separator
+	field, name := ExprSelector("A@create@msg").Split()
+	assert.Equal(t, FieldSelector("A@create"), field)
+	assert.Equal(t, "msg", name)
+}
@@ -14,6 +14,9 @@ type namedTagExpr struct {
 const (
 	tagOmit    = "-"
 	tagOmitNil = "?"
+	// exprOmitEmpty the prefix of the expression which is skipped by the validator
+	// when the field holds the zero value, such as `vd:"?email($)"`
+	exprOmitEmpty = "?"
 )
 
 func (f *fieldVM) parseExprs(tag string) error {
@@ -23,25 +26,49 @@ func (f *fieldVM) parseExprs(tag string) error {
 		return nil
 	}
 
-	kvs, err := parseTag(tag)
-	if err != nil {
-		return err
-	}
 	exprSelectorPrefix := f.structField.Name
+	var kvs map[string]string
+	var err error
+	if parser := f.origin.vm.tagParser; parser != nil {
+		if tag == "" {
+			return nil
+		}
+		kvs, err = parser(f.structField, tag)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %s", f.origin.name, exprSelectorPrefix, err.Error())
+		}
+	} else {
+		kvs, err = parseTag(tag)
+		if err != nil {
+			return err
+		}
+	}
 
 	for exprSelector, exprString := range kvs {
+		exprString = strings.TrimSpace(exprString)
+		omitEmpty := strings.HasPrefix(exprString, exprOmitEmpty)
+		if omitEmpty {
+			exprString = exprString[len(exprOmitEmpty):]
+		}
 		expr, err := parseExpr(exprString)
 		if err != nil {
 			return err
 		}
+		expr.omitEmpty = omitEmpty
 		if exprSelector == ExprNameSeparator {
 			exprSelector = exprSelectorPrefix
 		} else {
+			if check := f.origin.vm.exprNameChecker; check != nil {
+				if err = check(exprSelector); err != nil {
+					return fmt.Errorf("%s.%s: %s", f.origin.name, exprSelectorPrefix, err.Error())
+				}
+			}
 			exprSelector = exprSelectorPrefix + ExprNameSeparator + exprSelector
 		}
 		f.exprs[exprSelector] = expr
 		f.origin.exprs[exprSelector] = expr
 		f.origin.exprSelectorList = append(f.origin.exprSelectorList, exprSelector)
+		f.origin.exprFields[exprSelector] = exprSelectorPrefix
 	}
 	return nil
 }
@@ -0,0 +1,182 @@
+package validator
+
+import (
+	"strconv"
+	"strings"
+
+	tagexpr "github.com/bytedance/go-tagexpr"
+)
+
+// cardNetwork the length and prefix ranges of the card numbers of a network
+type cardNetwork struct {
+	minLen, maxLen int
+	// prefixes the inclusive ranges of the leading digits, such as {51, 55}
+	prefixes [][2]int
+}
+
+var cardNetworks = map[string]cardNetwork{
+	"visa":       {13, 19, [][2]int{{4, 4}}},
+	"mastercard": {16, 16, [][2]int{{51, 55}, {2221, 2720}}},
+	"amex":       {15, 15, [][2]int{{34, 34}, {37, 37}}},
+	"discover":   {16, 19, [][2]int{{6011, 6011}, {644, 649}, {65, 65}, {622126, 622925}}},
+	"jcb":        {16, 19, [][2]int{{3528, 3589}}},
+	"diners":     {14, 19, [][2]int{{300, 305}, {36, 36}, {38, 39}}},
+	"unionpay":   {16, 19, [][2]int{{62, 62}}},
+}
+
+// cardError the reason of the card number which fails the validation,
+// whose message does not contain the number.
+type cardError struct {
+	// raw the number as passed, digits the number without the spaces and hyphens
+	raw, digits string
+	masked      string
+}
+
+func newCardError(raw, digits string) *cardError {
+	return &cardError{raw: raw, digits: digits, masked: maskCardNumber(digits)}
+}
+
+// Error implements error interface.
+func (e *cardError) Error() string {
+	return "invalid card number"
+}
+
+// maskedValue returns the number masked except the last four digits.
+func (e *cardError) maskedValue() string {
+	return e.masked
+}
+
+// mask replaces the number in the message with the masked one,
+// such as the message formatted with the field value.
+func (e *cardError) mask(msg string) string {
+	if e.raw != "" {
+		msg = strings.ReplaceAll(msg, e.raw, e.masked)
+	}
+	if e.digits != "" {
+		msg = strings.ReplaceAll(msg, e.digits, e.masked)
+	}
+	return msg
+}
+
+func init() {
+	err := tagexpr.RegFunc("luhn", func(args ...interface{}) interface{} {
+		if len(args) != 1 {
+			return false
+		}
+		digits, ok := cardDigits(args[0])
+		if !ok {
+			return false
+		}
+		if !luhnValid(digits) {
+			return newCardError(args[0].(string), digits)
+		}
+		return true
+	}, true)
+	if err != nil {
+		panic(err)
+	}
+	err = tagexpr.RegFunc("creditcard", func(args ...interface{}) interface{} {
+		var network string
+		switch len(args) {
+		case 2:
+			s, ok := args[1].(string)
+			if !ok {
+				return false
+			}
+			if _, ok = cardNetworks[strings.ToLower(s)]; !ok {
+				return false
+			}
+			network = strings.ToLower(s)
+		case 1:
+		default:
+			return false
+		}
+		digits, ok := cardDigits(args[0])
+		if !ok {
+			return false
+		}
+		if !luhnValid(digits) || !matchCardNetwork(digits, network) {
+			return newCardError(args[0].(string), digits)
+		}
+		return true
+	}, true)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// cardDigits returns the digits of the card number, ignoring the spaces and hyphens.
+func cardDigits(v interface{}) (string, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return "", false
+	}
+	digits := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= '0' && c <= '9':
+			digits = append(digits, c)
+		case c == ' ' || c == '-':
+		default:
+			return "", false
+		}
+	}
+	if len(digits) < 2 {
+		return "", false
+	}
+	return string(digits), true
+}
+
+// luhnValid returns whether the digits pass the Luhn checksum.
+func luhnValid(digits string) bool {
+	var sum int
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// matchCardNetwork returns whether the length and prefix of the digits match the network,
+// or one of the networks if network=="".
+func matchCardNetwork(digits, network string) bool {
+	if network != "" {
+		return cardNetworks[network].match(digits)
+	}
+	for _, n := range cardNetworks {
+		if n.match(digits) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n cardNetwork) match(digits string) bool {
+	if len(digits) < n.minLen || len(digits) > n.maxLen {
+		return false
+	}
+	for _, r := range n.prefixes {
+		size := len(strconv.Itoa(r[0]))
+		prefix, _ := strconv.Atoi(digits[:size])
+		if prefix >= r[0] && prefix <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// maskCardNumber masks the digits except the last four.
+func maskCardNumber(digits string) string {
+	if len(digits) <= 4 {
+		return strings.Repeat("*", len(digits))
+	}
+	return strings.Repeat("*", len(digits)-4) + digits[len(digits)-4:]
+}
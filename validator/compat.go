@@ -0,0 +1,189 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// compatUUIDPattern the pattern of the 'uuid' directive
+const compatUUIDPattern = "^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$"
+
+// NewCompat creates a struct fields validator that uses tagName as the tag name,
+// whose tag value is written in the subset of the go-playground/validator syntax.
+// NOTE:
+//  The supported directives are: omitempty, required, min, max, len, oneof, email and uuid;
+//  The directives are separated by ',' and are all required to pass, e.g. `validate:"required,min=1"`;
+//  The unknown directive returns error with the field named when the struct type is registered.
+func NewCompat(tagName string) *Validator {
+	v := New(tagName)
+	v.vm.SetTagParser(parseCompatTag)
+	return v
+}
+
+// SetTagParser sets the parser which translates the tag value
+// to the expressions keyed by the expression names, e.g. for a foreign tag syntax.
+// NOTE:
+//  The default expression is keyed by '@', and the message by 'msg';
+//  The error of the parser is returned with the field named when the struct type is registered;
+//  It should be set before the struct type is registered.
+func (v *Validator) SetTagParser(fn func(tagValue string) (exprs map[string]string, err error)) *Validator {
+	if fn == nil {
+		v.vm.SetTagParser(nil)
+		return v
+	}
+	v.vm.SetTagParser(func(_ reflect.StructField, tagValue string) (map[string]string, error) {
+		return fn(tagValue)
+	})
+	return v
+}
+
+// parseCompatTag translates the go-playground/validator tag to the default expression.
+func parseCompatTag(field reflect.StructField, tagValue string) (map[string]string, error) {
+	t := field.Type
+	isPtr := t.Kind() == reflect.Ptr
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var omitempty bool
+	var conds []string
+	for _, directive := range strings.Split(tagValue, ",") {
+		name, param := directive, ""
+		if i := strings.IndexByte(directive, '='); i >= 0 {
+			name, param = directive[:i], directive[i+1:]
+		}
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if name == "omitempty" {
+			omitempty = true
+			continue
+		}
+		cond, err := compatCond(name, param, t, isPtr)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, cond)
+	}
+	if len(conds) == 0 {
+		return nil, nil
+	}
+	expr := strings.Join(conds, " && ")
+	if omitempty {
+		empty, err := compatEmpty(t, isPtr)
+		if err != nil {
+			return nil, err
+		}
+		expr = empty + " || (" + expr + ")"
+	}
+	return map[string]string{MatchExprName: expr}, nil
+}
+
+// compatEmpty returns the expression which is true if the field has the zero value.
+func compatEmpty(t reflect.Type, isPtr bool) (string, error) {
+	if isPtr {
+		return "$==nil", nil
+	}
+	switch kind := t.Kind(); {
+	case kind == reflect.String:
+		return "$==''", nil
+	case kind == reflect.Bool:
+		return "!$", nil
+	case isNumberKind(kind):
+		return "$==0", nil
+	case kind == reflect.Slice, kind == reflect.Map, kind == reflect.Array:
+		return "len($)==0", nil
+	}
+	return "", fmt.Errorf("validate directive %q is not supported for the type %s", "omitempty", t)
+}
+
+// compatCond returns the expression of the directive.
+func compatCond(name, param string, t reflect.Type, isPtr bool) (string, error) {
+	kind := t.Kind()
+	isNumber := isNumberKind(kind)
+	isLen := kind == reflect.String || kind == reflect.Slice || kind == reflect.Map || kind == reflect.Array
+	var lenFunc = "len"
+	if kind == reflect.String {
+		// go-playground/validator counts the runes of the string
+		lenFunc = "mblen"
+	}
+	switch name {
+	case "required":
+		if param != "" {
+			break
+		}
+		if isPtr {
+			return "$!=nil", nil
+		}
+		switch {
+		case kind == reflect.String:
+			return "$!=''", nil
+		case kind == reflect.Bool:
+			return "$", nil
+		case isNumber:
+			return "$!=0", nil
+		case isLen:
+			return "len($)>0", nil
+		}
+	case "min", "max", "len":
+		if _, err := strconv.ParseFloat(param, 64); err != nil {
+			return "", fmt.Errorf("validate directive %q: invalid parameter %q", name, param)
+		}
+		op := map[string]string{"min": ">=", "max": "<=", "len": "=="}[name]
+		switch {
+		case isNumber:
+			return "$" + op + param, nil
+		case isLen:
+			return lenFunc + "($)" + op + param, nil
+		}
+	case "oneof":
+		values := strings.Fields(param)
+		if len(values) == 0 {
+			return "", fmt.Errorf("validate directive %q: parameter is required", name)
+		}
+		a := make([]string, len(values))
+		for i, value := range values {
+			switch {
+			case kind == reflect.String:
+				if strings.ContainsAny(value, `'\`) {
+					return "", fmt.Errorf("validate directive %q: invalid parameter %q", name, value)
+				}
+				a[i] = "$=='" + value + "'"
+			case isNumber:
+				if _, err := strconv.ParseFloat(value, 64); err != nil {
+					return "", fmt.Errorf("validate directive %q: invalid parameter %q", name, value)
+				}
+				a[i] = "$==" + value
+			default:
+				return "", fmt.Errorf("validate directive %q is not supported for the type %s", name, t)
+			}
+		}
+		return "(" + strings.Join(a, " || ") + ")", nil
+	case "email":
+		if kind == reflect.String && param == "" {
+			return "email($)", nil
+		}
+	case "uuid":
+		if kind == reflect.String && param == "" {
+			return "regexp('" + compatUUIDPattern + "')", nil
+		}
+	default:
+		return "", fmt.Errorf("unknown validate directive %q", name)
+	}
+	if param != "" {
+		name += "=" + param
+	}
+	return "", fmt.Errorf("validate directive %q is not supported for the type %s", name, t)
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package validator
+
+import (
+	"math/big"
+	"strconv"
+)
+
+func init() {
+	for name, match := range map[string]func(cmp int) bool{
+		"decimalgt": func(cmp int) bool { return cmp > 0 },
+		"decimalge": func(cmp int) bool { return cmp >= 0 },
+		"decimallt": func(cmp int) bool { return cmp < 0 },
+		"decimalle": func(cmp int) bool { return cmp <= 0 },
+		"decimaleq": func(cmp int) bool { return cmp == 0 },
+	} {
+		match := match
+		MustRegFunc(name, func(args ...interface{}) bool {
+			if len(args) != 2 {
+				return false
+			}
+			x, ok1 := decimalOf(args[0])
+			y, ok2 := decimalOf(args[1])
+			return ok1 && ok2 && match(x.Cmp(y))
+		}, true)
+	}
+	MustRegFunc("decimalrange", func(args ...interface{}) bool {
+		if len(args) != 3 {
+			return false
+		}
+		x, ok1 := decimalOf(args[0])
+		min, ok2 := decimalOf(args[1])
+		max, ok3 := decimalOf(args[2])
+		return ok1 && ok2 && ok3 && x.Cmp(min) >= 0 && x.Cmp(max) <= 0
+	}, true)
+}
+
+// decimalOf returns the exact value of the decimal string, such as '-0019.990',
+// the number, or the big.Int, big.Float and big.Rat value or pointer.
+// NOTE:
+//  The string in the exponent or fraction form, such as '1e3' and '1/3', is not a decimal.
+func decimalOf(v interface{}) (*big.Rat, bool) {
+	switch x := v.(type) {
+	case string:
+		if !isDecimal(x) {
+			return nil, false
+		}
+		return new(big.Rat).SetString(x)
+	case float64:
+		return new(big.Rat).SetString(strconv.FormatFloat(x, 'f', -1, 64))
+	case *big.Int:
+		if x == nil {
+			return nil, false
+		}
+		return new(big.Rat).SetInt(x), true
+	case big.Int:
+		return new(big.Rat).SetInt(&x), true
+	case *big.Float:
+		if x == nil || x.IsInf() {
+			return nil, false
+		}
+		r, _ := x.Rat(nil)
+		return r, true
+	case big.Float:
+		if x.IsInf() {
+			return nil, false
+		}
+		r, _ := x.Rat(nil)
+		return r, true
+	case *big.Rat:
+		if x == nil {
+			return nil, false
+		}
+		return x, true
+	case big.Rat:
+		return &x, true
+	}
+	return nil, false
+}
+
+// isDecimal returns whether the string is a signed decimal number, such as '+1', '-0.5' or '19.990'.
+func isDecimal(s string) bool {
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		s = s[1:]
+	}
+	var digits, dot bool
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= '0' && c <= '9':
+			digits = true
+		case c == '.' && !dot:
+			dot = true
+		default:
+			return false
+		}
+	}
+	// at least one digit before or after the dot, such as '1.' or '.5'
+	return digits
+}
@@ -1,6 +1,8 @@
 package validator
 
-var defaultValidator = New("vd").SetErrorFactory(defaultErrorFactory)
+import "context"
+
+var defaultValidator = New("vd")
 
 // Default returns the default validator.
 // NOTE:
@@ -17,9 +19,91 @@ func Validate(value interface{}, checkAll ...bool) error {
 	return defaultValidator.Validate(value, checkAll...)
 }
 
+// ValidateWithWarnings uses the default validator to validate whether the fields of value is valid,
+// and returns the failures of the warning-level expressions separately.
+// NOTE:
+//  The tag name is 'vd'
+//  If checkAll=true, validate all the error.
+func ValidateWithWarnings(value interface{}, checkAll ...bool) (warnings []error, err error) {
+	return defaultValidator.ValidateWithWarnings(value, checkAll...)
+}
+
+// ValidateContext uses the default validator to validate whether the fields of value is valid with the context.
+// NOTE:
+//  The tag name is 'vd'
+//  If checkAll=true, validate all the error.
+func ValidateContext(ctx context.Context, value interface{}, checkAll ...bool) error {
+	return defaultValidator.ValidateContext(ctx, value, checkAll...)
+}
+
+// ValidateFields uses the default validator to validate only the fields specified by the selectors.
+// NOTE:
+//  The tag name is 'vd'
+//  The selector is in the dotted form, such as 'A' or 'A.B'.
+func ValidateFields(value interface{}, selectors ...string) error {
+	return defaultValidator.ValidateFields(value, selectors...)
+}
+
+// ValidateField uses the default validator to re-validate only the field specified by the selector.
+// NOTE:
+//  The tag name is 'vd'
+//  The selector is in the dotted form, such as 'A' or 'A.B'.
+func ValidateField(structPtr interface{}, fieldSelector string) error {
+	return defaultValidator.ValidateField(structPtr, fieldSelector)
+}
+
+// ValidateMap uses the default validator to validate the map against the rules,
+// whose keys are the dotted paths of the map entries and values are the expressions.
+// NOTE:
+//  If checkAll=true, validate all the error.
+func ValidateMap(m map[string]interface{}, rules map[string]string, checkAll ...bool) error {
+	return defaultValidator.ValidateMap(m, rules, checkAll...)
+}
+
+// ValidateGroup uses the default validator to validate the expressions of the group and the ungrouped expressions.
+// NOTE:
+//  The tag name is 'vd'
+//  If checkAll=true, validate all the error.
+func ValidateGroup(value interface{}, group string, checkAll ...bool) error {
+	return defaultValidator.ValidateGroup(value, group, checkAll...)
+}
+
+// AllowUnexported if set to false, the struct type with the expressions on the unexported fields
+// returns error from the default validator.
+// NOTE:
+//  The tag name is 'vd'
+//  The default is true, the unexported fields with the expressions are validated.
+func AllowUnexported(allow bool) {
+	defaultValidator.AllowUnexported(allow)
+}
+
+// SetNilSkip sets the default policy of validating the nil pointer fields for the default validator.
+// NOTE:
+//  The tag name is 'vd'
+//  If skip=true, the nil pointer fields are skipped, otherwise they fail with the required error.
+func SetNilSkip(skip bool) {
+	defaultValidator.SetNilSkip(skip)
+}
+
 // SetErrorFactory customizes the factory of validation error for the default validator.
 // NOTE:
 //  The tag name is 'vd'
 func SetErrorFactory(errFactory func(fieldSelector, msg string) error) {
 	defaultValidator.SetErrorFactory(errFactory)
 }
+
+// SetCodeErrorFactory customizes the factory of validation error for the default validator,
+// which receives the error code.
+// NOTE:
+//  The tag name is 'vd'
+func SetCodeErrorFactory(errFactory func(failPath, msg, code string) error) {
+	defaultValidator.SetCodeErrorFactory(errFactory)
+}
+
+// SetMsgCatalog sets the catalog used to look up the message that references a key for the default validator.
+// NOTE:
+//  The tag name is 'vd'
+//  The whole message in the form '@{key}' references the key of the catalog, such as `msg:'@{user.name.too_long}'`.
+func SetMsgCatalog(catalog MsgCatalog) {
+	defaultValidator.SetMsgCatalog(catalog)
+}
@@ -0,0 +1,179 @@
+package validator
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// errorsJSON the JSON object of the errors in the API response,
+// such as {"errors":[{"field":"user.email","code":"USER_EMAIL_INVALID","message":"invalid email"}]}.
+type errorsJSON struct {
+	Errors []errorEntry `json:"errors"`
+}
+
+// errorEntry the JSON object of an error.
+type errorEntry struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Rule the name of the failed named rule
+	Rule string `json:"rule,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler,
+// the error is the only entry of the API response shape, see MarshalErrors.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(errorsJSON{Errors: []errorEntry{entryOf(e)}})
+}
+
+// MarshalJSON implements json.Marshaler, the errors are the entries of the API response shape,
+// see MarshalErrors.
+func (e Errors) MarshalJSON() ([]byte, error) {
+	entries := make([]errorEntry, len(e))
+	for i, err := range e {
+		entries[i] = entryOf(err)
+	}
+	return json.Marshal(errorsJSON{Errors: entries})
+}
+
+// MarshalErrors returns the JSON of the error in the API response shape:
+// {"errors":[{"field":"user.email","code":"USER_EMAIL_INVALID","message":"invalid email"}]}.
+// NOTE:
+//  The error is *Error or Errors returned by the validator, and the field is its FailPath;
+//  The other error is marshaled as a single entry with the empty field and code;
+//  If err==nil, the errors are empty.
+func MarshalErrors(err error) ([]byte, error) {
+	switch e := err.(type) {
+	case nil:
+		return json.Marshal(errorsJSON{Errors: []errorEntry{}})
+	case Errors:
+		return e.MarshalJSON()
+	}
+	return json.Marshal(errorsJSON{Errors: []errorEntry{entryOf(err)}})
+}
+
+// ErrorsToMap returns the messages of the errors keyed by the fields, e.g. for the form errors.
+// NOTE:
+//  The error is *Error or Errors returned by the validator, and the field is its FailPath;
+//  The message of the first error of the field is kept;
+//  The other error is keyed by the empty field;
+//  If err==nil, return nil.
+func ErrorsToMap(err error) map[string]string {
+	if err == nil {
+		return nil
+	}
+	errs, ok := err.(Errors)
+	if !ok {
+		errs = Errors{err}
+	}
+	m := make(map[string]string, len(errs))
+	for _, e := range errs {
+		entry := entryOf(e)
+		if _, ok := m[entry.Field]; !ok {
+			m[entry.Field] = entry.Message
+		}
+	}
+	return m
+}
+
+func entryOf(err error) errorEntry {
+	if e, ok := err.(*Error); ok {
+		return errorEntry{Field: e.FailPath, Code: e.Code, Message: e.Error(), Rule: e.Rule}
+	}
+	return errorEntry{Message: err.Error()}
+}
+
+// renamePath returns the path whose fields are named by the tag, such as 'user.emails[0]' for 'User.Emails[0]'.
+// NOTE:
+//  The indexes and the map keys, such as '[0]' and '{K:a}', are kept.
+func renamePath(t reflect.Type, path, tag string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); {
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		switch path[i] {
+		case '.':
+			i++
+		case '[', '{':
+			// the index of the slice, or the key or value of the map
+			end := closingIndex(path, i)
+			seg := path[i:end]
+			b.WriteString(seg)
+			if t != nil {
+				switch {
+				case t.Kind() == reflect.Map && seg == "{}":
+					t = t.Key()
+				case t.Kind() == reflect.Map, t.Kind() == reflect.Slice, t.Kind() == reflect.Array:
+					t = t.Elem()
+				default:
+					t = nil
+				}
+			}
+			i = end
+		default:
+			end := i
+			for end < len(path) && path[end] != '.' && path[end] != '[' && path[end] != '{' {
+				end++
+			}
+			name := path[i:end]
+			var promoted bool
+			if t != nil && t.Kind() == reflect.Struct {
+				if f, ok := t.FieldByName(name); ok {
+					name, promoted = tagFieldName(f, tag)
+					t = f.Type
+				} else {
+					t = nil
+				}
+			} else {
+				t = nil
+			}
+			if !promoted {
+				if b.Len() > 0 {
+					b.WriteByte('.')
+				}
+				b.WriteString(name)
+			}
+			i = end
+		}
+	}
+	return b.String()
+}
+
+// tagFieldName returns the name of the field by the tag,
+// and whether the field is the untagged embedded struct whose fields are promoted.
+func tagFieldName(f reflect.StructField, tag string) (string, bool) {
+	v, ok := f.Tag.Lookup(tag)
+	if !ok {
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		return f.Name, f.Anonymous && ft.Kind() == reflect.Struct
+	}
+	if i := strings.IndexByte(v, ','); i >= 0 {
+		v = v[:i]
+	}
+	if v == "" || v == "-" {
+		return f.Name, false
+	}
+	return v, false
+}
+
+// closingIndex returns the index after the bracket closing the one at i.
+func closingIndex(path string, i int) int {
+	var depth int
+	for j := i; j < len(path); j++ {
+		switch path[j] {
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+			if depth == 0 {
+				return j + 1
+			}
+		}
+	}
+	return len(path)
+}
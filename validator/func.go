@@ -1,10 +1,10 @@
 package validator
 
 import (
+	"context"
 	"regexp"
 
 	tagexpr "github.com/bytedance/go-tagexpr"
-	"github.com/nyaruka/phonenumbers"
 )
 
 // MustRegFunc registers validator function expression.
@@ -33,19 +33,83 @@ func RegFunc(funcName string, fn func(args ...interface{}) bool, force ...bool)
 	}, force...)
 }
 
+// MustRegStringFunc registers validator function expression of a string argument.
+// NOTE:
+//  panic if exist error.
+func MustRegStringFunc(funcName string, fn func(s string) bool, force ...bool) {
+	err := RegStringFunc(funcName, fn, force...)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// RegStringFunc registers validator function expression of a string argument, e.g. email($),
+// which reads the string field without boxing it.
+// NOTE:
+//  If the argument is not a string or the number of arguments is not 1, the validation fails;
+//  If @force=true, allow to cover the existed same @funcName.
+func RegStringFunc(funcName string, fn func(s string) bool, force ...bool) error {
+	return tagexpr.RegStringFunc(funcName, func(s string) interface{} {
+		return fn(s)
+	}, force...)
+}
+
+// MustRegCtxFunc registers validator function expression which receives the context.
+// NOTE:
+//  panic if exist error.
+func MustRegCtxFunc(funcName string, fn func(ctx context.Context, args ...interface{}) (bool, error), force ...bool) {
+	err := RegCtxFunc(funcName, fn, force...)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// RegCtxFunc registers validator function expression which receives the context.
+// NOTE:
+//  The context is passed by ValidateContext, the default is context.Background();
+//  If the function returns error, the validation fails with the error message,
+//  or is aborted if the context is done;
+//  If @force=true, allow to cover the existed same @funcName.
+func RegCtxFunc(funcName string, fn func(ctx context.Context, args ...interface{}) (bool, error), force ...bool) error {
+	return tagexpr.RegCtxFunc(funcName, func(ctx context.Context, args ...interface{}) interface{} {
+		ok, err := fn(ctx, args...)
+		if err != nil {
+			return err
+		}
+		return ok
+	}, force...)
+}
+
+// MustRegErrFunc registers validator function expression which returns an error.
+// NOTE:
+//  panic if exist error.
+func MustRegErrFunc(funcName string, fn func(args ...interface{}) (bool, error), force ...bool) {
+	err := RegErrFunc(funcName, fn, force...)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// RegErrFunc registers validator function expression which returns an error,
+// e.g. the failed lookup, which is an internal error instead of the failed validation.
+// NOTE:
+//  If the function returns error, the validation is aborted and *FuncError with the field path is returned;
+//  Fix the number of arguments by tagexpr.SetFuncArity to check the expressions when the struct type is registered;
+//  If @force=true, allow to cover the existed same @funcName.
+func RegErrFunc(funcName string, fn func(args ...interface{}) (bool, error), force ...bool) error {
+	return tagexpr.RegErrFunc(funcName, func(args ...interface{}) (interface{}, error) {
+		ok, err := fn(args...)
+		if err != nil {
+			return nil, err
+		}
+		return ok, nil
+	}, force...)
+}
+
 func init() {
 	var pattern = "^([A-Za-z0-9_\\-\\.\u4e00-\u9fa5])+\\@([A-Za-z0-9_\\-\\.])+\\.([A-Za-z]{2,8})$"
 	emailRegexp := regexp.MustCompile(pattern)
-	MustRegFunc("email", func(args ...interface{}) bool {
-		if len(args) != 1 {
-			return false
-		}
-		s, ok := args[0].(string)
-		if !ok {
-			return false
-		}
-		return emailRegexp.MatchString(s)
-	}, true)
+	MustRegStringFunc("email", emailRegexp.MatchString, true)
 }
 
 func init() {
@@ -67,10 +131,9 @@ func init() {
 				return false
 			}
 		}
-		num, err := phonenumbers.Parse(numberToParse, defaultRegion)
-		if err != nil {
+		if numberToParse == "" {
 			return false
 		}
-		return phonenumbers.IsValidNumber(num)
+		return phoneChecker(numberToParse, defaultRegion)
 	}, true)
 }
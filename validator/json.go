@@ -0,0 +1,50 @@
+package validator
+
+import (
+	"github.com/tidwall/gjson"
+)
+
+func init() {
+	MustRegFunc("isjson", func(args ...interface{}) bool {
+		s, ok := jsonArg(args)
+		return ok && gjson.Valid(s)
+	}, true)
+	MustRegFunc("isjsonobj", func(args ...interface{}) bool {
+		s, ok := jsonArg(args)
+		return ok && jsonTopByte(s) == '{' && gjson.Valid(s)
+	}, true)
+	MustRegFunc("isjsonarr", func(args ...interface{}) bool {
+		s, ok := jsonArg(args)
+		return ok && jsonTopByte(s) == '[' && gjson.Valid(s)
+	}, true)
+}
+
+// jsonArg returns the string or []byte argument of the JSON functions,
+// the empty one is false.
+func jsonArg(args []interface{}) (string, bool) {
+	if len(args) != 1 {
+		return "", false
+	}
+	var s string
+	switch v := args[0].(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return "", false
+	}
+	return s, s != ""
+}
+
+// jsonTopByte returns the first non-whitespace byte of the JSON text.
+func jsonTopByte(s string) byte {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case ' ', '\t', '\n', '\r':
+		default:
+			return s[i]
+		}
+	}
+	return 0
+}
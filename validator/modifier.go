@@ -0,0 +1,249 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+	"unsafe"
+)
+
+// ModifierTagName the tag name of the modifiers which normalize the string fields before validation
+const ModifierTagName = "mod"
+
+var (
+	modifiers = map[string]func(string) string{
+		"trim":  strings.TrimSpace,
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+		"squash": func(s string) string {
+			return strings.Join(strings.FieldsFunc(s, unicode.IsSpace), " ")
+		},
+	}
+	modifiersRW sync.RWMutex
+)
+
+// RegModifier registers the string modifier used in the 'mod' tag.
+// NOTE:
+//  example: `mod:"trim,lower"`;
+//  If @force=true, allow to cover the existed same @name;
+//  The modifiers should be registered before the first validation of the struct type;
+//  The unexported fields are not modified.
+func RegModifier(name string, fn func(string) string, force ...bool) error {
+	modifiersRW.Lock()
+	defer modifiersRW.Unlock()
+	if len(force) == 0 || !force[0] {
+		if _, ok := modifiers[name]; ok {
+			return fmt.Errorf("duplicate registration modifier: %s", name)
+		}
+	}
+	modifiers[name] = fn
+	return nil
+}
+
+// MustRegModifier registers the string modifier used in the 'mod' tag.
+// NOTE:
+//  panic if exist error.
+func MustRegModifier(name string, fn func(string) string, force ...bool) {
+	err := RegModifier(name, fn, force...)
+	if err != nil {
+		panic(err)
+	}
+}
+
+type structModifier struct {
+	fields []*fieldModifier
+}
+
+type fieldModifier struct {
+	index int
+	// mods are applied to the string, *string and []string field in order
+	mods []func(string) string
+	// nested indicates that the nested fields have modifiers
+	nested bool
+}
+
+var (
+	structModifiers   = make(map[reflect.Type]*structModifier, 64)
+	structModifiersRW sync.RWMutex
+)
+
+// getStructModifier returns the modifiers of the struct type, or nil if it has none.
+func getStructModifier(t reflect.Type) (*structModifier, error) {
+	structModifiersRW.RLock()
+	sm, ok := structModifiers[t]
+	structModifiersRW.RUnlock()
+	if ok {
+		return sm, nil
+	}
+	structModifiersRW.Lock()
+	defer structModifiersRW.Unlock()
+	return getStructModifierLocked(t, make(map[reflect.Type]*structModifier, 4))
+}
+
+func getStructModifierLocked(t reflect.Type, building map[reflect.Type]*structModifier) (*structModifier, error) {
+	if sm, ok := structModifiers[t]; ok {
+		return sm, nil
+	}
+	if sm, ok := building[t]; ok {
+		// cyclic reference, assume that it has modifiers
+		return sm, nil
+	}
+	sm := new(structModifier)
+	building[t] = sm
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			// the unexported field is not settable
+			continue
+		}
+		fm := &fieldModifier{index: i}
+		for _, name := range strings.Split(field.Tag.Get(ModifierTagName), ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			modifiersRW.RLock()
+			fn, ok := modifiers[name]
+			modifiersRW.RUnlock()
+			if !ok {
+				delete(building, t)
+				return nil, fmt.Errorf("%s.%s: unknown modifier %q", t.String(), field.Name, name)
+			}
+			fm.mods = append(fm.mods, fn)
+		}
+		nested, err := hasNestedModifier(field.Type, building)
+		if err != nil {
+			delete(building, t)
+			return nil, err
+		}
+		fm.nested = nested
+		if fm.mods != nil || fm.nested {
+			sm.fields = append(sm.fields, fm)
+		}
+	}
+	if len(sm.fields) == 0 {
+		sm = nil
+	}
+	structModifiers[t] = sm
+	return sm, nil
+}
+
+func hasNestedModifier(t reflect.Type, building map[reflect.Type]*structModifier) (bool, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		sm, err := getStructModifierLocked(t, building)
+		return sm != nil, err
+	case reflect.Slice, reflect.Array:
+		return hasNestedModifier(t.Elem(), building)
+	case reflect.Map:
+		// the map value is not addressable
+		if t.Elem().Kind() == reflect.Ptr || t.Elem().Kind() == reflect.Interface {
+			return hasNestedModifier(t.Elem(), building)
+		}
+	case reflect.Interface:
+		return true, nil
+	}
+	return false, nil
+}
+
+// modify applies the modifiers to the addressable string fields of the value.
+func modify(v reflect.Value) error {
+	var visited map[unsafe.Pointer]bool
+	return modifyValue(v, &visited)
+}
+
+// modifyValue applies the modifiers to the value,
+// @visited records the pointers to avoid the cyclic reference.
+func modifyValue(v reflect.Value, visited *map[unsafe.Pointer]bool) error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Ptr {
+			// the struct without modifiers is not visited
+			if t := v.Type().Elem(); t.Kind() == reflect.Struct {
+				if sm, err := getStructModifier(t); sm == nil || err != nil {
+					return err
+				}
+			}
+			p := unsafe.Pointer(v.Pointer())
+			if (*visited)[p] {
+				return nil
+			}
+			if *visited == nil {
+				*visited = make(map[unsafe.Pointer]bool, 4)
+			}
+			(*visited)[p] = true
+		}
+		return modifyValue(v.Elem(), visited)
+	case reflect.Struct:
+		if !v.CanAddr() {
+			return nil
+		}
+		sm, err := getStructModifier(v.Type())
+		if sm == nil || err != nil {
+			return err
+		}
+		for _, fm := range sm.fields {
+			fv := v.Field(fm.index)
+			// the exported fields of the embedded unexported struct are still settable
+			if !fv.CanSet() && fv.Kind() != reflect.Struct {
+				continue
+			}
+			if fm.mods != nil {
+				modifyString(fv, fm.mods)
+			}
+			if fm.nested {
+				if err = modifyValue(fv, visited); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		switch v.Type().Elem().Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+			for i := v.Len() - 1; i >= 0; i-- {
+				if err := modifyValue(v.Index(i), visited); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Map:
+		switch v.Type().Elem().Kind() {
+		case reflect.Ptr, reflect.Interface:
+			iter := v.MapRange()
+			for iter.Next() {
+				if err := modifyValue(iter.Value(), visited); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// modifyString applies the modifiers to the string or the string elements of the value.
+func modifyString(v reflect.Value, mods []func(string) string) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			modifyString(v.Elem(), mods)
+		}
+	case reflect.String:
+		s := v.String()
+		for _, fn := range mods {
+			s = fn(s)
+		}
+		v.SetString(s)
+	case reflect.Slice, reflect.Array:
+		for i := v.Len() - 1; i >= 0; i-- {
+			modifyString(v.Index(i), mods)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package validator
+
+import (
+	"math"
+	"net"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	MustRegFunc("hostname", func(args ...interface{}) bool {
+		if len(args) != 1 {
+			return false
+		}
+		s, ok := args[0].(string)
+		return ok && isHostname(s)
+	}, true)
+	MustRegFunc("fqdn", func(args ...interface{}) bool {
+		if len(args) != 1 {
+			return false
+		}
+		s, ok := args[0].(string)
+		return ok && isFQDN(s)
+	}, true)
+	MustRegFunc("port", func(args ...interface{}) bool {
+		if len(args) != 1 {
+			return false
+		}
+		switch v := args[0].(type) {
+		case float64:
+			return v >= 1 && v <= math.MaxUint16 && v == math.Trunc(v)
+		case string:
+			return isPort(v)
+		}
+		return false
+	}, true)
+	MustRegFunc("hostport", func(args ...interface{}) bool {
+		if len(args) != 1 {
+			return false
+		}
+		s, ok := args[0].(string)
+		return ok && isHostPort(s)
+	}, true)
+}
+
+// isHostname returns whether the string is a RFC 1123 hostname,
+// which may have a trailing dot.
+func isHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || len(s) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if !isHostLabel(label) {
+			return false
+		}
+	}
+	return true
+}
+
+// isHostLabel returns whether the string is 1-63 letters, digits and hyphens,
+// which does not start or end with a hyphen.
+func isHostLabel(label string) bool {
+	if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+		return false
+	}
+	for i := 0; i < len(label); i++ {
+		switch c := label[i]; {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isFQDN returns whether the string is a hostname with at least one dot,
+// whose top-level domain is letters, or punycode with the 'xn--' prefix.
+func isFQDN(s string) bool {
+	if !isHostname(s) {
+		return false
+	}
+	s = strings.TrimSuffix(s, ".")
+	i := strings.LastIndexByte(s, '.')
+	if i < 0 {
+		return false
+	}
+	tld := s[i+1:]
+	if len(tld) < 2 {
+		return false
+	}
+	if len(tld) > 4 && strings.EqualFold(tld[:4], "xn--") {
+		return true
+	}
+	for j := 0; j < len(tld); j++ {
+		if c := tld[j] | 0x20; c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// isPort returns whether the string is a decimal number in 1-65535.
+func isPort(s string) bool {
+	if s == "" || s[0] == '+' {
+		return false
+	}
+	n, err := strconv.ParseUint(s, 10, 16)
+	return err == nil && n > 0
+}
+
+// isHostPort returns whether the string is 'host:port',
+// where the host is a hostname, IPv4, or bracketed IPv6 address,
+// or is empty such as the listen address ':8080'.
+func isHostPort(s string) bool {
+	host, port, err := net.SplitHostPort(s)
+	if err != nil || !isPort(port) {
+		return false
+	}
+	if host == "" {
+		return true
+	}
+	if i := strings.LastIndexByte(host, '%'); i > 0 && strings.Contains(host, ":") {
+		// IPv6 zone, such as 'fe80::1%eth0'
+		host = host[:i]
+	}
+	if net.ParseIP(host) != nil {
+		return true
+	}
+	return isHostname(host)
+}
@@ -0,0 +1,105 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+
+	tagexpr "github.com/bytedance/go-tagexpr"
+)
+
+// PasswordPolicy the policy of the 'password' function
+type PasswordPolicy struct {
+	// MinLength the minimum number of non-whitespace characters
+	MinLength int
+	// MinClasses the minimum number of the character classes:
+	// upper case letters, lower case letters, digits and symbols
+	MinClasses int
+}
+
+var passwordPolicy = PasswordPolicy{
+	MinLength:  8,
+	MinClasses: 3,
+}
+
+// SetPasswordPolicy sets the default policy of the 'password' function.
+// NOTE:
+//  The default is 8+ characters with 3 of 4 character classes;
+//  The policy can be overridden per use, such as password($, 12, 4).
+func SetPasswordPolicy(policy PasswordPolicy) {
+	passwordPolicy = policy
+}
+
+var errPasswordNotString = errors.New("password must be a string")
+
+func init() {
+	err := tagexpr.RegFunc("password", func(args ...interface{}) interface{} {
+		policy := passwordPolicy
+		switch len(args) {
+		case 3:
+			minClasses, ok := args[2].(float64)
+			if !ok {
+				return false
+			}
+			policy.MinClasses = int(minClasses)
+			fallthrough
+		case 2:
+			minLength, ok := args[1].(float64)
+			if !ok {
+				return false
+			}
+			policy.MinLength = int(minLength)
+		case 1:
+		default:
+			return false
+		}
+		s, ok := args[0].(string)
+		if !ok {
+			return errPasswordNotString
+		}
+		if err := policy.check(s); err != nil {
+			return err
+		}
+		return true
+	}, true)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// check returns the error describing the failed requirement.
+// NOTE:
+//  The length is the number of runes except whitespaces,
+//  so that the whitespace padding does not satisfy the length.
+func (p PasswordPolicy) check(s string) error {
+	var length int
+	var upper, lower, digit, symbol bool
+	for _, r := range s {
+		switch {
+		case unicode.IsSpace(r):
+			continue
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+		length++
+	}
+	if length < p.MinLength {
+		return fmt.Errorf("password must contain at least %d non-whitespace characters", p.MinLength)
+	}
+	var classes int
+	for _, b := range [...]bool{upper, lower, digit, symbol} {
+		if b {
+			classes++
+		}
+	}
+	if classes < p.MinClasses {
+		return fmt.Errorf("password must contain at least %d of upper case letters, lower case letters, digits and symbols", p.MinClasses)
+	}
+	return nil
+}
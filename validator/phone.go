@@ -0,0 +1,29 @@
+package validator
+
+import "github.com/nyaruka/phonenumbers"
+
+var phoneChecker = checkPhone
+
+// SetPhoneChecker customizes the checker of the 'phone' function,
+// such as a checker with the carrier or the number type.
+// NOTE:
+//  @defaultRegion is empty when it is omitted in the expression;
+//  If fn==nil, the default is used
+func SetPhoneChecker(fn func(number, defaultRegion string) bool) {
+	if fn == nil {
+		fn = checkPhone
+	}
+	phoneChecker = fn
+}
+
+// checkPhone is the default checker of the 'phone' function, which uses the libphonenumber.
+// NOTE:
+//  If @defaultRegion is empty, the number must be in E.164 format;
+//  Otherwise, the number can also be in the national format of the region.
+func checkPhone(number, defaultRegion string) bool {
+	num, err := phonenumbers.Parse(number, defaultRegion)
+	if err != nil {
+		return false
+	}
+	return phonenumbers.IsValidNumber(num)
+}
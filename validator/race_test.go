@@ -0,0 +1,8 @@
+//go:build race
+// +build race
+
+package validator_test
+
+// raceEnabled whether the race detector is enabled,
+// under which sync.Pool drops the released objects randomly.
+const raceEnabled = true
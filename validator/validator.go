@@ -16,13 +16,20 @@
 package validator
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"io"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"unicode"
 	_ "unsafe"
 
 	tagexpr "github.com/bytedance/go-tagexpr"
+	"github.com/henrylee2cn/goutil"
 )
 
 const (
@@ -31,19 +38,55 @@ const (
 	// ErrMsgExprName the name of the expression used to specify the message
 	// returned when validation failed
 	ErrMsgExprName = "msg"
+	// ErrCodeExprName the name of the expression used to specify the machine-readable error code
+	// returned when validation failed
+	ErrCodeExprName = "code"
+	// IfExprName the name of the expression used to decide whether to
+	// validate the nested fields of the current field
+	IfExprName = "if"
+	// WarnExprName the name of the expression used for the warning-level validation,
+	// whose failure does not fail the validation
+	WarnExprName = "warn"
+	// NilExprName the name of the expression used to override the nil policy of the field,
+	// whose value is 'skip' or 'fail'
+	NilExprName = "nil"
 )
 
 // Validator struct fields validator
 type Validator struct {
 	vm         *tagexpr.VM
-	errFactory func(failPath, msg string) error
+	errFactory func(failPath, msg, code string) error
+	strict     bool
+	groups     map[string]bool
+	msgCatalog MsgCatalog
+	nilPolicy  nilPolicy
+	// fieldNameTag the tag naming the fields in the error paths, such as 'json'
+	fieldNameTag string
 }
 
+// nilPolicy the policy of validating the nil pointer field
+type nilPolicy int8
+
+const (
+	// nilEval evaluates the expressions with the nil value
+	nilEval nilPolicy = iota
+	// nilSkip skips the expressions and the nested fields
+	nilSkip
+	// nilFail fails with the required error
+	nilFail
+)
+
+// MsgCatalog returns the message of the @key in the language @lang,
+// @field is the path of the failed field and @value is its value.
+// NOTE:
+//  If the key is missing, return "".
+type MsgCatalog func(key, lang, field string, value interface{}) string
+
 // New creates a struct fields validator.
 func New(tagName string) *Validator {
 	v := &Validator{
 		vm:         tagexpr.New(tagName),
-		errFactory: defaultErrorFactory,
+		errFactory: defaultCodeErrorFactory,
 	}
 	return v
 }
@@ -53,83 +96,976 @@ func (v *Validator) VM() *tagexpr.VM {
 	return v.vm
 }
 
+// SetStrictMode if set to true, the evaluation fault of the expression,
+// such as a panic in the function, is surfaced as the validation error message.
+// NOTE:
+//  The default is false, the evaluation fault is regarded as false;
+//  In strict mode, the fault of an operand of && or || is not converted to false.
+func (v *Validator) SetStrictMode(enable bool) *Validator {
+	v.strict = enable
+	v.vm.SetStrictMode(enable)
+	return v
+}
+
 // Validate validates whether the fields of value is valid.
 // NOTE:
-//  If checkAll=true, validate all the error.
+//  If checkAll=true, validate all the error;
+//  The modifiers in the 'mod' tag are applied to the addressable fields before validation.
 func (v *Validator) Validate(value interface{}, checkAll ...bool) error {
 	var all bool
 	if len(checkAll) > 0 {
 		all = checkAll[0]
 	}
-	type ErrInfo struct {
-		selector string
-		path     string
-		te       *tagexpr.TagExpr
+	return v.validate(context.Background(), value, all, "", nil, nil, nil)
+}
+
+// ValidateWithWarnings validates whether the fields of value is valid,
+// and returns the failures of the warning-level expressions separately.
+// NOTE:
+//  The warning-level expression is named 'warn', and its message is named 'warn@msg',
+//  e.g. `vd:"warn:len($)<256; warn@msg:'too long'; len($)<1024"`;
+//  The warnings never fail the validation;
+//  If checkAll=false, the warnings after the first error are not collected.
+func (v *Validator) ValidateWithWarnings(value interface{}, checkAll ...bool) (warnings []error, err error) {
+	var all bool
+	if len(checkAll) > 0 {
+		all = checkAll[0]
 	}
-	var errInfos = make([]*ErrInfo, 0, 8)
-	var errs = make([]error, 0, 8)
-	v.vm.RunAny(value, func(te *tagexpr.TagExpr, err error) error {
-		if err != nil {
-			errs = append(errs, err)
-			if all {
-				return nil
+	warnings = make([]error, 0)
+	err = v.validate(context.Background(), value, all, "", nil, nil, &warnings)
+	return warnings, err
+}
+
+// ValidateContext validates whether the fields of value is valid with the context,
+// which is passed to the functions registered by RegCtxFunc.
+// NOTE:
+//  If checkAll=true, validate all the error;
+//  If the context is done, the validation is aborted and *ContextError is returned.
+func (v *Validator) ValidateContext(ctx context.Context, value interface{}, checkAll ...bool) error {
+	var all bool
+	if len(checkAll) > 0 {
+		all = checkAll[0]
+	}
+	return v.validate(ctx, value, all, "", nil, nil, nil)
+}
+
+// AllowUnexported if set to false, the struct type with the expressions on the unexported fields,
+// or the references to the unexported fields, returns the error such as 'cannot validate unexported field user.secretKey'.
+// NOTE:
+//  The default is true, the unexported fields with the expressions are validated by the unsafe access;
+//  It should be set before the struct type is validated for the first time.
+func (v *Validator) AllowUnexported(allow bool) *Validator {
+	v.vm.SetAllowUnexported(allow)
+	return v
+}
+
+// SetDynamicDispatch if set to false, the dynamic values of the interface fields are not validated.
+// NOTE:
+//  The default is true, the dynamic struct or struct pointer is validated by its own rules,
+//  with the field path prefixed to the error path;
+//  The struct which is already being validated by the dispatching chain is skipped.
+func (v *Validator) SetDynamicDispatch(enable bool) *Validator {
+	v.vm.SetDynamicDispatch(enable)
+	return v
+}
+
+// SetNilSkip sets the default policy of validating the nil pointer fields.
+// NOTE:
+//  If skip=true, the expressions of the nil pointer field and its nested fields are not evaluated;
+//  If skip=false, the nil pointer field with expressions or nested expressions fails with the required error;
+//  If not set, the expressions are evaluated with nil and the nested fields are not validated;
+//  The policy of a field can be overridden by the 'nil' expression, e.g. `vd:"nil:'skip'; $>0"`.
+func (v *Validator) SetNilSkip(skip bool) *Validator {
+	if skip {
+		v.nilPolicy = nilSkip
+	} else {
+		v.nilPolicy = nilFail
+	}
+	return v
+}
+
+// SetMsgCatalog sets the catalog used to look up the message that references a key,
+// such as `vd:"len($)<32; msg:'@{user.name.too_long}'"`.
+// NOTE:
+//  The whole message in the form '@{key}' references the key of the catalog, the others are used literally;
+//  The language is taken from the context of ValidateContext, see WithLang;
+//  If the key is missing, the default message is used;
+//  If catalog==nil, the message is always used literally.
+func (v *Validator) SetMsgCatalog(catalog MsgCatalog) *Validator {
+	v.msgCatalog = catalog
+	return v
+}
+
+// SetFieldNameTag sets the tag which names the fields in the FailPath of the errors,
+// such as 'json' for the API responses, e.g. 'user.email' instead of 'User.Email'.
+// NOTE:
+//  The field without the tag or tagged '-' uses the Go field name;
+//  The fields of the untagged embedded struct are promoted, like encoding/json;
+//  The error code is still derived from the Go field names;
+//  If tag=="", the Go field names are used.
+func (v *Validator) SetFieldNameTag(tag string) *Validator {
+	v.fieldNameTag = tag
+	return v
+}
+
+type langKey struct{}
+
+// WithLang returns a copy of ctx with the language used to look up the message catalog.
+func WithLang(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, langKey{}, lang)
+}
+
+// LangFromContext returns the language set by WithLang.
+func LangFromContext(ctx context.Context) string {
+	lang, _ := ctx.Value(langKey{}).(string)
+	return lang
+}
+
+// SetGroups declares the validation groups, such as 'create' and 'update'.
+// NOTE:
+//  The expression named by the group is only evaluated in ValidateGroup with the group,
+//  and its message is named by '$group@msg', e.g. `vd:"create:len($)>6; create@msg:'too short'"`;
+//  After declaring, the unknown expression names cause an error when the struct type is registered,
+//  so it should be called before validating.
+func (v *Validator) SetGroups(groups ...string) *Validator {
+	v.groups = make(map[string]bool, len(groups))
+	for _, g := range groups {
+		v.groups[g] = true
+	}
+	v.vm.SetExprNameChecker(func(exprName string) error {
+		switch exprName {
+		case ErrMsgExprName, ErrCodeExprName, IfExprName, NilExprName, WarnExprName,
+			WarnExprName + tagexpr.ExprNameSeparator + ErrMsgExprName,
+			WarnExprName + tagexpr.ExprNameSeparator + ErrCodeExprName:
+			return nil
+		}
+		// the named rules and their messages and codes, such as '@a' and '@a@msg'
+		if strings.HasPrefix(exprName, tagexpr.ExprNameSeparator) {
+			return nil
+		}
+		group := strings.TrimSuffix(exprName, tagexpr.ExprNameSeparator+ErrMsgExprName)
+		group = strings.TrimSuffix(group, tagexpr.ExprNameSeparator+ErrCodeExprName)
+		if !v.groups[group] {
+			return fmt.Errorf("unknown validation group %q", group)
+		}
+		return nil
+	})
+	return v
+}
+
+// ValidateGroup validates the expressions of the group and the ungrouped expressions.
+// NOTE:
+//  The nested fields are validated with the same group;
+//  If the groups have been declared by SetGroups, the unknown group returns error.
+func (v *Validator) ValidateGroup(value interface{}, group string, checkAll ...bool) error {
+	switch group {
+	case "", ErrMsgExprName, ErrCodeExprName, IfExprName, NilExprName, WarnExprName:
+		return fmt.Errorf("invalid validation group %q", group)
+	}
+	if v.groups != nil && !v.groups[group] {
+		return fmt.Errorf("unknown validation group %q", group)
+	}
+	var all bool
+	if len(checkAll) > 0 {
+		all = checkAll[0]
+	}
+	return v.validate(context.Background(), value, all, group, nil, nil, nil)
+}
+
+// ValidateFields validates only the fields specified by the selectors and their nested fields.
+// NOTE:
+//  The selector is in the dotted form, such as 'A' or 'A.B';
+//  The expressions can still read the fields which are not selected;
+//  The if-expressions of the ancestors of the selected fields still decide whether to validate them;
+//  If no selector is specified, validate nothing;
+//  If a selector does not exist, return *SelectorError.
+func (v *Validator) ValidateFields(value interface{}, selectors ...string) error {
+	if len(selectors) == 0 {
+		return nil
+	}
+	return v.validate(context.Background(), value, false, "", selectors, nil, nil)
+}
+
+// ValidateField re-validates only the field specified by the selector and its nested fields,
+// e.g. after the field is mutated.
+// NOTE:
+//  The selector is in the dotted form, such as 'A' or 'A.B';
+//  Only the expressions of the field are evaluated, and they can still read the other fields;
+//  The if-expressions of the ancestors of the field still decide whether to validate it;
+//  If the selector does not exist, return *SelectorError.
+func (v *Validator) ValidateField(structPtr interface{}, fieldSelector string) error {
+	return v.ValidateFields(structPtr, fieldSelector)
+}
+
+// ValidateExcept validates whether the fields of value is valid with the context,
+// except the fields specified by the selectors and their nested fields,
+// e.g. the fields which already failed to be bound.
+// NOTE:
+//  The selector is in the dotted form, such as 'A' or 'A.B';
+//  If checkAll=true, validate all the error.
+func (v *Validator) ValidateExcept(ctx context.Context, value interface{}, checkAll bool, selectors ...string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return v.validate(ctx, value, checkAll, "", nil, selectors, nil)
+}
+
+// validate validates the value.
+// NOTE:
+//  If group!="", also validate the expressions of the group;
+//  If selectors!=nil, only validate the specified fields;
+//  The fields specified by excluded and their nested fields are not validated;
+//  If warnings!=nil, also validate the warning-level expressions and append their errors to it.
+func (v *Validator) validate(ctx context.Context, value interface{}, all bool, group string, selectors, excluded []string, warnings *[]error) error {
+	rv, ok := value.(reflect.Value)
+	if !ok {
+		rv = reflect.ValueOf(value)
+	}
+	if err := modify(rv); err != nil {
+		return err
+	}
+	vs := validationPool.Get().(*validation)
+	vs.v = v
+	if v.fieldNameTag != "" && rv.IsValid() {
+		vs.rootType = rv.Type()
+	}
+	vs.ctx = ctx
+	vs.all = all
+	vs.group = group
+	vs.selectors = selectors
+	if selectors != nil {
+		vs.rangeSelectors = withAncestors(selectors)
+	}
+	vs.excluded = excluded
+	vs.warnings = warnings
+	if elems, ok := topLevelElems(rv); ok {
+		vs.validateElems(elems)
+	} else {
+		v.vm.RunAny(value, vs.onTagExpr)
+	}
+	err := vs.result()
+	vs.reset()
+	validationPool.Put(vs)
+	return err
+}
+
+// errInfo the failed expression of the validation.
+type errInfo struct {
+	selector string
+	path     string
+	te       *tagexpr.TagExpr
+	// reason the error returned by the function of the expression
+	reason error
+	// code the default error code, when the code expression is absent
+	code string
+	// rule the name of the failed rule, such as 'a' for `@a:len($)>0`
+	rule string
+}
+
+// validation the state of a validation,
+// which is pooled with the method values bound once to avoid the allocations.
+type validation struct {
+	v         *Validator
+	ctx       context.Context
+	all       bool
+	group     string
+	selectors []string
+	excluded  []string
+	warnings  *[]error
+	errInfos  []*errInfo
+	warnInfos []*errInfo
+	errs      []error
+	// abortErr the error which aborts the validation, *ContextError, *FuncError or *SelectorError
+	abortErr error
+	// pathPrefix the index of the element of the top-level slice, such as '[3]'
+	pathPrefix string
+	// rootType the type of the validated value, for naming the fields by SetFieldNameTag
+	rootType reflect.Type
+	// te the TagExpr passed to onTagExpr, and the state of its expressions
+	te *tagexpr.TagExpr
+	// tes the TagExprs passed to onTagExpr, which are released by reset after the errors are built
+	tes             []*tagexpr.TagExpr
+	nilParentFields map[string]bool
+	skippedPaths    []string
+	// rangeSelectors the selectors and their ancestors, whose if-expressions gate the selected fields
+	rangeSelectors []string
+
+	onTagExpr func(*tagexpr.TagExpr, error) error
+	onExpr    func(*tagexpr.ExprHandler) error
+}
+
+var validationPool = sync.Pool{
+	New: func() interface{} {
+		vs := new(validation)
+		vs.onTagExpr = vs.validateTagExpr
+		vs.onExpr = vs.validateExpr
+		return vs
+	},
+}
+
+// reset clears the state, and keeps the capacity of the buffers.
+func (vs *validation) reset() {
+	for i := range vs.errInfos {
+		vs.errInfos[i] = nil
+	}
+	for i := range vs.warnInfos {
+		vs.warnInfos[i] = nil
+	}
+	for k := range vs.nilParentFields {
+		delete(vs.nilParentFields, k)
+	}
+	for i, te := range vs.tes {
+		te.Release()
+		vs.tes[i] = nil
+	}
+	*vs = validation{
+		errInfos:        vs.errInfos[:0],
+		warnInfos:       vs.warnInfos[:0],
+		tes:             vs.tes[:0],
+		nilParentFields: vs.nilParentFields,
+		skippedPaths:    vs.skippedPaths[:0],
+		onTagExpr:       vs.onTagExpr,
+		onExpr:          vs.onExpr,
+	}
+}
+
+// topLevelElems returns the slice, array or map of structs passed directly to Validate,
+// which is dereferenced.
+func topLevelElems(rv reflect.Value) (reflect.Value, bool) {
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return rv, false
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+	default:
+		return rv, false
+	}
+	switch goutil.DereferenceType(rv.Type().Elem()).Kind() {
+	case reflect.Struct, reflect.Interface:
+		return rv, true
+	}
+	return rv, false
+}
+
+// validateElems validates the elements of the top-level slice or array in order,
+// or the values of the top-level map in the order of the sorted keys.
+// NOTE:
+//  The paths of the errors are prefixed with the index or the key, such as '[3].Email' or '{K:prod}.MaxConns'.
+func (vs *validation) validateElems(elems reflect.Value) {
+	if elems.Kind() == reflect.Map {
+		keys := elems.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return mapKeyLess(keys[i], keys[j])
+		})
+		for _, key := range keys {
+			if !vs.validateElem(mapKeyPath(key), elems.MapIndex(key)) {
+				return
 			}
-			return io.EOF
 		}
-		nilParentFields := make(map[string]bool, 16)
-		err = te.Range(func(eh *tagexpr.ExprHandler) error {
-			if strings.Contains(eh.StringSelector(), tagexpr.ExprNameSeparator) {
-				return nil
+		return
+	}
+	for i := 0; i < elems.Len(); i++ {
+		if !vs.validateElem("["+strconv.Itoa(i)+"]", elems.Index(i)) {
+			return
+		}
+	}
+}
+
+// validateElem validates the element of the top-level slice, array or map, and returns whether to continue.
+// NOTE:
+//  The nil element is skipped, or fails with the required error by the nil policy.
+func (vs *validation) validateElem(pathPrefix string, elem reflect.Value) bool {
+	vs.pathPrefix = pathPrefix
+	if k := elem.Kind(); (k == reflect.Ptr || k == reflect.Interface) && elem.IsNil() {
+		if vs.v.nilPolicy != nilFail {
+			return true
+		}
+		vs.errInfos = append(vs.errInfos, &errInfo{
+			path:   pathPrefix,
+			reason: errValueRequired(pathPrefix),
+			code:   defaultErrCode(pathPrefix, "REQUIRED"),
+		})
+	} else {
+		vs.v.vm.RunAny(elem, vs.onTagExpr)
+	}
+	return vs.abortErr == nil && (vs.all || len(vs.errs)+len(vs.errInfos) == 0)
+}
+
+// mapKeyPath returns the path of the map value, such as '{K:prod}', like the nested map field.
+func mapKeyPath(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return "{K:" + key.String() + "}"
+	}
+	return "{K:" + fmt.Sprint(key.Interface()) + "}"
+}
+
+// mapKeyLess compares the map keys by the numbers or the strings.
+func mapKeyLess(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	case reflect.String:
+		return a.String() < b.String()
+	}
+	return fmt.Sprint(a.Interface()) < fmt.Sprint(b.Interface())
+}
+
+// prefixed returns the path prefixed with the index of the top-level element.
+func (vs *validation) prefixed(path string) string {
+	if vs.pathPrefix == "" || path == "" {
+		return vs.pathPrefix + path
+	}
+	if path[0] == '[' || path[0] == '{' {
+		return vs.pathPrefix + path
+	}
+	return vs.pathPrefix + "." + path
+}
+
+func (vs *validation) validateTagExpr(te *tagexpr.TagExpr, err error) error {
+	if err != nil {
+		vs.errs = append(vs.errs, err)
+		if vs.all {
+			return nil
+		}
+		return io.EOF
+	}
+	vs.te = te
+	vs.tes = append(vs.tes, te)
+	for k := range vs.nilParentFields {
+		delete(vs.nilParentFields, k)
+	}
+	vs.skippedPaths = vs.skippedPaths[:0]
+	if vs.selectors != nil {
+		for _, selector := range vs.selectors {
+			if _, ok := te.Field(selector); !ok {
+				vs.abortErr = &SelectorError{Selector: selector}
+				return io.EOF
 			}
-			if eh.EvalBool() {
-				return nil
+		}
+		err = te.RangeSelected(vs.rangeSelectors, vs.onExpr)
+	} else {
+		err = te.Range(vs.onExpr)
+	}
+	if vs.abortErr != nil || (err != nil && !vs.all) {
+		return io.EOF
+	}
+	return nil
+}
+
+func (vs *validation) validateExpr(eh *tagexpr.ExprHandler) error {
+	v, ctx := vs.v, vs.ctx
+	if err := ctx.Err(); err != nil {
+		vs.abortErr = &ContextError{FailPath: vs.prefixed(eh.Path()), Err: err}
+		return io.EOF
+	}
+	if isSkippedPath(vs.skippedPaths, eh.Path()) {
+		return nil
+	}
+	field, name := splitExprSelector(eh.StringSelector())
+	if vs.selectors != nil && !isSelectedPath(vs.selectors, eh.Path()) {
+		// The if-expression of the ancestor still gates the selected fields
+		if name == IfExprName && isAncestorPath(vs.selectors, fieldPathOf(eh)) {
+			return vs.evalIf(eh)
+		}
+		return nil
+	}
+	if vs.excluded != nil && isSelectedPath(vs.excluded, eh.Path()) {
+		return nil
+	}
+	var isWarn bool
+	var rule string
+	if name != tagexpr.DefaultExprName {
+		// The nested fields are not validated when the if-expression is false
+		if name == IfExprName {
+			if err := vs.evalIf(eh); err != nil {
+				return err
 			}
-			// Ignore this error if the value of the parent is nil
-			if pfs, ok := eh.ExprSelector().ParentField(); ok {
-				if nilParentFields[pfs] {
+		}
+		isWarn = name == WarnExprName && vs.warnings != nil
+		rule = ruleName(name)
+		if !isWarn && rule == "" && (vs.group == "" || name != vs.group) {
+			return nil
+		}
+	}
+	// The nested fields of the nil parent are skipped, or fail on the parent by the nil policy
+	if pfs, ok := tagexpr.FieldSelector(field).Parent(); ok {
+		if vs.nilParentFields[pfs] {
+			return nil
+		}
+		if fh, ok := eh.TagExpr().Field(pfs); ok {
+			fv := fh.Value(false)
+			if !fv.IsValid() || (fv.Kind() == reflect.Ptr && fv.IsNil()) {
+				if vs.nilParentFields == nil {
+					vs.nilParentFields = make(map[string]bool, 16)
+				}
+				vs.nilParentFields[pfs] = true
+				if v.policyOf(eh.TagExpr(), pfs) != nilFail || isWarn {
 					return nil
 				}
-				if fh, ok := eh.TagExpr().Field(pfs); ok {
-					v := fh.Value(false)
-					if !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()) {
-						nilParentFields[pfs] = true
-						return nil
-					}
+				path, _ := tagexpr.FieldSelector(fieldPathOf(eh)).Parent()
+				path = vs.prefixed(path)
+				vs.errInfos = append(vs.errInfos, &errInfo{
+					selector: pfs,
+					path:     path,
+					te:       vs.te,
+					reason:   errValueRequired(path),
+					code:     defaultErrCode(path, "REQUIRED"),
+				})
+				if vs.all {
+					return nil
 				}
+				return io.EOF
 			}
-			errInfos = append(errInfos, &ErrInfo{
-				selector: eh.StringSelector(),
-				path:     eh.Path(),
-				te:       te,
-			})
-			if all {
-				return nil
-			}
-			return io.EOF
-		})
-		if err != nil && !all {
-			return err
 		}
+	}
+	if eh.OmitEmpty() && isEmptyField(eh) {
 		return nil
-	})
-	for _, info := range errInfos {
-		errs = append(errs, v.errFactory(
-			info.path,
-			info.te.EvalString(info.selector+tagexpr.ExprNameSeparator+ErrMsgExprName),
-		))
 	}
+	var r interface{}
+	switch v.policyOfNil(eh) {
+	case nilSkip:
+		return nil
+	case nilFail:
+		r = errValueRequired(fieldPathOf(eh))
+	default:
+		r = eh.EvalContext(ctx)
+	}
+	if tagexpr.FakeBool(r) {
+		return nil
+	}
+	if fe, ok := r.(*tagexpr.FuncError); ok {
+		vs.abortErr = &FuncError{FailPath: vs.prefixed(eh.Path()), Err: fe}
+		return io.EOF
+	}
+	// The function is interrupted by the context
+	if _, ok := r.(error); ok && ctx.Err() != nil {
+		vs.abortErr = &ContextError{FailPath: vs.prefixed(eh.Path()), Err: ctx.Err()}
+		return io.EOF
+	}
+	path := vs.prefixed(eh.Path())
+	codeSuffix := "INVALID"
+	if isWarn {
+		path = strings.TrimSuffix(path, tagexpr.ExprNameSeparator+WarnExprName)
+		codeSuffix = "WARN"
+	} else if vs.group != "" && strings.HasSuffix(path, tagexpr.ExprNameSeparator+vs.group) {
+		// the path of the group expression is suffixed with the group name
+		path = strings.TrimSuffix(path, tagexpr.ExprNameSeparator+vs.group)
+		codeSuffix = vs.group + "_INVALID"
+	} else if rule != "" {
+		// the path of the named rule is suffixed with '@@name'
+		path = strings.TrimSuffix(path, tagexpr.ExprNameSeparator+tagexpr.ExprNameSeparator+rule)
+		codeSuffix = rule + "_INVALID"
+	}
+	info := &errInfo{
+		selector: eh.StringSelector(),
+		path:     path,
+		te:       vs.te,
+		reason:   v.reasonOf(r),
+		code:     defaultErrCode(path, codeSuffix),
+		rule:     rule,
+	}
+	if isWarn {
+		vs.warnInfos = append(vs.warnInfos, info)
+		return nil
+	}
+	vs.errInfos = append(vs.errInfos, info)
+	if vs.all {
+		return nil
+	}
+	return io.EOF
+}
+
+// result returns the error of the validation, and appends the warnings.
+func (vs *validation) result() error {
+	if vs.abortErr != nil {
+		return vs.abortErr
+	}
+	for _, info := range vs.warnInfos {
+		*vs.warnings = append(*vs.warnings, vs.newError(info))
+	}
+	for _, info := range vs.errInfos {
+		vs.errs = append(vs.errs, vs.newError(info))
+	}
+	return joinErrors(vs.errs)
+}
+
+func (vs *validation) newError(info *errInfo) error {
+	v := vs.v
+	var msg, code string
+	if _, ok := info.reason.(*tagexpr.EvalFault); ok || info.te == nil {
+		// the nil element of the top-level slice has no expressions
+		msg = info.reason.Error()
+	} else {
+		msg = info.te.EvalString(info.selector + tagexpr.ExprNameSeparator + ErrMsgExprName)
+		if msg == "" && info.rule != "" {
+			// the shared message of the field
+			msg = info.te.EvalString(exprFieldOf(info.selector) + tagexpr.ExprNameSeparator + ErrMsgExprName)
+		}
+		if v.msgCatalog != nil {
+			if key, ok := msgKeyOf(msg); ok {
+				msg = v.lookupMsg(vs.ctx, key, info.path, info.te, info.selector)
+			}
+		}
+		if msg == "" && info.reason != nil {
+			msg = info.reason.Error()
+		}
+	}
+	if info.te != nil {
+		code = info.te.EvalString(info.selector + tagexpr.ExprNameSeparator + ErrCodeExprName)
+		if code == "" && info.rule != "" {
+			code = info.te.EvalString(exprFieldOf(info.selector) + tagexpr.ExprNameSeparator + ErrCodeExprName)
+		}
+	}
+	if code == "" {
+		code = info.code
+	}
+	path := info.path
+	if vs.rootType != nil {
+		path = renamePath(vs.rootType, path, v.fieldNameTag)
+	}
+	// the sensitive value is masked before the error is built, such as the card number,
+	// whatever the error factory is
+	sr, _ := info.reason.(sensitiveReason)
+	if sr != nil {
+		msg = sr.mask(msg)
+	}
+	err := v.errFactory(path, msg, code)
+	if sr != nil {
+		if e, ok := err.(*Error); ok {
+			e.Value = sr.maskedValue()
+		}
+	}
+	if info.rule != "" {
+		if e, ok := err.(*Error); ok {
+			e.Rule = info.rule
+		}
+	}
+	return err
+}
+
+// sensitiveReason the reason of the failed expression on the sensitive value, such as the card number
+type sensitiveReason interface {
+	// maskedValue returns the masked value
+	maskedValue() string
+	// mask replaces the value in the message with the masked one
+	mask(msg string) string
+}
+
+// splitExprSelector splits the expression selector into the field selector and the expression name,
+// which may contain the separator, such as 'create@msg' and '@email' of the named rule.
+func splitExprSelector(exprSelector string) (field, name string) {
+	i := strings.Index(exprSelector, tagexpr.ExprNameSeparator)
+	if i < 0 {
+		return exprSelector, tagexpr.DefaultExprName
+	}
+	return exprSelector[:i], exprSelector[i+len(tagexpr.ExprNameSeparator):]
+}
+
+// exprFieldOf returns the field selector of the expression selector, see splitExprSelector.
+func exprFieldOf(exprSelector string) string {
+	field, _ := splitExprSelector(exprSelector)
+	return field
+}
+
+// fieldPathOf returns the path of the field of the expression, without the expression name suffix,
+// e.g. 'A[0].B' for 'A[0].B@create@msg'.
+func fieldPathOf(eh *tagexpr.ExprHandler) string {
+	path := eh.Path()
+	if _, name := splitExprSelector(eh.StringSelector()); name != tagexpr.DefaultExprName {
+		path = strings.TrimSuffix(path, tagexpr.ExprNameSeparator+name)
+	}
+	return path
+}
+
+// ruleName returns the name of the named rule, such as 'a' for the expression name '@a',
+// or "" if the expression is not a named rule, such as 'msg' and '@a@msg'.
+func ruleName(exprName string) string {
+	if !strings.HasPrefix(exprName, tagexpr.ExprNameSeparator) {
+		return ""
+	}
+	name := exprName[len(tagexpr.ExprNameSeparator):]
+	if name == "" || strings.Contains(name, tagexpr.ExprNameSeparator) {
+		return ""
+	}
+	return name
+}
+
+// ValidateMap validates the map, such as the schemaless JSON object, against the rules.
+// NOTE:
+//  The key of rules is the dotted path of the map entry, such as 'billing.amount';
+//  The value of rules is the expression, `$` is the value of the entry and
+//  `(X.Y)$` is the value of the path X.Y from the root of the map;
+//  The rule of the absent entry is skipped, unless it calls mapexists($);
+//  If checkAll=true, validate all the error.
+func (v *Validator) ValidateMap(m map[string]interface{}, rules map[string]string, checkAll ...bool) error {
+	var all bool
+	if len(checkAll) > 0 {
+		all = checkAll[0]
+	}
+	paths := make([]string, 0, len(rules))
+	for path := range rules {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var errs []error
+	for _, path := range paths {
+		expr, err := tagexpr.ParseMapExpr(rules[path])
+		if err != nil {
+			return fmt.Errorf("%s: %s", path, err)
+		}
+		if _, ok := tagexpr.MapValue(m, path); !ok && !expr.CallsFunc(tagexpr.MapExistsFuncName) {
+			continue
+		}
+		r := expr.Eval(m, path)
+		if tagexpr.FakeBool(r) {
+			continue
+		}
+		var msg string
+		if reason := v.reasonOf(r); reason != nil {
+			msg = reason.Error()
+		}
+		errs = append(errs, v.errFactory(path, msg, defaultErrCode(path, "INVALID")))
+		if !all {
+			break
+		}
+	}
+	return joinErrors(errs)
+}
+
+// joinErrors combines the errors into one.
+func joinErrors(errs []error) error {
 	switch len(errs) {
 	case 0:
 		return nil
 	case 1:
 		return errs[0]
 	default:
-		var errStr string
-		for _, e := range errs {
-			errStr += e.Error() + "\t"
+		return Errors(errs)
+	}
+}
+
+// Errors the errors of the validation with checkAll=true
+type Errors []error
+
+// Error implements error interface, the messages are separated by tab.
+func (e Errors) Error() string {
+	a := make([]string, len(e))
+	for i, err := range e {
+		a[i] = err.Error()
+	}
+	return strings.Join(a, "\t")
+}
+
+// Unwrap returns the errors, for errors.Is and errors.As.
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// defaultErrCode returns the default error code of the failed field,
+// which is the upper snake case of the path without indexes followed by the suffix,
+// e.g. 'USER_NAME_INVALID' for 'User.Name'.
+func defaultErrCode(path, suffix string) string {
+	var b strings.Builder
+	var prev rune
+	var skip int
+	rs := []rune(path)
+	for i, r := range rs {
+		switch {
+		case r == '[' || r == '{':
+			skip++
+			continue
+		case r == ']' || r == '}':
+			skip--
+			continue
+		case skip > 0:
+			continue
+		case r == '.' || r == '_' || r == '-':
+			r = '_'
+		case unicode.IsUpper(r):
+			// split the words of the camel case, e.g. 'UserID' and 'HTTPServer'
+			if i > 0 && prev != '_' && (unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				(unicode.IsUpper(prev) && i+1 < len(rs) && unicode.IsLower(rs[i+1]))) {
+				b.WriteByte('_')
+			}
+		}
+		if r == '_' && (prev == '_' || b.Len() == 0) {
+			continue
 		}
-		return errors.New(errStr[:len(errStr)-1])
+		b.WriteRune(unicode.ToUpper(r))
+		prev = r
+	}
+	if b.Len() > 0 && prev != '_' {
+		b.WriteByte('_')
+	}
+	b.WriteString(strings.ToUpper(suffix))
+	return b.String()
+}
+
+// policyOfNil returns the nil policy of the field of the expression if its value is a nil pointer,
+// otherwise returns nilEval.
+func (v *Validator) policyOfNil(eh *tagexpr.ExprHandler) nilPolicy {
+	field := exprFieldOf(eh.StringSelector())
+	fh, ok := eh.TagExpr().Field(field)
+	if !ok {
+		return nilEval
+	}
+	fv := fh.Value(false)
+	if fv.Kind() != reflect.Ptr || !fv.IsNil() {
+		return nilEval
 	}
+	return v.policyOf(eh.TagExpr(), field)
+}
+
+// isEmptyField returns whether the field of the expression holds the zero value,
+// such as the empty string, 0, false, the nil pointer, the empty slice or map and the zero time.Time.
+// NOTE:
+//  The non-nil pointer is not empty even if it points to the zero value.
+func isEmptyField(eh *tagexpr.ExprHandler) bool {
+	fh, ok := eh.TagExpr().Field(exprFieldOf(eh.StringSelector()))
+	if !ok {
+		return false
+	}
+	fv := fh.Value(false)
+	switch fv.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Ptr, reflect.Interface:
+		return fv.IsNil()
+	case reflect.String, reflect.Slice, reflect.Map:
+		return fv.Len() == 0
+	}
+	if fv.CanInterface() {
+		if z, ok := fv.Interface().(interface{ IsZero() bool }); ok {
+			return z.IsZero()
+		}
+	}
+	return fv.IsZero()
+}
+
+// policyOf returns the nil policy of the field, which is overridden by its 'nil' expression.
+func (v *Validator) policyOf(te *tagexpr.TagExpr, fieldSelector string) nilPolicy {
+	switch te.EvalString(fieldSelector + tagexpr.ExprNameSeparator + NilExprName) {
+	case "skip":
+		return nilSkip
+	case "fail":
+		return nilFail
+	}
+	return v.nilPolicy
+}
+
+// errValueRequired returns the reason of the nil field failed by the nil policy.
+func errValueRequired(path string) error {
+	return errors.New("value is required: " + path)
+}
+
+const (
+	// msgKeyPrefix and msgKeySuffix enclose the message which references a key of the catalog, such as '@{user.name.too_long}'
+	msgKeyPrefix = "@{"
+	msgKeySuffix = "}"
+)
+
+// msgKeyOf returns the key of the catalog if the message is in the form '@{key}'.
+func msgKeyOf(msg string) (string, bool) {
+	if !strings.HasPrefix(msg, msgKeyPrefix) || !strings.HasSuffix(msg, msgKeySuffix) {
+		return "", false
+	}
+	key := strings.TrimSpace(msg[len(msgKeyPrefix) : len(msg)-len(msgKeySuffix)])
+	return key, key != "" && !strings.ContainsAny(key, "{}")
+}
+
+// lookupMsg looks up the message of the key in the catalog.
+func (v *Validator) lookupMsg(ctx context.Context, key, path string, te *tagexpr.TagExpr, exprSelector string) string {
+	var value interface{}
+	if fh, ok := te.Field(exprFieldOf(exprSelector)); ok {
+		if fv := fh.Value(false); fv.IsValid() && fv.CanInterface() {
+			value = fv.Interface()
+		}
+	}
+	return v.msgCatalog(key, LangFromContext(ctx), path, value)
+}
+
+// reasonOf returns the error if the failed expression value is an error.
+// NOTE:
+//  The evaluation fault is returned only in strict mode.
+func (v *Validator) reasonOf(r interface{}) error {
+	err, _ := r.(error)
+	if _, ok := err.(*tagexpr.EvalFault); ok && !v.strict {
+		return nil
+	}
+	return err
+}
+
+// isSkippedPath returns whether the path is nested in one of the skipped field paths.
+func isSkippedPath(skippedPaths []string, path string) bool {
+	for _, p := range skippedPaths {
+		if len(path) > len(p) && strings.HasPrefix(path, p) {
+			switch path[len(p)] {
+			case '.', '[', '{':
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// evalIf evaluates the if-expression, and skips the nested fields of its field if it is false.
+func (vs *validation) evalIf(eh *tagexpr.ExprHandler) error {
+	r := eh.EvalContext(vs.ctx)
+	if fe, ok := r.(*tagexpr.FuncError); ok {
+		vs.abortErr = &FuncError{FailPath: vs.prefixed(fieldPathOf(eh)), Err: fe}
+		return io.EOF
+	}
+	if !tagexpr.FakeBool(r) {
+		vs.skippedPaths = append(vs.skippedPaths, fieldPathOf(eh))
+	}
+	return nil
+}
+
+// withAncestors returns the selectors followed by their ancestors, such as 'A' and 'A.B' for 'A.B.C'.
+func withAncestors(selectors []string) []string {
+	r := append(make([]string, 0, len(selectors)*2), selectors...)
+	for _, selector := range selectors {
+		for p, ok := tagexpr.FieldSelector(selector).Parent(); ok; p, ok = tagexpr.FieldSelector(p).Parent() {
+			r = append(r, p)
+		}
+	}
+	return r
+}
+
+// isAncestorPath returns whether the path is the ancestor of one of the selected fields.
+func isAncestorPath(selectors []string, path string) bool {
+	for _, p := range selectors {
+		if len(p) > len(path) && strings.HasPrefix(p, path) {
+			switch p[len(path)] {
+			case '.', '[', '{':
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isSelectedPath returns whether the path is one of the selected fields or nested in them.
+func isSelectedPath(selectors []string, path string) bool {
+	for _, p := range selectors {
+		if path == p {
+			return true
+		}
+		if len(path) > len(p) && strings.HasPrefix(path, p) {
+			switch path[len(p)] {
+			case '.', '[', '{', tagexpr.ExprNameSeparator[0]:
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // SetErrorFactory customizes the factory of validation error.
@@ -137,7 +1073,23 @@ func (v *Validator) Validate(value interface{}, checkAll ...bool) error {
 //  If errFactory==nil, the default is used
 func (v *Validator) SetErrorFactory(errFactory func(failPath, msg string) error) *Validator {
 	if errFactory == nil {
-		errFactory = defaultErrorFactory
+		v.errFactory = defaultCodeErrorFactory
+		return v
+	}
+	v.errFactory = func(failPath, msg, _ string) error {
+		return errFactory(failPath, msg)
+	}
+	return v
+}
+
+// SetCodeErrorFactory customizes the factory of validation error, which receives the error code.
+// NOTE:
+//  The code is specified by the 'code' expression, such as `vd:"len($)<32; code:'USER_NAME_TOO_LONG'"`,
+//  or generated from the path of the failed field by default, such as 'USER_NAME_INVALID';
+//  If errFactory==nil, the default is used
+func (v *Validator) SetCodeErrorFactory(errFactory func(failPath, msg, code string) error) *Validator {
+	if errFactory == nil {
+		errFactory = defaultCodeErrorFactory
 	}
 	v.errFactory = errFactory
 	return v
@@ -146,6 +1098,14 @@ func (v *Validator) SetErrorFactory(errFactory func(failPath, msg string) error)
 // Error validate error
 type Error struct {
 	FailPath, Msg string
+	// Code the machine-readable error code
+	Code string
+	// Value the masked value of the sensitive field, such as the card number
+	// failed by luhn or creditcard
+	Value string `json:",omitempty"`
+	// Rule the name of the failed named rule, such as 'a' for `@a:len($)>0`,
+	// empty for the unnamed expression
+	Rule string `json:",omitempty"`
 }
 
 // Error implements error interface.
@@ -156,6 +1116,50 @@ func (e *Error) Error() string {
 	return "invalid parameter: " + e.FailPath
 }
 
+// ContextError the error that the validation is aborted by the context,
+// such as timeout or cancellation
+type ContextError struct {
+	FailPath string
+	Err      error
+}
+
+// Error implements error interface.
+func (e *ContextError) Error() string {
+	return "validation aborted at " + e.FailPath + ": " + e.Err.Error()
+}
+
+// Unwrap returns the error of the context.
+func (e *ContextError) Unwrap() error {
+	return e.Err
+}
+
+// FuncError the error that the validation is aborted by the function registered by RegErrFunc
+type FuncError struct {
+	FailPath string
+	// Err the *tagexpr.FuncError with the function name and the expression
+	Err error
+}
+
+// Error implements error interface.
+func (e *FuncError) Error() string {
+	return "validation aborted at " + e.FailPath + ": " + e.Err.Error()
+}
+
+// Unwrap returns the *tagexpr.FuncError, which unwraps to the error returned by the function.
+func (e *FuncError) Unwrap() error {
+	return e.Err
+}
+
+// SelectorError the error that the field selector does not exist
+type SelectorError struct {
+	Selector string
+}
+
+// Error implements error interface.
+func (e *SelectorError) Error() string {
+	return fmt.Sprintf("field selector %q does not exist", e.Selector)
+}
+
 //go:linkname defaultErrorFactory validator.defaultErrorFactory
 //go:nosplit
 func defaultErrorFactory(failPath, msg string) error {
@@ -164,3 +1168,11 @@ func defaultErrorFactory(failPath, msg string) error {
 		Msg:      msg,
 	}
 }
+
+func defaultCodeErrorFactory(failPath, msg, code string) error {
+	return &Error{
+		FailPath: failPath,
+		Msg:      msg,
+		Code:     code,
+	}
+}